@@ -10,8 +10,8 @@ type Adapter interface {
 	Ping(ctx context.Context) error
 	Aggregate(ctx context.Context, query Query, mode string, field string) (int, error)
 	Query(ctx context.Context, query Query) (Cursor, error)
-	Insert(ctx context.Context, query Query, primaryField string, mutates map[string]Mutate) (interface{}, error)
-	InsertAll(ctx context.Context, query Query, primaryField string, fields []string, bulkMutates []map[string]Mutate) ([]interface{}, error)
+	Insert(ctx context.Context, query Query, primaryFields []string, mutates map[string]Mutate, onConflict OnConflict) (interface{}, error)
+	InsertAll(ctx context.Context, query Query, primaryFields []string, fields []string, bulkMutates []map[string]Mutate, onConflict OnConflict) ([]interface{}, error)
 	Update(ctx context.Context, query Query, mutates map[string]Mutate) (int, error)
 	Delete(ctx context.Context, query Query) (int, error)
 
@@ -21,3 +21,17 @@ type Adapter interface {
 
 	Apply(ctx context.Context, migration Migration) error
 }
+
+// ReturningAdapter is optional interface for adapter that is able to return the inserted or updated row.
+// When supported, the row will be scanned directly into the record, and no additional query is needed to reload it.
+type ReturningAdapter interface {
+	Adapter
+	SupportReturning() bool
+	InsertReturning(ctx context.Context, query Query, mutates map[string]Mutate, onConflict OnConflict) (Cursor, error)
+	UpdateReturning(ctx context.Context, query Query, mutates map[string]Mutate) (Cursor, error)
+}
+
+func returningAdapter(adapter Adapter) (ReturningAdapter, bool) {
+	ra, ok := adapter.(ReturningAdapter)
+	return ra, ok && ra.SupportReturning()
+}
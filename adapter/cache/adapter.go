@@ -0,0 +1,431 @@
+// Package cache wraps an adapter to cache query and aggregate results.
+//
+// Results are keyed by the generated sql statement and its arguments, and are invalidated
+// per table whenever the table is modified through the adapter.
+// Caching is disabled inside transactions.
+//
+// Usage:
+//
+//	// cache at most 1000 results for a minute.
+//	adapter = cache.New(adapter, cache.NewLRU(1000), time.Minute)
+//
+//	// initialize rel's repo.
+//	repo := rel.New(adapter)
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/adapter/sql"
+)
+
+var (
+	// config of key builder, the statement is only used to identify the query, so a fixed config is enough.
+	config = sql.Config{
+		Placeholder: "?",
+		EscapeChar:  "`",
+	}
+
+	versionSeq int64
+
+	rtTime = reflect.TypeOf(time.Time{})
+)
+
+// Adapter definition for caching adapter.
+type Adapter struct {
+	rel.Adapter
+	store  Store
+	ttl    time.Duration
+	tx     *transaction
+	nested bool
+}
+
+var (
+	_ rel.Adapter          = (*Adapter)(nil)
+	_ rel.ReturningAdapter = (*Adapter)(nil)
+)
+
+type executor interface {
+	Exec(ctx context.Context, statement string, args []interface{}) (int64, int64, error)
+}
+
+// transaction tracks tables modified by a transaction, so they can be invalidated again after commit.
+type transaction struct {
+	tables   []string
+	modified bool
+}
+
+// Aggregate record using given query, the result is served from cache when available.
+func (a *Adapter) Aggregate(ctx context.Context, query rel.Query, mode string, field string) (int, error) {
+	key, cacheable := a.key(query, func() (string, []interface{}) {
+		return sql.NewBuilder(config).Aggregate(query, mode, field)
+	})
+
+	if !cacheable {
+		return a.Adapter.Aggregate(ctx, query, mode, field)
+	}
+
+	if value, ok := a.store.Get(key); ok {
+		return value.(int), nil
+	}
+
+	value, err := a.Adapter.Aggregate(ctx, query, mode, field)
+	if err != nil {
+		return 0, err
+	}
+
+	a.store.Set(key, value, a.ttl)
+	return value, nil
+}
+
+// Query performs query operation, the result is served from cache when available.
+func (a *Adapter) Query(ctx context.Context, query rel.Query) (rel.Cursor, error) {
+	key, cacheable := a.key(query, func() (string, []interface{}) {
+		return sql.NewBuilder(config).Find(query)
+	})
+
+	if !cacheable {
+		return a.Adapter.Query(ctx, query)
+	}
+
+	if value, ok := a.store.Get(key); ok {
+		return value.(*result).cursor(), nil
+	}
+
+	cur, err := a.Adapter.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := newResult(cur)
+	if err != nil {
+		return nil, err
+	}
+
+	a.store.Set(key, res, a.ttl)
+	return res.cursor(), nil
+}
+
+// Insert inserts a record and invalidates cached results of its table.
+func (a *Adapter) Insert(ctx context.Context, query rel.Query, primaryFields []string, mutates map[string]rel.Mutate, onConflict rel.OnConflict) (interface{}, error) {
+	defer a.invalidate(query.Table)
+	return a.Adapter.Insert(ctx, query, primaryFields, mutates, onConflict)
+}
+
+// InsertAll inserts multiple records and invalidates cached results of their table.
+func (a *Adapter) InsertAll(ctx context.Context, query rel.Query, primaryFields []string, fields []string, bulkMutates []map[string]rel.Mutate, onConflict rel.OnConflict) ([]interface{}, error) {
+	defer a.invalidate(query.Table)
+	return a.Adapter.InsertAll(ctx, query, primaryFields, fields, bulkMutates, onConflict)
+}
+
+// Update updates records and invalidates cached results of their table.
+func (a *Adapter) Update(ctx context.Context, query rel.Query, mutates map[string]rel.Mutate) (int, error) {
+	defer a.invalidate(query.Table)
+	return a.Adapter.Update(ctx, query, mutates)
+}
+
+// Delete deletes records and invalidates cached results of their table.
+func (a *Adapter) Delete(ctx context.Context, query rel.Query) (int, error) {
+	defer a.invalidate(query.Table)
+	return a.Adapter.Delete(ctx, query)
+}
+
+// SupportReturning returns true when the wrapped adapter supports returning.
+func (a *Adapter) SupportReturning() bool {
+	ra, ok := a.Adapter.(rel.ReturningAdapter)
+	return ok && ra.SupportReturning()
+}
+
+// InsertReturning inserts a record, returns the inserted row and invalidates cached results of its table.
+func (a *Adapter) InsertReturning(ctx context.Context, query rel.Query, mutates map[string]rel.Mutate, onConflict rel.OnConflict) (rel.Cursor, error) {
+	defer a.invalidate(query.Table)
+	return a.Adapter.(rel.ReturningAdapter).InsertReturning(ctx, query, mutates, onConflict)
+}
+
+// UpdateReturning updates records, returns the updated rows and invalidates cached results of their table.
+func (a *Adapter) UpdateReturning(ctx context.Context, query rel.Query, mutates map[string]rel.Mutate) (rel.Cursor, error) {
+	defer a.invalidate(query.Table)
+	return a.Adapter.(rel.ReturningAdapter).UpdateReturning(ctx, query, mutates)
+}
+
+// Exec executes raw statement using the wrapped adapter and invalidates all cached results,
+// because tables modified by the statement are unknown.
+func (a *Adapter) Exec(ctx context.Context, statement string, args []interface{}) (int64, int64, error) {
+	exec, ok := a.Adapter.(executor)
+	if !ok {
+		return 0, 0, errors.New("rel: wrapped adapter doesn't support exec")
+	}
+
+	defer a.invalidate()
+	return exec.Exec(ctx, statement, args)
+}
+
+// Begin begins a new transaction, results are never cached inside a transaction.
+func (a *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	adapter, err := a.Adapter.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		tx = a.tx
+	)
+
+	if tx == nil {
+		tx = &transaction{}
+	}
+
+	return &Adapter{
+		Adapter: adapter,
+		store:   a.store,
+		ttl:     a.ttl,
+		tx:      tx,
+		nested:  a.tx != nil,
+	}, nil
+}
+
+// Commit commits current transaction.
+// Tables modified by the transaction are invalidated again, since results may be cached before the changes are visible.
+func (a *Adapter) Commit(ctx context.Context) error {
+	if err := a.Adapter.Commit(ctx); err != nil {
+		return err
+	}
+
+	if a.tx != nil && !a.nested && a.tx.modified {
+		a.Invalidate(a.tx.tables...)
+	}
+
+	return nil
+}
+
+// Apply applies migration and invalidates cached results of the migrated table.
+// Raw migration invalidates all cached results.
+func (a *Adapter) Apply(ctx context.Context, migration rel.Migration) error {
+	switch v := migration.(type) {
+	case rel.Table:
+		defer a.invalidate(v.Name, v.Rename)
+	case rel.Index:
+		defer a.invalidate(v.Table)
+	default:
+		defer a.invalidate()
+	}
+
+	return a.Adapter.Apply(ctx, migration)
+}
+
+// Invalidate cached results of the tables.
+// All cached results are invalidated when no table is given.
+func (a *Adapter) Invalidate(tables ...string) {
+	if len(tables) == 0 {
+		a.store.Set(versionKey(""), newVersion(), 0)
+		return
+	}
+
+	for _, table := range tables {
+		if table != "" {
+			a.store.Set(versionKey(table), newVersion(), 0)
+		}
+	}
+}
+
+func (a *Adapter) invalidate(tables ...string) {
+	if a.tx != nil {
+		a.tx.tables = append(a.tx.tables, tables...)
+		a.tx.modified = true
+	}
+
+	a.Invalidate(tables...)
+}
+
+// key returns cache key of the statement, the current version of every table used by the query is a part of the key,
+// so the cached result is no longer used once any of the table is invalidated.
+func (a *Adapter) key(query rel.Query, build func() (string, []interface{})) (string, bool) {
+	if a.tx != nil || query.LockQuery != "" {
+		return "", false
+	}
+
+	tables, ok := queryTables(nil, query)
+	if !ok {
+		return "", false
+	}
+
+	var (
+		buffer          strings.Builder
+		statement, args = build()
+	)
+
+	buffer.WriteString("rel:cache:")
+	buffer.WriteString(a.version(""))
+
+	for _, table := range tables {
+		buffer.WriteByte(':')
+		buffer.WriteString(a.version(table))
+	}
+
+	buffer.WriteByte(':')
+	buffer.WriteString(statement)
+	buffer.WriteString(fmt.Sprintf("%#v", keyArgs(args)))
+
+	return buffer.String(), true
+}
+
+// keyArgs normalizes arguments, so equal values produce the same key regardless of their address or time location.
+func keyArgs(args []interface{}) []interface{} {
+	var (
+		result = make([]interface{}, len(args))
+	)
+
+	for i := range args {
+		rv := reflect.ValueOf(args[i])
+		for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			rv = rv.Elem()
+		}
+
+		switch {
+		case !rv.IsValid() || (rv.Kind() == reflect.Ptr && rv.IsNil()):
+			result[i] = nil
+		case rv.Type() == rtTime:
+			result[i] = rv.Interface().(time.Time).UTC().Format(time.RFC3339Nano)
+		default:
+			result[i] = rv.Interface()
+		}
+	}
+
+	return result
+}
+
+func (a *Adapter) version(table string) string {
+	var (
+		key = versionKey(table)
+	)
+
+	if version, ok := a.store.Get(key); ok {
+		return version.(string)
+	}
+
+	var (
+		version = newVersion()
+	)
+
+	a.store.Set(key, version, 0)
+	return version
+}
+
+func versionKey(table string) string {
+	return "rel:cache:version:" + table
+}
+
+func newVersion() string {
+	return fmt.Sprintf("%x.%x", time.Now().UnixNano(), atomic.AddInt64(&versionSeq, 1))
+}
+
+// queryTables collects tables used by the query and its sub queries.
+// Query that uses raw sql can't be cached, because its tables are unknown.
+func queryTables(tables []string, query rel.Query) ([]string, bool) {
+	if query.SQLQuery.Statement != "" {
+		return nil, false
+	}
+
+	var (
+		ok bool
+	)
+
+	tables = appendTable(tables, query.Table)
+
+	if query.TableQuery != nil {
+		if tables, ok = queryTables(tables, *query.TableQuery); !ok {
+			return nil, false
+		}
+	}
+
+	for i := range query.WithQuery {
+		if tables, ok = queryTables(tables, query.WithQuery[i].Query); !ok {
+			return nil, false
+		}
+
+		if query.WithQuery[i].Recursive != nil {
+			if tables, ok = queryTables(tables, *query.WithQuery[i].Recursive); !ok {
+				return nil, false
+			}
+		}
+	}
+
+	for i := range query.JoinQuery {
+		if query.JoinQuery[i].Table == "" {
+			return nil, false
+		}
+
+		tables = appendTable(tables, query.JoinQuery[i].Table)
+	}
+
+	for i := range query.CombineQuery {
+		if tables, ok = queryTables(tables, query.CombineQuery[i].Query); !ok {
+			return nil, false
+		}
+	}
+
+	if tables, ok = filterTables(tables, query.WhereQuery); !ok {
+		return nil, false
+	}
+
+	return filterTables(tables, query.GroupQuery.Filter)
+}
+
+func filterTables(tables []string, filter rel.FilterQuery) ([]string, bool) {
+	var (
+		ok = true
+	)
+
+	switch v := filter.Value.(type) {
+	case rel.Query:
+		tables, ok = queryTables(tables, v)
+	case []interface{}:
+		for i := range v {
+			if sub, isQuery := v[i].(rel.Query); isQuery && ok {
+				tables, ok = queryTables(tables, sub)
+			}
+		}
+	}
+
+	for i := range filter.Inner {
+		if !ok {
+			break
+		}
+
+		tables, ok = filterTables(tables, filter.Inner[i])
+	}
+
+	return tables, ok
+}
+
+// appendTable appends table without its alias (eg: users AS buyer), so it's matched with the invalidated table.
+func appendTable(tables []string, table string) []string {
+	if i := strings.Index(strings.ToLower(table), " as "); i >= 0 {
+		table = table[:i]
+	}
+
+	for i := range tables {
+		if tables[i] == table {
+			return tables
+		}
+	}
+
+	return append(tables, table)
+}
+
+// New caching adapter that stores results in the store for the given ttl.
+// Zero ttl means the results are kept until invalidated or evicted by the store.
+func New(adapter rel.Adapter, store Store, ttl time.Duration) *Adapter {
+	return &Adapter{
+		Adapter: adapter,
+		store:   store,
+		ttl:     ttl,
+	}
+}
@@ -0,0 +1,610 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testAdapter struct {
+	mock.Mock
+	returning bool
+}
+
+var _ rel.ReturningAdapter = (*testAdapter)(nil)
+
+func (ta *testAdapter) Instrumentation(instrumenter rel.Instrumenter) {
+}
+
+func (ta *testAdapter) Ping(ctx context.Context) error {
+	args := ta.Called()
+	return args.Error(0)
+}
+
+func (ta *testAdapter) Aggregate(ctx context.Context, query rel.Query, mode string, field string) (int, error) {
+	args := ta.Called(query, mode, field)
+	return args.Int(0), args.Error(1)
+}
+
+func (ta *testAdapter) Query(ctx context.Context, query rel.Query) (rel.Cursor, error) {
+	args := ta.Called(query)
+	cur, _ := args.Get(0).(rel.Cursor)
+	return cur, args.Error(1)
+}
+
+func (ta *testAdapter) Insert(ctx context.Context, query rel.Query, primaryFields []string, mutates map[string]rel.Mutate, onConflict rel.OnConflict) (interface{}, error) {
+	args := ta.Called(query, mutates, onConflict)
+	return args.Get(0), args.Error(1)
+}
+
+func (ta *testAdapter) InsertAll(ctx context.Context, query rel.Query, primaryFields []string, fields []string, mutates []map[string]rel.Mutate, onConflict rel.OnConflict) ([]interface{}, error) {
+	args := ta.Called(query, mutates, onConflict)
+	return args.Get(0).([]interface{}), args.Error(1)
+}
+
+func (ta *testAdapter) Update(ctx context.Context, query rel.Query, mutates map[string]rel.Mutate) (int, error) {
+	args := ta.Called(query, mutates)
+	return args.Int(0), args.Error(1)
+}
+
+func (ta *testAdapter) Delete(ctx context.Context, query rel.Query) (int, error) {
+	args := ta.Called(query)
+	return args.Int(0), args.Error(1)
+}
+
+func (ta *testAdapter) SupportReturning() bool {
+	return ta.returning
+}
+
+func (ta *testAdapter) InsertReturning(ctx context.Context, query rel.Query, mutates map[string]rel.Mutate, onConflict rel.OnConflict) (rel.Cursor, error) {
+	args := ta.Called(query, mutates, onConflict)
+	return args.Get(0).(rel.Cursor), args.Error(1)
+}
+
+func (ta *testAdapter) UpdateReturning(ctx context.Context, query rel.Query, mutates map[string]rel.Mutate) (rel.Cursor, error) {
+	args := ta.Called(query, mutates)
+	return args.Get(0).(rel.Cursor), args.Error(1)
+}
+
+func (ta *testAdapter) Exec(ctx context.Context, statement string, args []interface{}) (int64, int64, error) {
+	ret := ta.Called(statement, args)
+	return int64(ret.Int(0)), int64(ret.Int(1)), ret.Error(2)
+}
+
+func (ta *testAdapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	args := ta.Called()
+	return ta, args.Error(0)
+}
+
+func (ta *testAdapter) Commit(ctx context.Context) error {
+	args := ta.Called()
+	return args.Error(0)
+}
+
+func (ta *testAdapter) Rollback(ctx context.Context) error {
+	args := ta.Called()
+	return args.Error(0)
+}
+
+func (ta *testAdapter) Apply(ctx context.Context, migration rel.Migration) error {
+	args := ta.Called(migration)
+	return args.Error(0)
+}
+
+type Country struct {
+	ID   int
+	Code string
+	Name *string
+}
+
+func createCursor(rows ...[]interface{}) *testCursor {
+	return &testCursor{
+		fields: []string{"id", "code", "name"},
+		rows:   rows,
+	}
+}
+
+func TestAdapter_Query(t *testing.T) {
+	var (
+		ctx       = context.TODO()
+		countries []Country
+		name      = "Indonesia"
+		child     = &testAdapter{}
+		repo      = rel.New(New(child, NewLRU(10), time.Minute))
+		query     = rel.From("countries").Where(where.Eq("code", "ID"))
+		result    = []Country{{ID: 1, Code: "ID", Name: &name}}
+	)
+
+	child.On("Query", query).Return(createCursor([]interface{}{int64(1), []byte("ID"), []byte("Indonesia")}), nil).Once()
+
+	assert.Nil(t, repo.FindAll(ctx, &countries, query))
+	assert.Equal(t, result, countries)
+
+	countries = nil
+	assert.Nil(t, repo.FindAll(ctx, &countries, query))
+	assert.Equal(t, result, countries)
+
+	child.AssertExpectations(t)
+}
+
+func TestAdapter_Query_differentArguments(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		country Country
+		child   = &testAdapter{}
+		repo    = rel.New(New(child, NewLRU(10), 0))
+	)
+
+	child.On("Query", rel.From("countries").Where(where.Eq("id", 1)).Limit(1)).Return(createCursor([]interface{}{1, "ID", nil}), nil).Once()
+	child.On("Query", rel.From("countries").Where(where.Eq("id", "1")).Limit(1)).Return(createCursor([]interface{}{1, "ID", nil}), nil).Once()
+
+	assert.Nil(t, repo.Find(ctx, &country, where.Eq("id", 1)))
+	assert.Nil(t, repo.Find(ctx, &country, where.Eq("id", "1")))
+	assert.Equal(t, Country{ID: 1, Code: "ID"}, country)
+
+	child.AssertExpectations(t)
+}
+
+func TestAdapter_Query_normalizedArguments(t *testing.T) {
+	var (
+		ctx       = context.TODO()
+		countries []Country
+		child     = &testAdapter{}
+		repo      = rel.New(New(child, NewLRU(10), 0))
+		code1     = "ID"
+		code2     = "ID"
+		at        = time.Date(2020, 1, 1, 7, 0, 0, 0, time.FixedZone("WIB", 7*3600))
+	)
+
+	child.On("Query", rel.From("countries").Where(where.Eq("code", &code1), where.Lt("created_at", at))).Return(createCursor([]interface{}{1, "ID", nil}), nil).Once()
+
+	assert.Nil(t, repo.FindAll(ctx, &countries, where.Eq("code", &code1), where.Lt("created_at", at)))
+	assert.Nil(t, repo.FindAll(ctx, &countries, where.Eq("code", &code2), where.Lt("created_at", at.UTC())))
+	assert.Equal(t, []Country{{ID: 1, Code: "ID"}}, countries)
+
+	child.AssertExpectations(t)
+}
+
+func TestAdapter_Query_notCached(t *testing.T) {
+	tests := []struct {
+		name  string
+		query rel.Query
+	}{
+		{
+			name:  "raw sql",
+			query: rel.Build("", rel.SQL("SELECT * FROM countries;")),
+		},
+		{
+			name:  "lock",
+			query: rel.From("countries").Lock("FOR UPDATE"),
+		},
+		{
+			name:  "join fragment",
+			query: rel.From("countries").Joinf("JOIN cities ON cities.id=?", 1),
+		},
+		{
+			name:  "raw sub query",
+			query: rel.From("countries").Where(where.In("id", rel.Build("", rel.SQL("SELECT 1")))),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				ctx     = context.TODO()
+				child   = &testAdapter{}
+				adapter = New(child, NewLRU(10), 0)
+			)
+
+			child.On("Query", test.query).Return(createCursor(), nil).Twice()
+
+			_, err := adapter.Query(ctx, test.query)
+			assert.Nil(t, err)
+
+			_, err = adapter.Query(ctx, test.query)
+			assert.Nil(t, err)
+
+			child.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdapter_Query_error(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		err     = errors.New("error")
+		child   = &testAdapter{}
+		adapter = New(child, NewLRU(10), 0)
+		query   = rel.From("countries")
+	)
+
+	child.On("Query", query).Return(nil, err).Once()
+	child.On("Query", query).Return(&testCursor{fields: []string{"id"}, rows: [][]interface{}{{1}}, err: err}, nil).Once()
+	child.On("Query", query).Return(createCursor(), nil).Once()
+
+	_, queryErr := adapter.Query(ctx, query)
+	assert.Equal(t, err, queryErr)
+
+	_, queryErr = adapter.Query(ctx, query)
+	assert.Equal(t, err, queryErr)
+
+	_, queryErr = adapter.Query(ctx, query)
+	assert.Nil(t, queryErr)
+
+	child.AssertExpectations(t)
+}
+
+func TestAdapter_Aggregate(t *testing.T) {
+	var (
+		ctx   = context.TODO()
+		child = &testAdapter{}
+		repo  = rel.New(New(child, NewLRU(10), 0))
+	)
+
+	child.On("Aggregate", rel.From("countries"), "count", "*").Return(5, nil).Once()
+	child.On("Aggregate", rel.From("countries"), "max", "id").Return(10, nil).Once()
+
+	for i := 0; i < 2; i++ {
+		count, err := repo.Count(ctx, "countries")
+		assert.Nil(t, err)
+		assert.Equal(t, 5, count)
+
+		max, err := repo.Aggregate(ctx, rel.From("countries"), "max", "id")
+		assert.Nil(t, err)
+		assert.Equal(t, 10, max)
+	}
+
+	child.AssertExpectations(t)
+}
+
+func TestAdapter_Aggregate_error(t *testing.T) {
+	var (
+		ctx   = context.TODO()
+		err   = errors.New("error")
+		child = &testAdapter{}
+		repo  = rel.New(New(child, NewLRU(10), 0))
+	)
+
+	child.On("Aggregate", rel.From("countries"), "count", "*").Return(0, err).Once()
+	child.On("Aggregate", rel.From("countries"), "count", "*").Return(5, nil).Once()
+
+	_, countErr := repo.Count(ctx, "countries")
+	assert.Equal(t, err, countErr)
+
+	count, countErr := repo.Count(ctx, "countries")
+	assert.Nil(t, countErr)
+	assert.Equal(t, 5, count)
+
+	child.AssertExpectations(t)
+}
+
+func TestAdapter_invalidate(t *testing.T) {
+	var (
+		cities  = rel.From("cities")
+		mutates = map[string]rel.Mutate{"name": rel.Set("name", "Jakarta")}
+	)
+
+	tests := []struct {
+		name  string
+		table string
+		mock  func(child *testAdapter)
+		write func(adapter *Adapter) error
+	}{
+		{
+			name:  "insert",
+			table: "cities",
+			mock: func(child *testAdapter) {
+				child.On("Insert", cities, mutates, rel.OnConflict{}).Return(1, nil).Once()
+			},
+			write: func(adapter *Adapter) error {
+				_, err := adapter.Insert(context.TODO(), cities, []string{"id"}, mutates, rel.OnConflict{})
+				return err
+			},
+		},
+		{
+			name:  "insert all",
+			table: "cities",
+			mock: func(child *testAdapter) {
+				child.On("InsertAll", cities, []map[string]rel.Mutate{mutates}, rel.OnConflict{}).Return([]interface{}{1}, nil).Once()
+			},
+			write: func(adapter *Adapter) error {
+				_, err := adapter.InsertAll(context.TODO(), cities, []string{"id"}, []string{"name"}, []map[string]rel.Mutate{mutates}, rel.OnConflict{})
+				return err
+			},
+		},
+		{
+			name:  "update",
+			table: "cities",
+			mock: func(child *testAdapter) {
+				child.On("Update", cities, mutates).Return(1, nil).Once()
+			},
+			write: func(adapter *Adapter) error {
+				_, err := adapter.Update(context.TODO(), cities, mutates)
+				return err
+			},
+		},
+		{
+			name:  "delete",
+			table: "cities",
+			mock: func(child *testAdapter) {
+				child.On("Delete", cities).Return(1, nil).Once()
+			},
+			write: func(adapter *Adapter) error {
+				_, err := adapter.Delete(context.TODO(), cities)
+				return err
+			},
+		},
+		{
+			name:  "insert returning",
+			table: "cities",
+			mock: func(child *testAdapter) {
+				child.On("InsertReturning", cities, mutates, rel.OnConflict{}).Return(createCursor(), nil).Once()
+			},
+			write: func(adapter *Adapter) error {
+				_, err := adapter.InsertReturning(context.TODO(), cities, mutates, rel.OnConflict{})
+				return err
+			},
+		},
+		{
+			name:  "update returning",
+			table: "cities",
+			mock: func(child *testAdapter) {
+				child.On("UpdateReturning", cities, mutates).Return(createCursor(), nil).Once()
+			},
+			write: func(adapter *Adapter) error {
+				_, err := adapter.UpdateReturning(context.TODO(), cities, mutates)
+				return err
+			},
+		},
+		{
+			name:  "apply table",
+			table: "cities",
+			mock: func(child *testAdapter) {
+				child.On("Apply", rel.Table{Op: rel.SchemaRename, Name: "towns", Rename: "cities"}).Return(nil).Once()
+			},
+			write: func(adapter *Adapter) error {
+				return adapter.Apply(context.TODO(), rel.Table{Op: rel.SchemaRename, Name: "towns", Rename: "cities"})
+			},
+		},
+		{
+			name:  "apply index",
+			table: "cities",
+			mock: func(child *testAdapter) {
+				child.On("Apply", rel.Index{Table: "cities", Name: "name_idx"}).Return(nil).Once()
+			},
+			write: func(adapter *Adapter) error {
+				return adapter.Apply(context.TODO(), rel.Index{Table: "cities", Name: "name_idx"})
+			},
+		},
+		{
+			name:  "apply raw",
+			table: "countries",
+			mock: func(child *testAdapter) {
+				child.On("Apply", rel.Raw("DROP TABLE cities;")).Return(nil).Once()
+			},
+			write: func(adapter *Adapter) error {
+				return adapter.Apply(context.TODO(), rel.Raw("DROP TABLE cities;"))
+			},
+		},
+		{
+			name:  "exec",
+			table: "countries",
+			mock: func(child *testAdapter) {
+				child.On("Exec", "DELETE FROM cities;", []interface{}(nil)).Return(0, 1, nil).Once()
+			},
+			write: func(adapter *Adapter) error {
+				_, _, err := adapter.Exec(context.TODO(), "DELETE FROM cities;", nil)
+				return err
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				ctx         = context.TODO()
+				child       = &testAdapter{returning: true}
+				adapter     = New(child, NewLRU(10), 0)
+				query       = rel.From("countries").JoinWith("JOIN", "cities AS city", "city.country_id", "countries.id")
+				unaffected  = rel.From("provinces")
+				aggregateOf = func(query rel.Query) int {
+					count, err := adapter.Aggregate(ctx, query, "count", "*")
+					assert.Nil(t, err)
+					return count
+				}
+			)
+
+			assert.True(t, adapter.SupportReturning())
+
+			child.On("Aggregate", query, "count", "*").Return(1, nil).Once()
+			child.On("Aggregate", unaffected, "count", "*").Return(1, nil).Once()
+			test.mock(child)
+			child.On("Aggregate", query, "count", "*").Return(2, nil).Once()
+
+			assert.Equal(t, 1, aggregateOf(query))
+			assert.Equal(t, 1, aggregateOf(unaffected))
+			assert.Nil(t, test.write(adapter))
+			assert.Equal(t, 2, aggregateOf(query))
+			assert.Equal(t, 2, aggregateOf(query))
+
+			if test.name != "apply raw" && test.name != "exec" {
+				assert.Equal(t, 1, aggregateOf(unaffected))
+			}
+
+			child.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdapter_Exec_unsupported(t *testing.T) {
+	var (
+		adapter = New(&struct{ rel.Adapter }{}, NewLRU(10), 0)
+	)
+
+	_, _, err := adapter.Exec(context.TODO(), "DELETE FROM cities;", nil)
+	assert.NotNil(t, err)
+}
+
+func TestAdapter_Invalidate(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		child   = &testAdapter{}
+		adapter = New(child, NewLRU(10), 0)
+		query   = rel.From("countries").Where(where.In("id", rel.Select("country_id").From("cities")))
+	)
+
+	child.On("Aggregate", query, "count", "*").Return(1, nil).Times(3)
+
+	for _, tables := range [][]string{{"cities"}, nil} {
+		_, err := adapter.Aggregate(ctx, query, "count", "*")
+		assert.Nil(t, err)
+
+		_, err = adapter.Aggregate(ctx, query, "count", "*")
+		assert.Nil(t, err)
+
+		adapter.Invalidate(tables...)
+	}
+
+	_, err := adapter.Aggregate(ctx, query, "count", "*")
+	assert.Nil(t, err)
+
+	child.AssertExpectations(t)
+}
+
+func TestAdapter_ttl(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		now     = time.Now()
+		child   = &testAdapter{}
+		store   = NewLRU(10)
+		adapter = New(child, store, time.Minute)
+		query   = rel.From("countries")
+	)
+
+	store.nowFunc = func() time.Time { return now }
+	child.On("Aggregate", query, "count", "*").Return(1, nil).Twice()
+
+	for i := 0; i < 2; i++ {
+		_, err := adapter.Aggregate(ctx, query, "count", "*")
+		assert.Nil(t, err)
+	}
+
+	now = now.Add(time.Minute)
+
+	_, err := adapter.Aggregate(ctx, query, "count", "*")
+	assert.Nil(t, err)
+
+	child.AssertExpectations(t)
+}
+
+func TestAdapter_Transaction(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		child   = &testAdapter{}
+		adapter = New(child, NewLRU(10), 0)
+		query   = rel.From("countries")
+		mutates = map[string]rel.Mutate{"name": rel.Set("name", "Indonesia")}
+	)
+
+	child.On("Begin").Return(nil).Twice()
+	child.On("Aggregate", query, "count", "*").Return(1, nil).Twice()
+	child.On("Insert", query, mutates, rel.OnConflict{}).Return(1, nil).Once()
+	child.On("Aggregate", query, "count", "*").Return(1, nil).Once()
+	child.On("Commit").Return(nil).Twice()
+	child.On("Aggregate", query, "count", "*").Return(2, nil).Once()
+
+	tx, err := adapter.Begin(ctx)
+	assert.Nil(t, err)
+
+	nested, err := tx.Begin(ctx)
+	assert.Nil(t, err)
+
+	// not cached inside transaction.
+	for i := 0; i < 2; i++ {
+		_, err = nested.Aggregate(ctx, query, "count", "*")
+		assert.Nil(t, err)
+	}
+
+	_, err = nested.Insert(ctx, query, []string{"id"}, mutates, rel.OnConflict{})
+	assert.Nil(t, err)
+
+	// uncommitted changes is not visible outside, and the result is cached.
+	for i := 0; i < 2; i++ {
+		count, err := adapter.Aggregate(ctx, query, "count", "*")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, count)
+	}
+
+	assert.Nil(t, nested.Commit(ctx))
+	assert.Nil(t, tx.Commit(ctx))
+
+	count, err := adapter.Aggregate(ctx, query, "count", "*")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	child.AssertExpectations(t)
+}
+
+func TestAdapter_Transaction_error(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		err     = errors.New("error")
+		child   = &testAdapter{}
+		adapter = New(child, NewLRU(10), 0)
+	)
+
+	child.On("Begin").Return(err).Once()
+
+	_, beginErr := adapter.Begin(ctx)
+	assert.Equal(t, err, beginErr)
+
+	child.On("Begin").Return(nil).Once()
+	child.On("Commit").Return(err).Once()
+	child.On("Rollback").Return(nil).Once()
+
+	tx, beginErr := adapter.Begin(ctx)
+	assert.Nil(t, beginErr)
+	assert.Equal(t, err, tx.Commit(ctx))
+	assert.Nil(t, tx.Rollback(ctx))
+
+	child.AssertExpectations(t)
+}
+
+func TestAdapter_Ping(t *testing.T) {
+	var (
+		child   = &testAdapter{}
+		adapter = New(child, NewLRU(10), 0)
+	)
+
+	child.On("Ping").Return(nil).Once()
+
+	assert.Nil(t, adapter.Ping(context.TODO()))
+	assert.False(t, adapter.SupportReturning())
+
+	child.AssertExpectations(t)
+}
+
+func TestKeyArgs(t *testing.T) {
+	var (
+		code    = "ID"
+		codePtr = &code
+		at      = time.Date(2020, 1, 1, 7, 0, 0, 5, time.FixedZone("WIB", 7*3600))
+		nilPtr  *string
+	)
+
+	assert.Equal(t, []interface{}{
+		1,
+		"ID",
+		"ID",
+		"2020-01-01T00:00:00.000000005Z",
+		"2020-01-01T00:00:00.000000005Z",
+		nil,
+		nil,
+	}, keyArgs([]interface{}{1, "ID", &codePtr, at, &at, nilPtr, nil}))
+}
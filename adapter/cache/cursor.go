@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+
+	"github.com/go-rel/rel"
+)
+
+// result of a query that is read into memory, so it can be replayed by multiple cursors.
+type result struct {
+	fields []string
+	rows   [][]interface{}
+}
+
+func (r *result) cursor() *cursor {
+	return &cursor{result: r}
+}
+
+func newResult(cur rel.Cursor) (*result, error) {
+	defer cur.Close()
+
+	fields, err := cur.Fields()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		res = &result{fields: fields}
+	)
+
+	for cur.Next() {
+		var (
+			row  = make([]interface{}, len(fields))
+			dest = make([]interface{}, len(fields))
+		)
+
+		for i := range dest {
+			dest[i] = &row[i]
+		}
+
+		if err := cur.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		res.rows = append(res.rows, row)
+	}
+
+	return res, nil
+}
+
+// cursor replays a cached result.
+type cursor struct {
+	*result
+	index int
+}
+
+func (c *cursor) Close() error {
+	return nil
+}
+
+func (c *cursor) Fields() ([]string, error) {
+	return c.fields, nil
+}
+
+func (c *cursor) Next() bool {
+	c.index++
+	return c.index <= len(c.rows)
+}
+
+func (c *cursor) Scan(dest ...interface{}) error {
+	if c.index < 1 || c.index > len(c.rows) {
+		return errors.New("rel: scan called without calling next")
+	}
+
+	var (
+		row = c.rows[c.index-1]
+	)
+
+	if len(dest) != len(row) {
+		return errors.New("rel: number of scan destination doesn't match the number of fields")
+	}
+
+	for i := range dest {
+		if err := assign(dest[i], row[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *cursor) NopScanner() interface{} {
+	return new(interface{})
+}
+
+// assign value to the scan destination the same way database/sql does.
+// bytes are copied so the cached value can't be modified through the destination.
+func assign(dest interface{}, src interface{}) error {
+	if b, ok := src.([]byte); ok {
+		src = cloneBytes(b)
+	}
+
+	switch d := dest.(type) {
+	case sql.Scanner:
+		return d.Scan(src)
+	case *interface{}:
+		*d = src
+		return nil
+	}
+
+	var (
+		rv = reflect.ValueOf(dest)
+	)
+
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("rel: scan destination must be a non-nil pointer")
+	}
+
+	if rv.Elem().Kind() == reflect.Ptr {
+		if src == nil {
+			rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+			return nil
+		}
+
+		var (
+			ptr = reflect.New(rv.Elem().Type().Elem())
+		)
+
+		if err := assign(ptr.Interface(), src); err != nil {
+			return err
+		}
+
+		rv.Elem().Set(ptr)
+		return nil
+	}
+
+	return rel.Nullable(dest).(sql.Scanner).Scan(src)
+}
+
+func cloneBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-rel/rel"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCursor struct {
+	fields []string
+	rows   [][]interface{}
+	index  int
+	err    error
+	closed bool
+}
+
+var _ rel.Cursor = (*testCursor)(nil)
+
+func (tc *testCursor) Close() error {
+	tc.closed = true
+	return nil
+}
+
+func (tc *testCursor) Fields() ([]string, error) {
+	return tc.fields, tc.err
+}
+
+func (tc *testCursor) Next() bool {
+	tc.index++
+	return tc.index <= len(tc.rows)
+}
+
+func (tc *testCursor) NopScanner() interface{} {
+	return &sql.RawBytes{}
+}
+
+func (tc *testCursor) Scan(dest ...interface{}) error {
+	if tc.err != nil {
+		return tc.err
+	}
+
+	for i, value := range tc.rows[tc.index-1] {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(&value).Elem())
+	}
+
+	return nil
+}
+
+func TestResult(t *testing.T) {
+	var (
+		child = &testCursor{
+			fields: []string{"id", "name"},
+			rows:   [][]interface{}{{1, "a"}, {2, nil}},
+		}
+	)
+
+	res, err := newResult(child)
+	assert.Nil(t, err)
+	assert.True(t, child.closed)
+	assert.Equal(t, []string{"id", "name"}, res.fields)
+	assert.Equal(t, [][]interface{}{{1, "a"}, {2, nil}}, res.rows)
+
+	for i := 0; i < 2; i++ {
+		var (
+			cur        = res.cursor()
+			id         int
+			name       string
+			fields, _  = cur.Fields()
+			scanErr    = cur.Scan(&id, &name)
+			nopScanner = cur.NopScanner()
+		)
+
+		assert.Equal(t, []string{"id", "name"}, fields)
+		assert.EqualError(t, scanErr, "rel: scan called without calling next")
+		assert.Equal(t, new(interface{}), nopScanner)
+
+		assert.True(t, cur.Next())
+		assert.Nil(t, cur.Scan(&id, &name))
+		assert.Equal(t, 1, id)
+		assert.Equal(t, "a", name)
+
+		assert.True(t, cur.Next())
+		assert.Nil(t, cur.Scan(&id, nopScanner))
+		assert.Equal(t, 2, id)
+		assert.EqualError(t, cur.Scan(&id), "rel: number of scan destination doesn't match the number of fields")
+
+		assert.False(t, cur.Next())
+		assert.Nil(t, cur.Close())
+	}
+}
+
+func TestResult_error(t *testing.T) {
+	var (
+		err = errors.New("error")
+	)
+
+	_, resErr := newResult(&testCursor{err: err})
+	assert.Equal(t, err, resErr)
+}
+
+func TestAssign(t *testing.T) {
+	var (
+		now    = time.Now()
+		bytes  = []byte("rel")
+		str    *string
+		ptr    = new(int)
+		value  interface{}
+		buffer []byte
+		null   sql.NullString
+		date   time.Time
+		number float64
+	)
+
+	assert.Nil(t, assign(&str, bytes))
+	assert.Equal(t, "rel", *str)
+
+	assert.Nil(t, assign(&str, nil))
+	assert.Nil(t, str)
+
+	assert.Nil(t, assign(&ptr, int64(1)))
+	assert.Equal(t, 1, *ptr)
+
+	assert.Nil(t, assign(&value, bytes))
+	assert.Equal(t, bytes, value)
+
+	assert.Nil(t, assign(&buffer, bytes))
+	buffer[0] = 'R'
+	assert.Equal(t, []byte("rel"), bytes)
+
+	assert.Nil(t, assign(&null, "rel"))
+	assert.Equal(t, sql.NullString{String: "rel", Valid: true}, null)
+
+	assert.Nil(t, assign(&date, now))
+	assert.Equal(t, now, date)
+
+	assert.Nil(t, assign(&number, int64(1)))
+	assert.Equal(t, float64(1), number)
+
+	assert.EqualError(t, assign(number, int64(1)), "rel: scan destination must be a non-nil pointer")
+	assert.NotNil(t, assign(&ptr, "rel"))
+}
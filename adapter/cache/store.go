@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Store is the storage of cached results.
+// Zero ttl means the value never expires, but it may still be evicted by the store.
+type Store interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{}, ttl time.Duration)
+}
+
+// LRU is an in-memory store that evicts the least recently used value when it's full.
+type LRU struct {
+	size    int
+	items   map[string]*list.Element
+	order   *list.List
+	mutex   sync.Mutex
+	nowFunc func() time.Time
+}
+
+var (
+	_ Store = (*LRU)(nil)
+)
+
+type entry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+// Get value of the key, expired value is removed.
+func (l *LRU) Get(key string) (interface{}, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	elem, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	var (
+		e = elem.Value.(*entry)
+	)
+
+	if !e.expires.IsZero() && !l.nowFunc().Before(e.expires) {
+		l.remove(elem)
+		return nil, false
+	}
+
+	l.order.MoveToFront(elem)
+	return e.value, true
+}
+
+// Set value of the key.
+func (l *LRU) Set(key string, value interface{}, ttl time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var (
+		expires time.Time
+	)
+
+	if ttl > 0 {
+		expires = l.nowFunc().Add(ttl)
+	}
+
+	if elem, ok := l.items[key]; ok {
+		e := elem.Value.(*entry)
+		e.value = value
+		e.expires = expires
+		l.order.MoveToFront(elem)
+		return
+	}
+
+	l.items[key] = l.order.PushFront(&entry{key: key, value: value, expires: expires})
+
+	for l.order.Len() > l.size {
+		l.remove(l.order.Back())
+	}
+}
+
+// Len returns number of values in the store.
+func (l *LRU) Len() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.order.Len()
+}
+
+func (l *LRU) remove(elem *list.Element) {
+	l.order.Remove(elem)
+	delete(l.items, elem.Value.(*entry).key)
+}
+
+// NewLRU store that holds at most size values.
+func NewLRU(size int) *LRU {
+	if size <= 0 {
+		panic("rel: lru size must be greater than zero")
+	}
+
+	return &LRU{
+		size:    size,
+		items:   make(map[string]*list.Element, size),
+		order:   list.New(),
+		nowFunc: time.Now,
+	}
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	var (
+		lru = NewLRU(2)
+	)
+
+	lru.Set("a", 1, 0)
+	lru.Set("b", 2, 0)
+
+	value, ok := lru.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	// b is the least recently used.
+	lru.Set("c", 3, 0)
+	assert.Equal(t, 2, lru.Len())
+
+	_, ok = lru.Get("b")
+	assert.False(t, ok)
+
+	lru.Set("a", 4, 0)
+	value, ok = lru.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 4, value)
+
+	value, ok = lru.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 3, value)
+}
+
+func TestLRU_ttl(t *testing.T) {
+	var (
+		now = time.Now()
+		lru = NewLRU(2)
+	)
+
+	lru.nowFunc = func() time.Time { return now }
+	lru.Set("a", 1, time.Second)
+	lru.Set("b", 2, 0)
+
+	now = now.Add(time.Second)
+
+	_, ok := lru.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 1, lru.Len())
+
+	value, ok := lru.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+}
+
+func TestNewLRU(t *testing.T) {
+	assert.Panics(t, func() {
+		NewLRU(0)
+	})
+}
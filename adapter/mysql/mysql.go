@@ -30,12 +30,13 @@ var (
 
 	// Config for mysql adapter.
 	Config = sql.Config{
-		DropIndexOnTable: true,
-		Placeholder:      "?",
-		EscapeChar:       "`",
-		IncrementFunc:    incrementFunc,
-		ErrorFunc:        errorFunc,
-		MapColumnFunc:    sql.MapColumn,
+		DropIndexOnTable:     true,
+		OnDuplicateKeyUpdate: true,
+		Placeholder:          "?",
+		EscapeChar:           "`",
+		IncrementFunc:        incrementFunc,
+		ErrorFunc:            errorFunc,
+		MapColumnFunc:        sql.MapColumn,
 	}
 )
 
@@ -106,6 +107,11 @@ func errorFunc(err error) error {
 			Type: rel.ForeignKeyConstraint,
 			Err:  err,
 		}
+	case "Error 1213":
+		return rel.TransactionConflictError{
+			Type: rel.Deadlock,
+			Err:  err,
+		}
 	default:
 		return err
 	}
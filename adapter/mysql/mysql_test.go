@@ -65,6 +65,8 @@ func TestAdapter_specs(t *testing.T) {
 	specs.InsertBelongsTo(t, repo)
 	specs.Inserts(t, repo)
 	specs.InsertAll(t, repo)
+	specs.InsertOnConflictIgnore(t, repo)
+	specs.InsertOnConflictReplace(t, repo)
 	specs.InsertAllPartialCustomPrimary(t, repo)
 
 	// Update Specs
@@ -133,6 +135,13 @@ func TestAdapter_Exec_error(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestErrorFunc(t *testing.T) {
+	assert.Nil(t, errorFunc(nil))
+	assert.True(t, errors.Is(errorFunc(errors.New("Error 1213: Deadlock found when trying to get lock; try restarting transaction")), rel.ErrDeadlock))
+	assert.True(t, errors.Is(errorFunc(errors.New("Error 1062: Duplicate entry '1' for key 'PRIMARY'")), rel.ErrUniqueConstraint))
+	assert.Equal(t, errors.New("error"), errorFunc(errors.New("error")))
+}
+
 func TestCheck(t *testing.T) {
 	assert.Panics(t, func() {
 		check(errors.New("error"))
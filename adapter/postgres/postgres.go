@@ -19,6 +19,7 @@ import (
 
 	"github.com/go-rel/rel"
 	"github.com/go-rel/rel/adapter/sql"
+	"github.com/lib/pq"
 )
 
 // Adapter definition for postgres database.
@@ -35,6 +36,7 @@ var (
 		EscapeChar:          "\"",
 		Ordinal:             true,
 		InsertDefaultValues: true,
+		Returning:           true,
 		ErrorFunc:           errorFunc,
 		MapColumnFunc:       mapColumnFunc,
 	}
@@ -57,34 +59,40 @@ func Open(dsn string) (*Adapter, error) {
 }
 
 // Insert inserts a record to database and returns its id.
-func (adapter *Adapter) Insert(ctx context.Context, query rel.Query, primaryField string, mutates map[string]rel.Mutate) (interface{}, error) {
+// Returned id is scanned as is, and will be converted to the actual type of primary field by rel.
+// When composite primary is used, returned id will be a slice of primary values.
+func (adapter *Adapter) Insert(ctx context.Context, query rel.Query, primaryFields []string, mutates map[string]rel.Mutate, onConflict rel.OnConflict) (interface{}, error) {
 	var (
-		id              int64
-		statement, args = sql.NewBuilder(adapter.Config).Returning(primaryField).Insert(query.Table, mutates)
-		rows, err       = adapter.query(ctx, statement, args)
+		id              interface{}
+		statement, args = sql.NewBuilder(adapter.Config).Returning(primaryFields...).Insert(query.Table, mutates, onConflict)
+		rows, err       = adapter.QueryRows(ctx, statement, args)
 	)
 
 	if err == nil && rows.Next() {
 		defer rows.Close()
-		rows.Scan(&id)
+		id, err = scanPrimary(rows, len(primaryFields))
 	}
 
 	return id, err
 }
 
 // InsertAll inserts multiple records to database and returns its ids.
-func (adapter *Adapter) InsertAll(ctx context.Context, query rel.Query, primaryField string, fields []string, bulkMutates []map[string]rel.Mutate) ([]interface{}, error) {
+// When conflicting rows are ignored, the number of returned ids will be less than the number of records.
+func (adapter *Adapter) InsertAll(ctx context.Context, query rel.Query, primaryFields []string, fields []string, bulkMutates []map[string]rel.Mutate, onConflict rel.OnConflict) ([]interface{}, error) {
 	var (
 		ids             []interface{}
-		statement, args = sql.NewBuilder(adapter.Config).Returning(primaryField).InsertAll(query.Table, fields, bulkMutates)
-		rows, err       = adapter.query(ctx, statement, args)
+		statement, args = sql.NewBuilder(adapter.Config).Returning(primaryFields...).InsertAll(query.Table, fields, bulkMutates, onConflict)
+		rows, err       = adapter.QueryRows(ctx, statement, args)
 	)
 
 	if err == nil {
 		defer rows.Close()
 		for rows.Next() {
-			var id int64
-			rows.Scan(&id)
+			var id interface{}
+			if id, err = scanPrimary(rows, len(primaryFields)); err != nil {
+				return nil, err
+			}
+
 			ids = append(ids, id)
 		}
 	}
@@ -92,21 +100,25 @@ func (adapter *Adapter) InsertAll(ctx context.Context, query rel.Query, primaryF
 	return ids, err
 }
 
-func (adapter *Adapter) query(ctx context.Context, statement string, args []interface{}) (*db.Rows, error) {
+func scanPrimary(rows *db.Rows, count int) (interface{}, error) {
 	var (
-		err  error
-		rows *db.Rows
+		values   = make([]interface{}, count)
+		scanners = make([]interface{}, count)
 	)
 
-	finish := adapter.Instrumenter.Observe(ctx, "adapter-query", statement)
-	if adapter.Tx != nil {
-		rows, err = adapter.Tx.QueryContext(ctx, statement, args...)
-	} else {
-		rows, err = adapter.DB.QueryContext(ctx, statement, args...)
+	for i := range values {
+		scanners[i] = &values[i]
+	}
+
+	if err := rows.Scan(scanners...); err != nil {
+		return nil, err
+	}
+
+	if count == 1 {
+		return values[0], nil
 	}
-	finish(err)
 
-	return rows, errorFunc(err)
+	return values, nil
 }
 
 // Begin begins a new transaction.
@@ -128,6 +140,21 @@ func errorFunc(err error) error {
 		constraintType = sql.ExtractString(msg, "violates ", " constraint")
 	)
 
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code {
+		case "40001": // serialization_failure
+			return rel.TransactionConflictError{
+				Type: rel.SerializationFailure,
+				Err:  err,
+			}
+		case "40P01": // deadlock_detected
+			return rel.TransactionConflictError{
+				Type: rel.Deadlock,
+				Err:  err,
+			}
+		}
+	}
+
 	switch constraintType {
 	case "unique":
 		return rel.ConstraintError{
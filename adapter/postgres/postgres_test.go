@@ -4,13 +4,14 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/go-rel/rel"
 	"github.com/go-rel/rel/adapter/specs"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -69,6 +70,8 @@ func TestAdapter_specs(t *testing.T) {
 	specs.InsertBelongsTo(t, repo)
 	specs.Inserts(t, repo)
 	specs.InsertAll(t, repo)
+	specs.InsertOnConflictIgnore(t, repo)
+	specs.InsertOnConflictReplace(t, repo)
 	specs.InsertAllPartialCustomPrimary(t, repo)
 
 	// Update Specs
@@ -98,6 +101,56 @@ func TestAdapter_specs(t *testing.T) {
 	specs.CheckConstraint(t, repo)
 }
 
+func TestAdapter_Insert_statementCache(t *testing.T) {
+	type Name struct {
+		ID   int
+		Name string
+	}
+
+	var (
+		hits, misses int
+	)
+
+	adapter, err := Open(dsn())
+	assert.Nil(t, err)
+	defer adapter.Close()
+
+	_, _, err = adapter.Exec(ctx, "CREATE TABLE IF NOT EXISTS names (id SERIAL PRIMARY KEY, name TEXT);", nil)
+	assert.Nil(t, err)
+	defer adapter.Exec(ctx, "DROP TABLE names;", nil)
+
+	adapter.Config.StatementCacheSize = 10
+	repo := rel.New(adapter)
+	repo.Instrumentation(func(ctx context.Context, op string, message string) func(err error) {
+		switch op {
+		case "adapter-statement-cache-hit":
+			hits++
+		case "adapter-statement-cache-miss":
+			misses++
+		}
+
+		return func(error) {}
+	})
+
+	for i := 0; i < 2; i++ {
+		name := Name{Name: "Luffy"}
+		assert.Nil(t, repo.Insert(ctx, &name))
+		assert.NotZero(t, name.ID)
+	}
+
+	assert.Equal(t, 1, hits)
+	assert.Equal(t, 1, misses)
+
+	for i := 0; i < 2; i++ {
+		names := []Name{{Name: "Luffy"}, {Name: "Zoro"}}
+		assert.Nil(t, repo.InsertAll(ctx, &names))
+		assert.NotZero(t, names[1].ID)
+	}
+
+	assert.Equal(t, 2, hits)
+	assert.Equal(t, 2, misses)
+}
+
 func TestAdapter_Transaction_commitError(t *testing.T) {
 	adapter, err := Open(dsn())
 	assert.Nil(t, err)
@@ -122,3 +175,12 @@ func TestAdapter_Exec_error(t *testing.T) {
 	_, _, err = adapter.Exec(ctx, "error", nil)
 	assert.NotNil(t, err)
 }
+
+func TestErrorFunc(t *testing.T) {
+	assert.Nil(t, errorFunc(nil))
+	assert.True(t, errors.Is(errorFunc(&pq.Error{Code: "40001", Message: "could not serialize access due to concurrent update"}), rel.ErrSerializationFailure))
+	assert.True(t, errors.Is(errorFunc(&pq.Error{Code: "40P01", Message: "deadlock detected"}), rel.ErrDeadlock))
+	assert.False(t, errors.Is(errorFunc(errors.New("pq: deadlock detected")), rel.ErrDeadlock))
+	assert.True(t, errors.Is(errorFunc(errors.New(`pq: duplicate key value violates unique constraint "users_pkey"`)), rel.ErrUniqueConstraint))
+	assert.Equal(t, errors.New("error"), errorFunc(errors.New("error")))
+}
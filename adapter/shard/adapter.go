@@ -0,0 +1,588 @@
+// Package shard wraps multiple adapters as a single sharded adapter for rel.
+//
+// Every operation is routed to a shard using the value of the shard key,
+// which is taken from the filter of the query or from the mutates of inserted record.
+// Queries without shard key are executed on all shards, and the results are merged.
+//
+// Usage:
+//
+//	// open a connection for every shard.
+//	shards := []rel.Adapter{shard0, shard1, shard2}
+//
+//	// initialize rel's repo using user_id as shard key.
+//	repo := rel.New(shard.New("user_id", shards, nil))
+package shard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/go-rel/rel"
+)
+
+var (
+	// ErrCrossShard is returned when operation inside a transaction touches more than one shard.
+	ErrCrossShard = errors.New("Transaction across multiple shards is not supported")
+
+	// ErrMissingShardKey is returned when inserted record doesn't have a shard key.
+	ErrMissingShardKey = errors.New("Shard key is missing")
+
+	// ErrShardKeyMutation is returned when update may move records to other shard by modifying the shard key.
+	ErrShardKeyMutation = errors.New("Shard key can't be modified")
+
+	// ErrCrossShardAggregate is returned when average or grouped query targets more than one shard, because its results can't be merged.
+	ErrCrossShardAggregate = errors.New("Average or grouped query across multiple shards is not supported")
+)
+
+// Func returns index of the shard for the given shard key.
+type Func func(key interface{}, shards int) int
+
+// Hash is the default shard function, it uses fnv hash of the formatted key.
+func Hash(key interface{}, shards int) int {
+	var (
+		h = fnv.New32a()
+	)
+
+	h.Write([]byte(fmt.Sprint(key)))
+	return int(h.Sum32() % uint32(shards))
+}
+
+// Adapter definition for sharded database.
+type Adapter struct {
+	field  string
+	shards []rel.Adapter
+	fn     Func
+	tx     *transaction
+}
+
+var (
+	_ rel.Adapter = (*Adapter)(nil)
+)
+
+// New sharded adapter using field as shard key.
+// Hash is used when shard function is nil.
+func New(field string, shards []rel.Adapter, fn Func) *Adapter {
+	if len(shards) == 0 {
+		panic("rel: shard adapter requires at least one shard")
+	}
+
+	if fn == nil {
+		fn = Hash
+	}
+
+	return &Adapter{
+		field:  field,
+		shards: shards,
+		fn:     fn,
+	}
+}
+
+// Instrumentation set instrumenter for all shards.
+func (a *Adapter) Instrumentation(instrumenter rel.Instrumenter) {
+	for i := range a.shards {
+		a.shards[i].Instrumentation(instrumenter)
+	}
+}
+
+// Ping all shards.
+func (a *Adapter) Ping(ctx context.Context) error {
+	for i := range a.shards {
+		if err := a.shards[i].Ping(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Aggregate record using given query.
+// When query targets multiple shards, count and sum are added up and min and max are compared,
+// avg and grouped query return ErrCrossShardAggregate.
+func (a *Adapter) Aggregate(ctx context.Context, query rel.Query, mode string, field string) (int, error) {
+	targets, err := a.targets(ctx, a.shardsOf(query.WhereQuery))
+	if err != nil {
+		return 0, err
+	}
+
+	if len(targets) == 1 {
+		return targets[0].Aggregate(ctx, query, mode, field)
+	}
+
+	if mode == "avg" || len(query.GroupQuery.Fields) > 0 {
+		return 0, ErrCrossShardAggregate
+	}
+
+	var (
+		result int
+		found  bool
+	)
+
+	switch mode {
+	case "count", "sum":
+		for i := range targets {
+			n, err := targets[i].Aggregate(ctx, query, mode, field)
+			if err != nil {
+				return 0, err
+			}
+
+			result += n
+		}
+	default:
+		for i := range targets {
+			// empty shard is skipped, otherwise its zero result would be compared.
+			if count, err := targets[i].Aggregate(ctx, query, "count", field); err != nil {
+				return 0, err
+			} else if count == 0 {
+				continue
+			}
+
+			n, err := targets[i].Aggregate(ctx, query, mode, field)
+			if err != nil {
+				return 0, err
+			}
+
+			if !found || (mode == "max" && n > result) || (mode == "min" && n < result) {
+				result = n
+				found = true
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Query performs query operation.
+// When query targets multiple shards, results are merged using the sort, offset and limit of the query,
+// grouped query returns ErrCrossShardAggregate.
+func (a *Adapter) Query(ctx context.Context, query rel.Query) (rel.Cursor, error) {
+	var (
+		indexes []int
+	)
+
+	if query.SQLQuery.Statement == "" {
+		indexes = a.shardsOf(query.WhereQuery)
+	}
+
+	targets, err := a.targets(ctx, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(targets) == 1 {
+		return targets[0].Query(ctx, query)
+	}
+
+	if len(query.GroupQuery.Fields) > 0 {
+		return nil, ErrCrossShardAggregate
+	}
+
+	var (
+		offset  = int(query.OffsetQuery)
+		limit   = int(query.LimitQuery)
+		cursors = make([]rel.Cursor, 0, len(targets))
+	)
+
+	// every shard needs to return enough rows for the merged result to be offset.
+	if query.SQLQuery.Statement == "" {
+		query.OffsetQuery = 0
+		if limit > 0 {
+			query.LimitQuery = rel.Limit(offset + limit)
+		}
+	} else {
+		offset, limit = 0, 0
+	}
+
+	for i := range targets {
+		cur, err := targets[i].Query(ctx, query)
+		if err != nil {
+			for j := range cursors {
+				cursors[j].Close()
+			}
+
+			return nil, err
+		}
+
+		cursors = append(cursors, cur)
+	}
+
+	return newCursor(cursors, query.SortQuery, offset, limit), nil
+}
+
+// Insert inserts a record to the shard of its shard key.
+func (a *Adapter) Insert(ctx context.Context, query rel.Query, primaryFields []string, mutates map[string]rel.Mutate, onConflict rel.OnConflict) (interface{}, error) {
+	index, err := a.shardOf(mutates)
+	if err != nil {
+		return nil, err
+	}
+
+	adapter, err := a.target(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+
+	return adapter.Insert(ctx, query, primaryFields, mutates, onConflict)
+}
+
+// InsertAll inserts every record to the shard of its shard key.
+// Returned ids are in the same order as the records.
+func (a *Adapter) InsertAll(ctx context.Context, query rel.Query, primaryFields []string, fields []string, bulkMutates []map[string]rel.Mutate, onConflict rel.OnConflict) ([]interface{}, error) {
+	var (
+		order   []int
+		groups  = make(map[int][]int)
+		ids     = make([]interface{}, len(bulkMutates))
+		partial []interface{}
+		exact   = true
+	)
+
+	for i := range bulkMutates {
+		index, err := a.shardOf(bulkMutates[i])
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := groups[index]; !ok {
+			order = append(order, index)
+		}
+
+		groups[index] = append(groups[index], i)
+	}
+
+	if a.tx != nil && len(order) > 1 {
+		return nil, ErrCrossShard
+	}
+
+	for _, index := range order {
+		var (
+			rows    = groups[index]
+			mutates = make([]map[string]rel.Mutate, len(rows))
+			adapter rel.Adapter
+			result  []interface{}
+			err     error
+		)
+
+		for i, row := range rows {
+			mutates[i] = bulkMutates[row]
+		}
+
+		if adapter, err = a.target(ctx, index); err != nil {
+			return nil, err
+		}
+
+		if result, err = adapter.InsertAll(ctx, query, primaryFields, fields, mutates, onConflict); err != nil {
+			return nil, err
+		}
+
+		// ids can't be matched to the records when some of them are ignored because of conflict.
+		if len(result) != len(rows) {
+			exact = false
+		}
+
+		for i := range result {
+			if i < len(rows) {
+				ids[rows[i]] = result[i]
+			}
+		}
+
+		partial = append(partial, result...)
+	}
+
+	if !exact {
+		return partial, nil
+	}
+
+	return ids, nil
+}
+
+// Update updates records on the shards targeted by the query.
+// Records can't be moved to other shard, updating shard key is only allowed when the query targets the shard of the new key.
+func (a *Adapter) Update(ctx context.Context, query rel.Query, mutates map[string]rel.Mutate) (int, error) {
+	var (
+		indexes = a.shardsOf(query.WhereQuery)
+	)
+
+	if _, ok := mutates[a.field]; ok {
+		if index, err := a.shardOf(mutates); err != nil || len(indexes) != 1 || indexes[0] != index {
+			return 0, ErrShardKeyMutation
+		}
+	}
+
+	targets, err := a.targets(ctx, indexes)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		updated int
+	)
+
+	for i := range targets {
+		n, err := targets[i].Update(ctx, query, mutates)
+		if err != nil {
+			return updated, err
+		}
+
+		updated += n
+	}
+
+	return updated, nil
+}
+
+// Delete deletes records on the shards targeted by the query.
+func (a *Adapter) Delete(ctx context.Context, query rel.Query) (int, error) {
+	targets, err := a.targets(ctx, a.shardsOf(query.WhereQuery))
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		deleted int
+	)
+
+	for i := range targets {
+		n, err := targets[i].Delete(ctx, query)
+		if err != nil {
+			return deleted, err
+		}
+
+		deleted += n
+	}
+
+	return deleted, nil
+}
+
+// Begin begins a new transaction.
+// The transaction is started lazily on the first shard it touches,
+// touching other shard afterwards returns ErrCrossShard.
+func (a *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	return &Adapter{
+		field:  a.field,
+		shards: a.shards,
+		fn:     a.fn,
+		tx:     &transaction{parent: a.tx},
+	}, nil
+}
+
+// Commit commits current transaction.
+func (a *Adapter) Commit(ctx context.Context) error {
+	if a.tx == nil || a.tx.adapter == nil {
+		return nil
+	}
+
+	return a.tx.adapter.Commit(ctx)
+}
+
+// Rollback revert current transaction.
+func (a *Adapter) Rollback(ctx context.Context) error {
+	if a.tx == nil || a.tx.adapter == nil {
+		return nil
+	}
+
+	return a.tx.adapter.Rollback(ctx)
+}
+
+// Apply applies migration to all shards.
+func (a *Adapter) Apply(ctx context.Context, migration rel.Migration) error {
+	for i := range a.shards {
+		if err := a.shards[i].Apply(ctx, migration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Shards returns the child adapters.
+func (a *Adapter) Shards() []rel.Adapter {
+	return a.shards
+}
+
+func (a *Adapter) index(key interface{}) int {
+	return a.fn(key, len(a.shards))
+}
+
+// shardOf returns shard index of a record using its mutates.
+func (a *Adapter) shardOf(mutates map[string]rel.Mutate) (int, error) {
+	if mut, ok := mutates[a.field]; ok && mut.Type == rel.ChangeSetOp {
+		return a.index(mut.Value), nil
+	}
+
+	return 0, ErrMissingShardKey
+}
+
+// shardsOf returns shard indexes targeted by the filter, nil means all shards.
+// Shard key compared to a subquery targets all shards, because its value is unknown.
+func (a *Adapter) shardsOf(filter rel.FilterQuery) []int {
+	switch filter.Type {
+	case rel.FilterEqOp:
+		if _, subQuery := filter.Value.(rel.Query); a.isKey(filter.Field) && !subQuery {
+			return []int{a.index(filter.Value)}
+		}
+	case rel.FilterInOp:
+		if values, ok := filter.Value.([]interface{}); ok && a.isKey(filter.Field) {
+			var (
+				indexes []int
+			)
+
+			for i := range values {
+				if _, subQuery := values[i].(rel.Query); subQuery {
+					return nil
+				}
+
+				indexes = appendIndex(indexes, a.index(values[i]))
+			}
+
+			return indexes
+		}
+	case rel.FilterAndOp:
+		var (
+			indexes []int
+		)
+
+		for i := range filter.Inner {
+			if inner := a.shardsOf(filter.Inner[i]); inner != nil {
+				if indexes == nil {
+					indexes = inner
+				} else {
+					indexes = intersectIndex(indexes, inner)
+				}
+			}
+		}
+
+		return indexes
+	case rel.FilterOrOp:
+		var (
+			indexes []int
+		)
+
+		for i := range filter.Inner {
+			inner := a.shardsOf(filter.Inner[i])
+			if inner == nil {
+				return nil
+			}
+
+			for _, index := range inner {
+				indexes = appendIndex(indexes, index)
+			}
+		}
+
+		return indexes
+	}
+
+	return nil
+}
+
+func (a *Adapter) isKey(field string) bool {
+	return field == a.field || strings.HasSuffix(field, "."+a.field)
+}
+
+// targets returns adapters of the given shard indexes, nil indexes means all shards.
+func (a *Adapter) targets(ctx context.Context, indexes []int) ([]rel.Adapter, error) {
+	if indexes == nil {
+		indexes = make([]int, len(a.shards))
+		for i := range indexes {
+			indexes[i] = i
+		}
+	}
+
+	if a.tx != nil && len(indexes) > 1 {
+		return nil, ErrCrossShard
+	}
+
+	var (
+		adapters = make([]rel.Adapter, len(indexes))
+	)
+
+	for i, index := range indexes {
+		adapter, err := a.target(ctx, index)
+		if err != nil {
+			return nil, err
+		}
+
+		adapters[i] = adapter
+	}
+
+	return adapters, nil
+}
+
+func (a *Adapter) target(ctx context.Context, index int) (rel.Adapter, error) {
+	if a.tx == nil {
+		return a.shards[index], nil
+	}
+
+	return a.tx.begin(ctx, a.shards, index)
+}
+
+// transaction pins a transaction to the first shard it touches.
+type transaction struct {
+	parent  *transaction
+	index   int
+	adapter rel.Adapter
+}
+
+func (t *transaction) begin(ctx context.Context, shards []rel.Adapter, index int) (rel.Adapter, error) {
+	if t.adapter != nil {
+		if t.index != index {
+			return nil, ErrCrossShard
+		}
+
+		return t.adapter, nil
+	}
+
+	var (
+		base = shards[index]
+	)
+
+	if t.parent != nil {
+		adapter, err := t.parent.begin(ctx, shards, index)
+		if err != nil {
+			return nil, err
+		}
+
+		base = adapter
+	}
+
+	adapter, err := base.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t.index = index
+	t.adapter = adapter
+
+	return adapter, nil
+}
+
+func appendIndex(indexes []int, index int) []int {
+	for i := range indexes {
+		if indexes[i] == index {
+			return indexes
+		}
+	}
+
+	return append(indexes, index)
+}
+
+func intersectIndex(a []int, b []int) []int {
+	var (
+		result = []int{}
+	)
+
+	for _, index := range a {
+		for i := range b {
+			if b[i] == index {
+				result = append(result, index)
+				break
+			}
+		}
+	}
+
+	// contradicting shard keys won't match any record, a single shard is enough to return empty result.
+	if len(result) == 0 {
+		return a[:1]
+	}
+
+	return result
+}
@@ -0,0 +1,761 @@
+package shard
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testAdapter struct {
+	mock.Mock
+}
+
+var _ rel.Adapter = (*testAdapter)(nil)
+
+func (ta *testAdapter) Instrumentation(instrumenter rel.Instrumenter) {
+	ta.Called()
+}
+
+func (ta *testAdapter) Ping(ctx context.Context) error {
+	args := ta.Called()
+	return args.Error(0)
+}
+
+func (ta *testAdapter) Aggregate(ctx context.Context, query rel.Query, mode string, field string) (int, error) {
+	args := ta.Called(query, mode, field)
+	return args.Int(0), args.Error(1)
+}
+
+func (ta *testAdapter) Query(ctx context.Context, query rel.Query) (rel.Cursor, error) {
+	args := ta.Called(query)
+	cur, _ := args.Get(0).(rel.Cursor)
+	return cur, args.Error(1)
+}
+
+func (ta *testAdapter) Insert(ctx context.Context, query rel.Query, primaryFields []string, mutates map[string]rel.Mutate, onConflict rel.OnConflict) (interface{}, error) {
+	args := ta.Called(query, mutates, onConflict)
+	return args.Get(0), args.Error(1)
+}
+
+func (ta *testAdapter) InsertAll(ctx context.Context, query rel.Query, primaryFields []string, fields []string, mutates []map[string]rel.Mutate, onConflict rel.OnConflict) ([]interface{}, error) {
+	args := ta.Called(query, mutates, onConflict)
+	return args.Get(0).([]interface{}), args.Error(1)
+}
+
+func (ta *testAdapter) Update(ctx context.Context, query rel.Query, mutates map[string]rel.Mutate) (int, error) {
+	args := ta.Called(query, mutates)
+	return args.Int(0), args.Error(1)
+}
+
+func (ta *testAdapter) Delete(ctx context.Context, query rel.Query) (int, error) {
+	args := ta.Called(query)
+	return args.Int(0), args.Error(1)
+}
+
+func (ta *testAdapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	args := ta.Called()
+	return ta, args.Error(0)
+}
+
+func (ta *testAdapter) Commit(ctx context.Context) error {
+	args := ta.Called()
+	return args.Error(0)
+}
+
+func (ta *testAdapter) Rollback(ctx context.Context) error {
+	args := ta.Called()
+	return args.Error(0)
+}
+
+func (ta *testAdapter) Apply(ctx context.Context, migration rel.Migration) error {
+	args := ta.Called(migration)
+	return args.Error(0)
+}
+
+// modulo shard function, keeps the tests independent from the hash.
+func modulo(key interface{}, shards int) int {
+	return key.(int) % shards
+}
+
+func createAdapter(n int) (*Adapter, []*testAdapter) {
+	var (
+		shards   = make([]rel.Adapter, n)
+		adapters = make([]*testAdapter, n)
+	)
+
+	for i := range shards {
+		adapters[i] = &testAdapter{}
+		shards[i] = adapters[i]
+	}
+
+	return New("user_id", shards, modulo), adapters
+}
+
+func assertExpectations(t *testing.T, adapters []*testAdapter) {
+	for i := range adapters {
+		adapters[i].AssertExpectations(t)
+	}
+}
+
+func TestHash(t *testing.T) {
+	for _, key := range []interface{}{1, 2, "a", "b", 100} {
+		var (
+			index = Hash(key, 3)
+		)
+
+		assert.True(t, index >= 0 && index < 3)
+		assert.Equal(t, index, Hash(key, 3))
+	}
+
+	assert.Equal(t, Hash(1, 4), Hash(int64(1), 4))
+}
+
+func TestNew(t *testing.T) {
+	var (
+		shards  = []rel.Adapter{&testAdapter{}}
+		adapter = New("user_id", shards, nil)
+	)
+
+	assert.Equal(t, shards, adapter.Shards())
+	assert.NotNil(t, adapter.fn)
+}
+
+func TestNew_noShards(t *testing.T) {
+	assert.Panics(t, func() {
+		New("user_id", nil, nil)
+	})
+}
+
+func TestAdapter_Instrumentation(t *testing.T) {
+	var (
+		adapter, adapters = createAdapter(2)
+	)
+
+	adapters[0].On("Instrumentation").Once()
+	adapters[1].On("Instrumentation").Once()
+
+	adapter.Instrumentation(rel.DefaultLogger)
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Ping(t *testing.T) {
+	var (
+		err               = errors.New("error")
+		adapter, adapters = createAdapter(2)
+	)
+
+	adapters[0].On("Ping").Return(nil).Once()
+	adapters[1].On("Ping").Return(err).Once()
+
+	assert.Equal(t, err, adapter.Ping(context.TODO()))
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Apply(t *testing.T) {
+	var (
+		migration         = rel.Table{Name: "posts"}
+		adapter, adapters = createAdapter(2)
+	)
+
+	adapters[0].On("Apply", migration).Return(nil).Once()
+	adapters[1].On("Apply", migration).Return(nil).Once()
+
+	assert.Nil(t, adapter.Apply(context.TODO(), migration))
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Aggregate(t *testing.T) {
+	var (
+		adapter, adapters = createAdapter(3)
+		query             = rel.From("posts").Where(where.Eq("user_id", 4))
+	)
+
+	adapters[1].On("Aggregate", query, "count", "*").Return(2, nil).Once()
+
+	count, err := adapter.Aggregate(context.TODO(), query, "count", "*")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Aggregate_fanOut(t *testing.T) {
+	var (
+		query = rel.From("posts")
+	)
+
+	tests := []struct {
+		mode   string
+		result int
+		mock   func(adapters []*testAdapter)
+	}{
+		{
+			mode:   "count",
+			result: 5,
+			mock: func(adapters []*testAdapter) {
+				adapters[0].On("Aggregate", query, "count", "id").Return(2, nil).Once()
+				adapters[1].On("Aggregate", query, "count", "id").Return(3, nil).Once()
+			},
+		},
+		{
+			mode:   "sum",
+			result: 30,
+			mock: func(adapters []*testAdapter) {
+				adapters[0].On("Aggregate", query, "sum", "id").Return(10, nil).Once()
+				adapters[1].On("Aggregate", query, "sum", "id").Return(20, nil).Once()
+			},
+		},
+		{
+			mode:   "max",
+			result: 20,
+			mock: func(adapters []*testAdapter) {
+				adapters[0].On("Aggregate", query, "count", "id").Return(2, nil).Once()
+				adapters[0].On("Aggregate", query, "max", "id").Return(10, nil).Once()
+				adapters[1].On("Aggregate", query, "count", "id").Return(3, nil).Once()
+				adapters[1].On("Aggregate", query, "max", "id").Return(20, nil).Once()
+			},
+		},
+		{
+			mode:   "min",
+			result: 5,
+			mock: func(adapters []*testAdapter) {
+				adapters[0].On("Aggregate", query, "count", "id").Return(0, nil).Once()
+				adapters[1].On("Aggregate", query, "count", "id").Return(3, nil).Once()
+				adapters[1].On("Aggregate", query, "min", "id").Return(5, nil).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.mode, func(t *testing.T) {
+			var (
+				adapter, adapters = createAdapter(2)
+			)
+
+			test.mock(adapters)
+
+			result, err := adapter.Aggregate(context.TODO(), query, test.mode, "id")
+			assert.Nil(t, err)
+			assert.Equal(t, test.result, result)
+
+			assertExpectations(t, adapters)
+		})
+	}
+}
+
+func TestAdapter_Aggregate_fanOutUnsupported(t *testing.T) {
+	var (
+		adapter, adapters = createAdapter(2)
+	)
+
+	_, err := adapter.Aggregate(context.TODO(), rel.From("posts"), "avg", "id")
+	assert.Equal(t, ErrCrossShardAggregate, err)
+
+	_, err = adapter.Aggregate(context.TODO(), rel.From("posts").Group("user_id"), "count", "id")
+	assert.Equal(t, ErrCrossShardAggregate, err)
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Aggregate_error(t *testing.T) {
+	var (
+		err               = errors.New("error")
+		adapter, adapters = createAdapter(2)
+		query             = rel.From("posts")
+	)
+
+	adapters[0].On("Aggregate", query, "count", "id").Return(0, err).Once()
+
+	_, aggErr := adapter.Aggregate(context.TODO(), query, "count", "id")
+	assert.Equal(t, err, aggErr)
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Query(t *testing.T) {
+	tests := []struct {
+		name  string
+		query rel.Query
+		shard int
+	}{
+		{
+			name:  "eq",
+			query: rel.From("posts").Where(where.Eq("user_id", 2)),
+			shard: 2,
+		},
+		{
+			name:  "qualified",
+			query: rel.From("posts").Where(where.Eq("posts.user_id", 4)),
+			shard: 1,
+		},
+		{
+			name:  "and",
+			query: rel.From("posts").Where(where.Eq("title", "rel"), where.Eq("user_id", 3)),
+			shard: 0,
+		},
+		{
+			name:  "in",
+			query: rel.From("posts").Where(where.In("user_id", 1, 4)),
+			shard: 1,
+		},
+		{
+			name:  "or",
+			query: rel.From("posts").Where(where.Eq("user_id", 2).OrEq("user_id", 5)),
+			shard: 2,
+		},
+		{
+			name:  "contradicting",
+			query: rel.From("posts").Where(where.Eq("user_id", 1), where.Eq("user_id", 2)),
+			shard: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				adapter, adapters = createAdapter(3)
+				cur               = &testCursor{}
+			)
+
+			adapters[test.shard].On("Query", test.query).Return(cur, nil).Once()
+
+			result, err := adapter.Query(context.TODO(), test.query)
+			assert.Nil(t, err)
+			assert.Equal(t, cur, result)
+
+			assertExpectations(t, adapters)
+		})
+	}
+}
+
+func TestAdapter_Query_fanOut(t *testing.T) {
+	var (
+		adapter, adapters = createAdapter(2)
+		query             = rel.From("posts").SortDesc("id").Offset(1).Limit(2)
+		shardQuery        = rel.From("posts").SortDesc("id").Limit(3)
+		cur0              = createCursor([]string{"id", "user_id"}, []interface{}{4, 2}, []interface{}{2, 2})
+		cur1              = createCursor([]string{"id", "user_id"}, []interface{}{5, 1}, []interface{}{3, 1}, []interface{}{1, 1})
+	)
+
+	adapters[0].On("Query", shardQuery).Return(cur0, nil).Once()
+	adapters[1].On("Query", shardQuery).Return(cur1, nil).Once()
+
+	cur, err := adapter.Query(context.TODO(), query)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]interface{}{{4, 2}, {3, 1}}, readAll(t, cur))
+	assert.Nil(t, cur.Close())
+	assert.True(t, cur0.closed)
+	assert.True(t, cur1.closed)
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Query_fanOutGroup(t *testing.T) {
+	var (
+		adapter, adapters = createAdapter(2)
+		query             = rel.From("posts").Select("user_id").Group("user_id")
+	)
+
+	cur, err := adapter.Query(context.TODO(), query)
+	assert.Nil(t, cur)
+	assert.Equal(t, ErrCrossShardAggregate, err)
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Query_fanOutSQL(t *testing.T) {
+	var (
+		adapter, adapters = createAdapter(2)
+		query             = rel.Build("", rel.SQL("SELECT * FROM posts WHERE user_id=? LIMIT 1", 1))
+		cur0              = createCursor([]string{"id"}, []interface{}{2})
+		cur1              = createCursor([]string{"id"}, []interface{}{1})
+	)
+
+	adapters[0].On("Query", query).Return(cur0, nil).Once()
+	adapters[1].On("Query", query).Return(cur1, nil).Once()
+
+	cur, err := adapter.Query(context.TODO(), query)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]interface{}{{2}, {1}}, readAll(t, cur))
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Delete_fanOutSubQuery(t *testing.T) {
+	var (
+		adapter, adapters = createAdapter(2)
+		subQuery          = rel.Select("id").From("users").Where(where.Eq("banned", true))
+	)
+
+	tests := []rel.Query{
+		rel.From("posts").Where(where.Eq("user_id", subQuery)),
+		rel.From("posts").Where(where.In("user_id", subQuery)),
+		rel.From("posts").Where(where.Eq("title", "rel"), where.In("user_id", subQuery)),
+	}
+
+	for _, query := range tests {
+		adapters[0].On("Delete", query).Return(1, nil).Once()
+		adapters[1].On("Delete", query).Return(2, nil).Once()
+
+		deleted, err := adapter.Delete(context.TODO(), query)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, deleted)
+	}
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Query_error(t *testing.T) {
+	var (
+		err               = errors.New("error")
+		adapter, adapters = createAdapter(2)
+		query             = rel.From("posts")
+		cur0              = createCursor([]string{"id"})
+	)
+
+	adapters[0].On("Query", query).Return(cur0, nil).Once()
+	adapters[1].On("Query", query).Return(nil, err).Once()
+
+	_, queryErr := adapter.Query(context.TODO(), query)
+	assert.Equal(t, err, queryErr)
+	assert.True(t, cur0.closed)
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Insert(t *testing.T) {
+	var (
+		adapter, adapters = createAdapter(3)
+		query             = rel.From("posts")
+		mutates           = map[string]rel.Mutate{
+			"user_id": rel.Set("user_id", 5),
+			"title":   rel.Set("title", "rel"),
+		}
+	)
+
+	adapters[2].On("Insert", query, mutates, rel.OnConflict{}).Return(1, nil).Once()
+
+	id, err := adapter.Insert(context.TODO(), query, []string{"id"}, mutates, rel.OnConflict{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, id)
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Insert_missingShardKey(t *testing.T) {
+	var (
+		adapter, adapters = createAdapter(3)
+		mutates           = map[string]rel.Mutate{
+			"title": rel.Set("title", "rel"),
+		}
+	)
+
+	_, err := adapter.Insert(context.TODO(), rel.From("posts"), []string{"id"}, mutates, rel.OnConflict{})
+	assert.Equal(t, ErrMissingShardKey, err)
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_InsertAll(t *testing.T) {
+	var (
+		adapter, adapters = createAdapter(2)
+		query             = rel.From("posts")
+		bulkMutates       = []map[string]rel.Mutate{
+			{"user_id": rel.Set("user_id", 1)},
+			{"user_id": rel.Set("user_id", 2)},
+			{"user_id": rel.Set("user_id", 3)},
+		}
+	)
+
+	adapters[1].On("InsertAll", query, []map[string]rel.Mutate{bulkMutates[0], bulkMutates[2]}, rel.OnConflict{}).Return([]interface{}{10, 11}, nil).Once()
+	adapters[0].On("InsertAll", query, []map[string]rel.Mutate{bulkMutates[1]}, rel.OnConflict{}).Return([]interface{}{20}, nil).Once()
+
+	ids, err := adapter.InsertAll(context.TODO(), query, []string{"id"}, []string{"user_id"}, bulkMutates, rel.OnConflict{})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{10, 20, 11}, ids)
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_InsertAll_conflict(t *testing.T) {
+	var (
+		adapter, adapters = createAdapter(2)
+		query             = rel.From("posts")
+		onConflict        = rel.OnConflictIgnore()
+		bulkMutates       = []map[string]rel.Mutate{
+			{"user_id": rel.Set("user_id", 1)},
+			{"user_id": rel.Set("user_id", 2)},
+			{"user_id": rel.Set("user_id", 3)},
+		}
+	)
+
+	adapters[1].On("InsertAll", query, []map[string]rel.Mutate{bulkMutates[0], bulkMutates[2]}, onConflict).Return([]interface{}{10}, nil).Once()
+	adapters[0].On("InsertAll", query, []map[string]rel.Mutate{bulkMutates[1]}, onConflict).Return([]interface{}{20}, nil).Once()
+
+	ids, err := adapter.InsertAll(context.TODO(), query, []string{"id"}, []string{"user_id"}, bulkMutates, onConflict)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{10, 20}, ids)
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_InsertAll_error(t *testing.T) {
+	var (
+		err               = errors.New("error")
+		adapter, adapters = createAdapter(2)
+		query             = rel.From("posts")
+		bulkMutates       = []map[string]rel.Mutate{
+			{"user_id": rel.Set("user_id", 1)},
+		}
+	)
+
+	adapters[1].On("InsertAll", query, bulkMutates, rel.OnConflict{}).Return([]interface{}{}, err).Once()
+
+	_, insertErr := adapter.InsertAll(context.TODO(), query, []string{"id"}, []string{"user_id"}, bulkMutates, rel.OnConflict{})
+	assert.Equal(t, err, insertErr)
+
+	_, insertErr = adapter.InsertAll(context.TODO(), query, []string{"id"}, []string{"title"}, []map[string]rel.Mutate{{}}, rel.OnConflict{})
+	assert.Equal(t, ErrMissingShardKey, insertErr)
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Update(t *testing.T) {
+	var (
+		adapter, adapters = createAdapter(2)
+		query             = rel.From("posts").Where(where.Eq("id", 1).AndEq("user_id", 3))
+		mutates           = map[string]rel.Mutate{
+			"title": rel.Set("title", "rel"),
+		}
+	)
+
+	adapters[1].On("Update", query, mutates).Return(1, nil).Once()
+
+	updated, err := adapter.Update(context.TODO(), query, mutates)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, updated)
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Update_shardKey(t *testing.T) {
+	var (
+		adapter, adapters = createAdapter(2)
+		query             = rel.From("posts").Where(where.Eq("id", 1).AndEq("user_id", 1))
+		mutates           = map[string]rel.Mutate{
+			"user_id": rel.Set("user_id", 3),
+			"title":   rel.Set("title", "rel"),
+		}
+	)
+
+	// stays on the same shard.
+	adapters[1].On("Update", query, mutates).Return(1, nil).Once()
+
+	updated, err := adapter.Update(context.TODO(), query, mutates)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, updated)
+
+	// moved to other shard.
+	_, err = adapter.Update(context.TODO(), rel.From("posts").Where(where.Eq("user_id", 2)), mutates)
+	assert.Equal(t, ErrShardKeyMutation, err)
+
+	// unknown shard.
+	_, err = adapter.Update(context.TODO(), rel.From("posts").Where(where.Eq("id", 1)), mutates)
+	assert.Equal(t, ErrShardKeyMutation, err)
+
+	_, err = adapter.Update(context.TODO(), query, map[string]rel.Mutate{"user_id": rel.Inc("user_id")})
+	assert.Equal(t, ErrShardKeyMutation, err)
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Update_fanOut(t *testing.T) {
+	var (
+		err               = errors.New("error")
+		adapter, adapters = createAdapter(2)
+		query             = rel.From("posts")
+		mutates           = map[string]rel.Mutate{
+			"title": rel.Set("title", "rel"),
+		}
+	)
+
+	adapters[0].On("Update", query, mutates).Return(2, nil).Once()
+	adapters[1].On("Update", query, mutates).Return(3, nil).Once()
+
+	updated, updateErr := adapter.Update(context.TODO(), query, mutates)
+	assert.Nil(t, updateErr)
+	assert.Equal(t, 5, updated)
+
+	adapters[0].On("Update", query, mutates).Return(0, err).Once()
+
+	_, updateErr = adapter.Update(context.TODO(), query, mutates)
+	assert.Equal(t, err, updateErr)
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Delete(t *testing.T) {
+	var (
+		adapter, adapters = createAdapter(2)
+		query             = rel.From("posts").Where(where.Eq("user_id", 2))
+	)
+
+	adapters[0].On("Delete", query).Return(1, nil).Once()
+
+	deleted, err := adapter.Delete(context.TODO(), query)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, deleted)
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Delete_fanOut(t *testing.T) {
+	var (
+		err               = errors.New("error")
+		adapter, adapters = createAdapter(2)
+		query             = rel.From("posts").Where(where.Eq("id", 1))
+	)
+
+	adapters[0].On("Delete", query).Return(0, nil).Once()
+	adapters[1].On("Delete", query).Return(1, nil).Once()
+
+	deleted, deleteErr := adapter.Delete(context.TODO(), query)
+	assert.Nil(t, deleteErr)
+	assert.Equal(t, 1, deleted)
+
+	adapters[0].On("Delete", query).Return(0, err).Once()
+
+	_, deleteErr = adapter.Delete(context.TODO(), query)
+	assert.Equal(t, err, deleteErr)
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Transaction(t *testing.T) {
+	var (
+		ctx               = context.TODO()
+		adapter, adapters = createAdapter(2)
+		query             = rel.From("posts").Where(where.Eq("user_id", 1))
+		mutates           = map[string]rel.Mutate{"user_id": rel.Set("user_id", 3)}
+	)
+
+	adapters[1].On("Begin").Return(nil).Once()
+	adapters[1].On("Update", query, mutates).Return(1, nil).Once()
+	adapters[1].On("Delete", query).Return(1, nil).Once()
+	adapters[1].On("Commit").Return(nil).Once()
+
+	tx, err := adapter.Begin(ctx)
+	assert.Nil(t, err)
+
+	_, err = tx.Update(ctx, query, mutates)
+	assert.Nil(t, err)
+
+	_, err = tx.Delete(ctx, query)
+	assert.Nil(t, err)
+
+	assert.Nil(t, tx.Commit(ctx))
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Transaction_crossShard(t *testing.T) {
+	var (
+		ctx               = context.TODO()
+		adapter, adapters = createAdapter(2)
+		query             = rel.From("posts")
+	)
+
+	adapters[1].On("Begin").Return(nil).Once()
+	adapters[1].On("Insert", query, mock.Anything, rel.OnConflict{}).Return(1, nil).Once()
+	adapters[1].On("Rollback").Return(nil).Once()
+
+	tx, err := adapter.Begin(ctx)
+	assert.Nil(t, err)
+
+	_, err = tx.Insert(ctx, query, []string{"id"}, map[string]rel.Mutate{"user_id": rel.Set("user_id", 1)}, rel.OnConflict{})
+	assert.Nil(t, err)
+
+	_, err = tx.Insert(ctx, query, []string{"id"}, map[string]rel.Mutate{"user_id": rel.Set("user_id", 2)}, rel.OnConflict{})
+	assert.Equal(t, ErrCrossShard, err)
+
+	_, err = tx.Query(ctx, query)
+	assert.Equal(t, ErrCrossShard, err)
+
+	_, err = tx.InsertAll(ctx, query, []string{"id"}, []string{"user_id"}, []map[string]rel.Mutate{
+		{"user_id": rel.Set("user_id", 1)},
+		{"user_id": rel.Set("user_id", 2)},
+	}, rel.OnConflict{})
+	assert.Equal(t, ErrCrossShard, err)
+
+	assert.Nil(t, tx.Rollback(ctx))
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Transaction_nested(t *testing.T) {
+	var (
+		ctx               = context.TODO()
+		adapter, adapters = createAdapter(2)
+		query             = rel.From("posts").Where(where.Eq("user_id", 2))
+	)
+
+	adapters[0].On("Begin").Return(nil).Twice()
+	adapters[0].On("Delete", query).Return(1, nil).Once()
+	adapters[0].On("Commit").Return(nil).Twice()
+
+	tx, err := adapter.Begin(ctx)
+	assert.Nil(t, err)
+
+	nested, err := tx.Begin(ctx)
+	assert.Nil(t, err)
+
+	_, err = nested.Delete(ctx, query)
+	assert.Nil(t, err)
+
+	_, err = nested.Delete(ctx, rel.From("posts").Where(where.Eq("user_id", 1)))
+	assert.Equal(t, ErrCrossShard, err)
+
+	assert.Nil(t, nested.Commit(ctx))
+	assert.Nil(t, tx.Commit(ctx))
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Transaction_empty(t *testing.T) {
+	var (
+		ctx               = context.TODO()
+		adapter, adapters = createAdapter(2)
+	)
+
+	tx, err := adapter.Begin(ctx)
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Commit(ctx))
+	assert.Nil(t, tx.Rollback(ctx))
+
+	assertExpectations(t, adapters)
+}
+
+func TestAdapter_Transaction_beginError(t *testing.T) {
+	var (
+		err               = errors.New("error")
+		ctx               = context.TODO()
+		adapter, adapters = createAdapter(2)
+		query             = rel.From("posts").Where(where.Eq("user_id", 2))
+	)
+
+	adapters[0].On("Begin").Return(err).Once()
+
+	tx, _ := adapter.Begin(ctx)
+	_, deleteErr := tx.Delete(ctx, query)
+	assert.Equal(t, err, deleteErr)
+
+	assertExpectations(t, adapters)
+}
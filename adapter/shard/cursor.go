@@ -0,0 +1,338 @@
+package shard
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-rel/rel"
+)
+
+// cursor merges results of multiple shards.
+// Rows are merged by the sort of the query, otherwise results of each shard are concatenated.
+// Child cursor must allow the current row to be scanned more than once, because the sort keys
+// are scanned before the row is returned.
+type cursor struct {
+	cursors []rel.Cursor
+	sorts   []rel.SortQuery
+	fields  []string
+	columns []int
+	keys    [][]interface{}
+	valid   []bool
+	current int
+	started bool
+	offset  int
+	limit   int
+	count   int
+	err     error
+}
+
+func (c *cursor) Close() error {
+	var (
+		err error
+	)
+
+	for i := range c.cursors {
+		if e := c.cursors[i].Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	return err
+}
+
+func (c *cursor) Fields() ([]string, error) {
+	if c.fields != nil {
+		return c.fields, nil
+	}
+
+	fields, err := c.cursors[0].Fields()
+	if err != nil {
+		return nil, err
+	}
+
+	c.columns = make([]int, len(c.sorts))
+	for i := range c.sorts {
+		c.columns[i] = -1
+		for j := range fields {
+			if sortField(c.sorts[i].Field) == fields[j] {
+				c.columns[i] = j
+				break
+			}
+		}
+
+		if c.columns[i] < 0 {
+			return nil, fmt.Errorf("rel: sort field %s must be selected to merge shard results", c.sorts[i].Field)
+		}
+	}
+
+	c.fields = fields
+	return fields, nil
+}
+
+func (c *cursor) Next() bool {
+	if c.err != nil {
+		return false
+	}
+
+	if !c.started {
+		c.started = true
+		if _, c.err = c.Fields(); c.err != nil {
+			// let the next scan return the error.
+			return true
+		}
+
+		for i := range c.cursors {
+			c.advance(i)
+		}
+	} else if c.current >= 0 {
+		c.advance(c.current)
+	}
+
+	for {
+		if c.err != nil {
+			return true
+		}
+
+		if c.current = c.pick(); c.current < 0 {
+			return false
+		}
+
+		if c.offset == 0 {
+			break
+		}
+
+		c.offset--
+		c.advance(c.current)
+	}
+
+	if c.limit > 0 && c.count >= c.limit {
+		return false
+	}
+
+	c.count++
+	return true
+}
+
+func (c *cursor) Scan(dest ...interface{}) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	return c.cursors[c.current].Scan(dest...)
+}
+
+func (c *cursor) NopScanner() interface{} {
+	return c.cursors[0].NopScanner()
+}
+
+// advance moves child cursor to the next row and reads its sort keys.
+func (c *cursor) advance(i int) {
+	if c.valid[i] = c.cursors[i].Next(); !c.valid[i] || len(c.sorts) == 0 {
+		return
+	}
+
+	var (
+		discard interface{}
+		dest    = make([]interface{}, len(c.fields))
+	)
+
+	// nop scanner of the child is not used, since it may prevent the row to be scanned again.
+	for j := range dest {
+		dest[j] = &discard
+	}
+
+	for k, column := range c.columns {
+		dest[column] = &c.keys[i][k]
+	}
+
+	if err := c.cursors[i].Scan(dest...); err != nil {
+		c.valid[i] = false
+		c.err = err
+	}
+}
+
+// pick returns index of the child cursor that holds the next row, or -1 when all of them are exhausted.
+func (c *cursor) pick() int {
+	var (
+		picked = -1
+	)
+
+	for i := range c.cursors {
+		if !c.valid[i] {
+			continue
+		}
+
+		if len(c.sorts) == 0 {
+			return i
+		}
+
+		if picked < 0 || c.less(i, picked) {
+			picked = i
+		}
+	}
+
+	return picked
+}
+
+func (c *cursor) less(i int, j int) bool {
+	for k := range c.sorts {
+		result := compare(c.keys[i][k], c.keys[j][k])
+		if result == 0 {
+			continue
+		}
+
+		if c.sorts[k].Asc() {
+			return result < 0
+		}
+
+		return result > 0
+	}
+
+	return false
+}
+
+func newCursor(cursors []rel.Cursor, sorts []rel.SortQuery, offset int, limit int) *cursor {
+	var (
+		keys = make([][]interface{}, len(cursors))
+	)
+
+	for i := range keys {
+		keys[i] = make([]interface{}, len(sorts))
+	}
+
+	return &cursor{
+		cursors: cursors,
+		sorts:   sorts,
+		keys:    keys,
+		valid:   make([]bool, len(cursors)),
+		current: -1,
+		offset:  offset,
+		limit:   limit,
+	}
+}
+
+func sortField(field string) string {
+	if i := strings.LastIndexByte(field, '.'); i >= 0 {
+		return field[i+1:]
+	}
+
+	return field
+}
+
+// compare returns -1, 0 or 1 when a is less than, equal or greater than b.
+// Nil is considered less than any other value.
+func compare(a interface{}, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	switch av := a.(type) {
+	case []byte:
+		if bv, ok := b.([]byte); ok {
+			return compareBytes(av, bv)
+		}
+	case string:
+		if bv, ok := b.(string); ok {
+			return strings.Compare(av, bv)
+		}
+	case time.Time:
+		if bv, ok := b.(time.Time); ok {
+			return sign(av.After(bv), av.Before(bv))
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			return sign(av && !bv, !av && bv)
+		}
+	}
+
+	var (
+		ra = reflect.ValueOf(a)
+		rb = reflect.ValueOf(b)
+	)
+
+	switch {
+	case isInt(ra) && isInt(rb):
+		return sign(ra.Int() > rb.Int(), ra.Int() < rb.Int())
+	case isUint(ra) && isUint(rb):
+		return sign(ra.Uint() > rb.Uint(), ra.Uint() < rb.Uint())
+	case isNumber(ra) && isNumber(rb):
+		return sign(toFloat(ra) > toFloat(rb), toFloat(ra) < toFloat(rb))
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// compareBytes compares numeric value returned as bytes by the driver (eg: mysql) numerically, and other value by its bytes.
+func compareBytes(a []byte, b []byte) int {
+	if ai, err := strconv.ParseInt(string(a), 10, 64); err == nil {
+		if bi, err := strconv.ParseInt(string(b), 10, 64); err == nil {
+			return sign(ai > bi, ai < bi)
+		}
+	}
+
+	if af, err := strconv.ParseFloat(string(a), 64); err == nil {
+		if bf, err := strconv.ParseFloat(string(b), 64); err == nil {
+			return sign(af > bf, af < bf)
+		}
+	}
+
+	return bytes.Compare(a, b)
+}
+
+func sign(greater bool, less bool) int {
+	switch {
+	case greater:
+		return 1
+	case less:
+		return -1
+	}
+
+	return 0
+}
+
+func isInt(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+
+	return false
+}
+
+func isUint(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
+
+func isNumber(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return isInt(rv) || isUint(rv)
+}
+
+func toFloat(rv reflect.Value) float64 {
+	switch {
+	case isInt(rv):
+		return float64(rv.Int())
+	case isUint(rv):
+		return float64(rv.Uint())
+	}
+
+	return rv.Float()
+}
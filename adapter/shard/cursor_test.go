@@ -0,0 +1,224 @@
+package shard
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-rel/rel"
+	"github.com/stretchr/testify/assert"
+)
+
+// testCursor iterates rows in memory, the current row can be scanned more than once.
+type testCursor struct {
+	fields []string
+	rows   [][]interface{}
+	index  int
+	err    error
+	closed bool
+}
+
+var _ rel.Cursor = (*testCursor)(nil)
+
+func (tc *testCursor) Close() error {
+	tc.closed = true
+	return nil
+}
+
+func (tc *testCursor) Fields() ([]string, error) {
+	return tc.fields, nil
+}
+
+func (tc *testCursor) Next() bool {
+	tc.index++
+	return tc.index <= len(tc.rows)
+}
+
+func (tc *testCursor) NopScanner() interface{} {
+	return &sql.RawBytes{}
+}
+
+func (tc *testCursor) Scan(dest ...interface{}) error {
+	if tc.err != nil {
+		return tc.err
+	}
+
+	for i, value := range tc.rows[tc.index-1] {
+		if _, ok := dest[i].(*sql.RawBytes); ok {
+			continue
+		}
+
+		rv := reflect.ValueOf(dest[i]).Elem()
+		if value == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+		} else {
+			rv.Set(reflect.ValueOf(value))
+		}
+	}
+
+	return nil
+}
+
+func createCursor(fields []string, rows ...[]interface{}) *testCursor {
+	return &testCursor{
+		fields: fields,
+		rows:   rows,
+	}
+}
+
+func readAll(t *testing.T, cur rel.Cursor) [][]interface{} {
+	var (
+		rows [][]interface{}
+	)
+
+	fields, err := cur.Fields()
+	assert.Nil(t, err)
+
+	for cur.Next() {
+		var (
+			row  = make([]interface{}, len(fields))
+			dest = make([]interface{}, len(fields))
+		)
+
+		for i := range dest {
+			dest[i] = &row[i]
+		}
+
+		assert.Nil(t, cur.Scan(dest...))
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+func TestCursor(t *testing.T) {
+	var (
+		fields = []string{"id", "name"}
+	)
+
+	tests := []struct {
+		name   string
+		sorts  []rel.SortQuery
+		offset int
+		limit  int
+		result [][]interface{}
+	}{
+		{
+			name:   "concat",
+			result: [][]interface{}{{1, "c"}, {3, "a"}, {2, "b"}, {4, nil}},
+		},
+		{
+			name:   "sort asc",
+			sorts:  []rel.SortQuery{rel.NewSortAsc("id")},
+			result: [][]interface{}{{1, "c"}, {2, "b"}, {3, "a"}, {4, nil}},
+		},
+		{
+			name:   "sort desc",
+			sorts:  []rel.SortQuery{rel.NewSortDesc("name")},
+			result: [][]interface{}{{1, "c"}, {2, "b"}, {3, "a"}, {4, nil}},
+		},
+		{
+			name:   "qualified sort",
+			sorts:  []rel.SortQuery{rel.NewSortDesc("posts.name")},
+			result: [][]interface{}{{1, "c"}, {2, "b"}, {3, "a"}, {4, nil}},
+		},
+		{
+			name:   "offset and limit",
+			sorts:  []rel.SortQuery{rel.NewSortAsc("id")},
+			offset: 1,
+			limit:  2,
+			result: [][]interface{}{{2, "b"}, {3, "a"}},
+		},
+		{
+			name:   "offset exceed",
+			sorts:  []rel.SortQuery{rel.NewSortAsc("id")},
+			offset: 5,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				cur = newCursor([]rel.Cursor{
+					createCursor(fields, []interface{}{1, "c"}, []interface{}{3, "a"}),
+					createCursor(fields),
+					createCursor(fields, []interface{}{2, "b"}, []interface{}{4, nil}),
+				}, test.sorts, test.offset, test.limit)
+			)
+
+			assert.Equal(t, test.result, readAll(t, cur))
+			assert.Equal(t, &sql.RawBytes{}, cur.NopScanner())
+		})
+	}
+}
+
+func TestCursor_sortNotSelected(t *testing.T) {
+	var (
+		cur = newCursor([]rel.Cursor{
+			createCursor([]string{"id"}, []interface{}{1}),
+			createCursor([]string{"id"}, []interface{}{2}),
+		}, []rel.SortQuery{rel.NewSortAsc("name")}, 0, 0)
+	)
+
+	_, err := cur.Fields()
+	assert.EqualError(t, err, "rel: sort field name must be selected to merge shard results")
+
+	assert.True(t, cur.Next())
+	assert.EqualError(t, cur.Scan(), "rel: sort field name must be selected to merge shard results")
+	assert.False(t, cur.Next())
+}
+
+func TestCursor_scanError(t *testing.T) {
+	var (
+		err  = errors.New("error")
+		cur0 = createCursor([]string{"id"}, []interface{}{1})
+		cur  = newCursor([]rel.Cursor{cur0}, []rel.SortQuery{rel.NewSortAsc("id")}, 0, 0)
+	)
+
+	cur0.err = err
+
+	_, fieldsErr := cur.Fields()
+	assert.Nil(t, fieldsErr)
+	assert.True(t, cur.Next())
+	assert.Equal(t, err, cur.Scan())
+	assert.False(t, cur.Next())
+}
+
+func TestCompare(t *testing.T) {
+	var (
+		now = time.Now()
+	)
+
+	tests := []struct {
+		a, b   interface{}
+		result int
+	}{
+		{nil, nil, 0},
+		{nil, 1, -1},
+		{1, nil, 1},
+		{1, 2, -1},
+		{int64(2), 1, 1},
+		{uint(1), uint8(1), 0},
+		{1.5, 1, 1},
+		{float32(1), uint(2), -1},
+		{"a", "b", -1},
+		{[]byte("b"), []byte("a"), 1},
+		{[]byte("9"), []byte("10"), -1},
+		{[]byte("-2"), []byte("1"), -1},
+		{[]byte("10.5"), []byte("9.25"), 1},
+		{[]byte("10"), []byte("10.0"), 0},
+		{[]byte("10"), []byte("9a"), -1},
+		{now, now.Add(time.Second), -1},
+		{now, now, 0},
+		{true, false, 1},
+		{false, true, -1},
+		{true, true, 0},
+		{"1", 2, -1},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.result, compare(test.a, test.b), "compare(%v, %v)", test.a, test.b)
+	}
+}
@@ -259,3 +259,57 @@ func assertRecords(t *testing.T, repo rel.Repository, records interface{}) {
 		assert.Equal(t, found, *v)
 	}
 }
+
+// InsertOnConflictIgnore tests insert specification with on conflict ignore.
+func InsertOnConflictIgnore(t *testing.T, repo rel.Repository) {
+	var (
+		extra    = createExtra(repo, "on-conflict-ignore")
+		conflict = Extra{Slug: extra.Slug, Score: 10, UserID: extra.UserID}
+	)
+
+	t.Run("InsertOnConflictIgnore", func(t *testing.T) {
+		err := repo.Insert(ctx, &conflict, rel.OnConflictKeyIgnore("slug"))
+		assert.Nil(t, err)
+		assert.Equal(t, extra, conflict)
+	})
+
+	t.Run("InsertOnConflictIgnore|any", func(t *testing.T) {
+		var (
+			slug     = *extra.Slug + "-any"
+			inserted = Extra{Slug: &slug, UserID: extra.UserID}
+			queried  Extra
+		)
+
+		assert.Nil(t, repo.Insert(ctx, &inserted, rel.OnConflictIgnore()))
+		assert.NotZero(t, inserted.ID)
+
+		repo.MustFind(ctx, &queried, where.Eq("id", inserted.ID))
+		assert.Equal(t, inserted, queried)
+
+		conflict := Extra{Slug: inserted.Slug, UserID: extra.UserID}
+		assert.Equal(t, rel.ErrNotInserted, repo.Insert(ctx, &conflict, rel.OnConflictIgnore()))
+		assert.Zero(t, conflict.ID)
+	})
+}
+
+// InsertOnConflictReplace tests insert specification with on conflict replace.
+func InsertOnConflictReplace(t *testing.T, repo rel.Repository) {
+	var (
+		extra    = createExtra(repo, "on-conflict-replace")
+		conflict = Extra{Slug: extra.Slug, Score: 10, UserID: extra.UserID}
+	)
+
+	t.Run("InsertOnConflictReplace", func(t *testing.T) {
+		err := repo.Insert(ctx, &conflict, rel.OnConflictKeyReplace("slug"))
+		assert.Nil(t, err)
+		assert.Equal(t, extra.ID, conflict.ID)
+		assert.Equal(t, 10, conflict.Score)
+
+		var (
+			queried Extra
+		)
+
+		repo.MustFind(ctx, &queried, where.Eq("id", extra.ID))
+		assert.Equal(t, conflict, queried)
+	})
+}
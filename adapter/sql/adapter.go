@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"errors"
 	"strconv"
+	"sync"
 
 	"github.com/go-rel/rel"
 )
@@ -17,12 +18,27 @@ type Adapter struct {
 	DB           *sql.DB
 	Tx           *sql.Tx
 	savepoint    int
+	statements   *statementCache
+	txStatements *statementCache
 }
 
-var _ rel.Adapter = (*Adapter)(nil)
+var (
+	_ rel.ReturningAdapter = (*Adapter)(nil)
+
+	// statementsMutex guards initialization of statement cache, adapter can't hold the mutex because it's copied by value.
+	statementsMutex sync.RWMutex
+)
 
 // Close database connection.
 func (a *Adapter) Close() error {
+	statementsMutex.RLock()
+	statements := a.statements
+	statementsMutex.RUnlock()
+
+	if statements != nil {
+		statements.reset()
+	}
+
 	return a.DB.Close()
 }
 
@@ -39,20 +55,24 @@ func (a *Adapter) Ping(ctx context.Context) error {
 // Aggregate record using given query.
 func (a *Adapter) Aggregate(ctx context.Context, query rel.Query, mode string, field string) (int, error) {
 	var (
-		err             error
 		out             sql.NullInt64
 		statement, args = NewBuilder(a.Config).Aggregate(query, mode, field)
 	)
 
 	finish := a.Instrumenter.Observe(ctx, "adapter-aggregate", statement)
-	if a.Tx != nil {
-		err = a.Tx.QueryRowContext(ctx, statement, args...).Scan(&out)
-	} else {
-		err = a.DB.QueryRowContext(ctx, statement, args...).Scan(&out)
+	rows, err := a.query(ctx, statement, args)
+	if err == nil {
+		defer rows.Close()
+
+		if rows.Next() {
+			err = rows.Scan(&out)
+		} else if err = rows.Err(); err == nil {
+			err = sql.ErrNoRows
+		}
 	}
 	finish(err)
 
-	return int(out.Int64), err
+	return int(out.Int64), a.Config.ErrorFunc(err)
 }
 
 // Query performs query operation.
@@ -61,14 +81,34 @@ func (a *Adapter) Query(ctx context.Context, query rel.Query) (rel.Cursor, error
 		statement, args = NewBuilder(a.Config).Find(query)
 	)
 
+	return a.queryCursor(ctx, statement, args)
+}
+
+func (a *Adapter) queryCursor(ctx context.Context, statement string, args []interface{}) (rel.Cursor, error) {
+	rows, err := a.QueryRows(ctx, statement, args)
+	return &Cursor{rows}, err
+}
+
+// QueryRows performs raw query and returns its rows, the statement is prepared and cached when statement cache is enabled.
+func (a *Adapter) QueryRows(ctx context.Context, statement string, args []interface{}) (*sql.Rows, error) {
 	finish := a.Instrumenter.Observe(ctx, "adapter-query", statement)
 	rows, err := a.query(ctx, statement, args)
 	finish(err)
 
-	return &Cursor{rows}, a.Config.ErrorFunc(err)
+	return rows, a.Config.ErrorFunc(err)
 }
 
 func (a *Adapter) query(ctx context.Context, statement string, args []interface{}) (*sql.Rows, error) {
+	if cache := a.cache(); cache != nil {
+		stmt, release, err := cache.get(ctx, a.Instrumenter, statement)
+		if err != nil {
+			return nil, err
+		}
+
+		defer release()
+		return stmt.QueryContext(ctx, args...)
+	}
+
 	if a.Tx != nil {
 		return a.Tx.QueryContext(ctx, statement, args...)
 	}
@@ -77,9 +117,14 @@ func (a *Adapter) query(ctx context.Context, statement string, args []interface{
 }
 
 // Exec performs exec operation.
+// The statement is never prepared, so it may contain multiple statements.
 func (a *Adapter) Exec(ctx context.Context, statement string, args []interface{}) (int64, int64, error) {
+	return a.execute(ctx, statement, args, false)
+}
+
+func (a *Adapter) execute(ctx context.Context, statement string, args []interface{}, prepare bool) (int64, int64, error) {
 	finish := a.Instrumenter.Observe(ctx, "adapter-exec", statement)
-	res, err := a.exec(ctx, statement, args)
+	res, err := a.exec(ctx, statement, args, prepare)
 	finish(err)
 
 	if err != nil {
@@ -92,7 +137,17 @@ func (a *Adapter) Exec(ctx context.Context, statement string, args []interface{}
 	return lastID, rowCount, nil
 }
 
-func (a *Adapter) exec(ctx context.Context, statement string, args []interface{}) (sql.Result, error) {
+func (a *Adapter) exec(ctx context.Context, statement string, args []interface{}, prepare bool) (sql.Result, error) {
+	if cache := a.cache(); prepare && cache != nil {
+		stmt, release, err := cache.get(ctx, a.Instrumenter, statement)
+		if err != nil {
+			return nil, err
+		}
+
+		defer release()
+		return stmt.ExecContext(ctx, args...)
+	}
+
 	if a.Tx != nil {
 		return a.Tx.ExecContext(ctx, statement, args...)
 	}
@@ -100,24 +155,92 @@ func (a *Adapter) exec(ctx context.Context, statement string, args []interface{}
 	return a.DB.ExecContext(ctx, statement, args...)
 }
 
+// cache returns statement cache of the current database or transaction, nil when it's disabled.
+func (a *Adapter) cache() *statementCache {
+	if a.Tx != nil {
+		return a.txStatements
+	}
+
+	return a.statementCache()
+}
+
+// statementCache returns statement cache of the database, it's created on first use.
+// transaction adapter shares the cache of its database.
+func (a *Adapter) statementCache() *statementCache {
+	statementsMutex.RLock()
+	statements := a.statements
+	statementsMutex.RUnlock()
+
+	if statements != nil || a.Config.StatementCacheSize <= 0 || a.DB == nil {
+		return statements
+	}
+
+	statementsMutex.Lock()
+	defer statementsMutex.Unlock()
+
+	// keeps the cache that is created first when it's initialized concurrently.
+	if a.statements == nil {
+		a.statements = newStatementCache(a.Config.StatementCacheSize, a.DB)
+	}
+
+	return a.statements
+}
+
+// resetStatements evicts cached statements, prepared statements may be invalid after schema is changed.
+func (a *Adapter) resetStatements() {
+	if a.txStatements != nil {
+		a.txStatements.reset()
+	}
+
+	if statements := a.statementCache(); statements != nil {
+		statements.reset()
+	}
+}
+
 // Insert inserts a record to database and returns its id.
-func (a *Adapter) Insert(ctx context.Context, query rel.Query, primaryField string, mutates map[string]rel.Mutate) (interface{}, error) {
+// When composite primary is used, or the record is ignored or updated due to conflict, returned id will be nil because last insert id is not reliable.
+func (a *Adapter) Insert(ctx context.Context, query rel.Query, primaryFields []string, mutates map[string]rel.Mutate, onConflict rel.OnConflict) (interface{}, error) {
 	var (
-		statement, args = NewBuilder(a.Config).Insert(query.Table, mutates)
-		id, _, err      = a.Exec(ctx, statement, args)
+		statement, args       = NewBuilder(a.Config).Insert(query.Table, mutates, onConflict)
+		id, rowsAffected, err = a.execute(ctx, statement, args, true)
 	)
 
-	return id, err
+	if err != nil || len(primaryFields) != 1 || !a.inserted(onConflict, primaryFields[0], mutates, rowsAffected) {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+// inserted returns true when the record is inserted instead of being ignored or updated due to conflict.
+func (a *Adapter) inserted(onConflict rel.OnConflict, primaryField string, mutates map[string]rel.Mutate, rowsAffected int64) bool {
+	switch {
+	case onConflict.None():
+		return true
+	case onConflict.Ignore, a.Config.OnDuplicateKeyUpdate:
+		// ignored record affects no row, and updated record using on duplicate key update affects two rows.
+		return rowsAffected == 1
+	default:
+		// conflict can't happen on primary key generated by database, otherwise updated and inserted record affect one row.
+		_, set := mutates[primaryField]
+		return !set && len(onConflict.Keys) == 1 && onConflict.Keys[0] == primaryField
+	}
 }
 
 // InsertAll inserts all record to database and returns its ids.
-func (a *Adapter) InsertAll(ctx context.Context, query rel.Query, primaryField string, fields []string, bulkMutates []map[string]rel.Mutate) ([]interface{}, error) {
-	statement, args := NewBuilder(a.Config).InsertAll(query.Table, fields, bulkMutates)
-	id, _, err := a.Exec(ctx, statement, args)
-	if err != nil {
+// When composite primary is used, or some records are ignored or updated due to conflict, returned ids will be nil because last insert id is not reliable.
+func (a *Adapter) InsertAll(ctx context.Context, query rel.Query, primaryFields []string, fields []string, bulkMutates []map[string]rel.Mutate, onConflict rel.OnConflict) ([]interface{}, error) {
+	statement, args := NewBuilder(a.Config).InsertAll(query.Table, fields, bulkMutates, onConflict)
+	id, rowsAffected, err := a.execute(ctx, statement, args, true)
+	if err != nil || len(primaryFields) != 1 {
 		return nil, err
 	}
 
+	// ids can only be calculated when every record is inserted.
+	if !onConflict.None() && (!onConflict.Ignore || rowsAffected != int64(len(bulkMutates))) {
+		return nil, nil
+	}
+
 	var (
 		ids = make([]interface{}, len(bulkMutates))
 		inc = 1
@@ -132,38 +255,63 @@ func (a *Adapter) InsertAll(ctx context.Context, query rel.Query, primaryField s
 		inc *= -1
 	}
 
-	if primaryField != "" {
-		counter := 0
-		for i := range ids {
-			if mut, ok := bulkMutates[i][primaryField]; ok {
-				ids[i] = mut.Value
-				id = toInt64(ids[i])
-				counter = 1
-			} else {
-				ids[i] = id + int64(counter*inc)
-				counter++
-			}
+	var (
+		counter      = 0
+		primaryField = primaryFields[0]
+	)
+
+	for i := range ids {
+		if mut, ok := bulkMutates[i][primaryField]; ok {
+			ids[i] = mut.Value
+			id = toInt64(ids[i])
+			counter = 1
+		} else {
+			ids[i] = id + int64(counter*inc)
+			counter++
 		}
 	}
 
 	return ids, nil
 }
 
+// SupportReturning returns true if the database is able to return inserted and updated row.
+func (a *Adapter) SupportReturning() bool {
+	return a.Config.Returning
+}
+
+// InsertReturning inserts a record to database and returns all columns of inserted row.
+func (a *Adapter) InsertReturning(ctx context.Context, query rel.Query, mutates map[string]rel.Mutate, onConflict rel.OnConflict) (rel.Cursor, error) {
+	var (
+		statement, args = NewBuilder(a.Config).Returning("*").Insert(query.Table, mutates, onConflict)
+	)
+
+	return a.queryCursor(ctx, statement, args)
+}
+
 // Update updates a record in database.
 func (a *Adapter) Update(ctx context.Context, query rel.Query, mutates map[string]rel.Mutate) (int, error) {
 	var (
 		statement, args      = NewBuilder(a.Config).Update(query.Table, mutates, query.WhereQuery)
-		_, updatedCount, err = a.Exec(ctx, statement, args)
+		_, updatedCount, err = a.execute(ctx, statement, args, true)
 	)
 
 	return int(updatedCount), err
 }
 
+// UpdateReturning updates a record in database and returns all columns of updated row.
+func (a *Adapter) UpdateReturning(ctx context.Context, query rel.Query, mutates map[string]rel.Mutate) (rel.Cursor, error) {
+	var (
+		statement, args = NewBuilder(a.Config).Returning("*").Update(query.Table, mutates, query.WhereQuery)
+	)
+
+	return a.queryCursor(ctx, statement, args)
+}
+
 // Delete deletes all results that match the query.
 func (a *Adapter) Delete(ctx context.Context, query rel.Query) (int, error) {
 	var (
 		statement, args      = NewBuilder(a.Config).Delete(query.Table, query.WhereQuery)
-		_, deletedCount, err = a.Exec(ctx, statement, args)
+		_, deletedCount, err = a.execute(ctx, statement, args, true)
 	)
 
 	return int(deletedCount), err
@@ -172,9 +320,11 @@ func (a *Adapter) Delete(ctx context.Context, query rel.Query) (int, error) {
 // Begin begins a new transaction.
 func (a *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
 	var (
-		tx        *sql.Tx
-		savepoint int
-		err       error
+		tx           *sql.Tx
+		savepoint    int
+		statements   = a.statementCache()
+		txStatements = a.txStatements
+		err          error
 	)
 
 	finish := a.Instrumenter.Observe(ctx, "adapter-begin", "begin transaction")
@@ -185,6 +335,9 @@ func (a *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
 		_, _, err = a.Exec(ctx, "SAVEPOINT s"+strconv.Itoa(savepoint)+";", []interface{}{})
 	} else {
 		tx, err = a.DB.BeginTx(ctx, nil)
+		if err == nil && statements != nil {
+			txStatements = newTxStatementCache(statements, tx)
+		}
 	}
 
 	finish(err)
@@ -194,6 +347,8 @@ func (a *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
 		Config:       a.Config,
 		Tx:           tx,
 		savepoint:    savepoint,
+		statements:   statements,
+		txStatements: txStatements,
 	}, err
 }
 
@@ -209,6 +364,7 @@ func (a *Adapter) Commit(ctx context.Context) error {
 		_, _, err = a.Exec(ctx, "RELEASE SAVEPOINT s"+strconv.Itoa(a.savepoint)+";", []interface{}{})
 	} else {
 		err = a.Tx.Commit()
+		a.resetTxStatements()
 	}
 
 	finish(err)
@@ -228,6 +384,7 @@ func (a *Adapter) Rollback(ctx context.Context) error {
 		_, _, err = a.Exec(ctx, "ROLLBACK TO SAVEPOINT s"+strconv.Itoa(a.savepoint)+";", []interface{}{})
 	} else {
 		err = a.Tx.Rollback()
+		a.resetTxStatements()
 	}
 
 	finish(err)
@@ -235,6 +392,13 @@ func (a *Adapter) Rollback(ctx context.Context) error {
 	return a.Config.ErrorFunc(err)
 }
 
+// resetTxStatements releases statements bound to the finished transaction.
+func (a *Adapter) resetTxStatements() {
+	if a.txStatements != nil {
+		a.txStatements.reset()
+	}
+}
+
 // Apply table.
 func (a *Adapter) Apply(ctx context.Context, migration rel.Migration) error {
 	var (
@@ -252,6 +416,8 @@ func (a *Adapter) Apply(ctx context.Context, migration rel.Migration) error {
 	}
 
 	_, _, err := a.Exec(ctx, statement, nil)
+	a.resetStatements()
+
 	return err
 }
 
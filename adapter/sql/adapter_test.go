@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/where"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
 )
@@ -89,6 +90,27 @@ func TestAdapter_Aggregate_transaction(t *testing.T) {
 	})
 }
 
+func TestAdapter_Aggregate_conflict(t *testing.T) {
+	var (
+		adapter = open(t)
+		repo    = rel.New(adapter)
+	)
+
+	defer adapter.Close()
+
+	adapter.Config.ErrorFunc = func(err error) error {
+		if err == nil {
+			return nil
+		}
+
+		return rel.TransactionConflictError{Type: rel.SerializationFailure, Err: err}
+	}
+
+	count, err := repo.Aggregate(context.TODO(), rel.From("conflicts"), "count", "id")
+	assert.Equal(t, 0, count)
+	assert.True(t, errors.Is(err, rel.ErrSerializationFailure))
+}
+
 func TestAdapter_FindAll(t *testing.T) {
 	var (
 		adapter = open(t)
@@ -138,6 +160,67 @@ func TestAdapter_Insert(t *testing.T) {
 	assert.NotEqual(t, 0, name.ID)
 }
 
+func TestAdapter_Insert_onConflict(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = open(t)
+		repo    = rel.New(adapter)
+		name    = Name{Name: "Luffy"}
+	)
+	defer adapter.Close()
+
+	// inserted.
+	assert.Nil(t, repo.Insert(ctx, &name, rel.OnConflictIgnore()))
+	assert.NotEqual(t, 0, name.ID)
+
+	id, err := adapter.Insert(ctx, rel.From("names"), []string{"id"}, map[string]rel.Mutate{
+		"id":   rel.Set("id", name.ID),
+		"name": rel.Set("name", "Zoro"),
+	}, rel.OnConflictIgnore())
+	assert.Nil(t, err)
+	assert.Nil(t, id)
+
+	// inserted using generated primary key.
+	replaced := Name{Name: "Nami"}
+	assert.Nil(t, repo.Insert(ctx, &replaced, rel.OnConflictReplace()))
+	assert.NotEqual(t, 0, replaced.ID)
+
+	// updated.
+	replaced.Name = "Sanji"
+	assert.Nil(t, repo.Insert(ctx, &replaced, rel.OnConflictReplace()))
+
+	var (
+		queried Name
+	)
+
+	assert.Nil(t, repo.Find(ctx, &queried, where.Eq("id", replaced.ID)))
+	assert.Equal(t, replaced, queried)
+}
+
+func TestAdapter_InsertAll_onConflict(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = open(t)
+		repo    = rel.New(adapter)
+		names   = []Name{
+			{Name: "Luffy"},
+			{Name: "Zoro"},
+		}
+	)
+	defer adapter.Close()
+
+	assert.Nil(t, repo.InsertAll(ctx, &names, rel.OnConflictIgnore()))
+	assert.NotEqual(t, 0, names[0].ID)
+	assert.Equal(t, names[0].ID+1, names[1].ID)
+
+	ids, err := adapter.InsertAll(ctx, rel.From("names"), []string{"id"}, []string{"id", "name"}, []map[string]rel.Mutate{
+		{"id": rel.Set("id", names[1].ID), "name": rel.Set("name", "Zoro")},
+		{"id": rel.Set("id", names[1].ID+1), "name": rel.Set("name", "Nami")},
+	}, rel.OnConflictIgnore())
+	assert.Nil(t, err)
+	assert.Nil(t, ids)
+}
+
 func TestAdapter_InsertAll(t *testing.T) {
 	var (
 		adapter = open(t)
@@ -283,6 +366,39 @@ func TestAdapter_Transaction_nestedRollback(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestAdapter_SupportReturning(t *testing.T) {
+	assert.False(t, New(Config{}).SupportReturning())
+	assert.True(t, New(Config{Returning: true}).SupportReturning())
+}
+
+func TestAdapter_InsertReturning_error(t *testing.T) {
+	var (
+		adapter = open(t)
+		mutates = map[string]rel.Mutate{
+			"name": rel.Set("name", "foo"),
+		}
+	)
+
+	defer adapter.Close()
+
+	_, err := adapter.InsertReturning(context.TODO(), rel.From("not_exists"), mutates, rel.OnConflict{})
+	assert.NotNil(t, err)
+}
+
+func TestAdapter_UpdateReturning_error(t *testing.T) {
+	var (
+		adapter = open(t)
+		mutates = map[string]rel.Mutate{
+			"name": rel.Set("name", "foo"),
+		}
+	)
+
+	defer adapter.Close()
+
+	_, err := adapter.UpdateReturning(context.TODO(), rel.From("not_exists").Where(where.Eq("id", 1)), mutates)
+	assert.NotNil(t, err)
+}
+
 func TestAdapter_InsertAll_error(t *testing.T) {
 	var (
 		adapter = open(t)
@@ -295,7 +411,7 @@ func TestAdapter_InsertAll_error(t *testing.T) {
 		{"notexist": rel.Set("notexist", "12")},
 	}
 
-	ids, err := adapter.InsertAll(context.TODO(), rel.Query{}, "id", fields, mutations)
+	ids, err := adapter.InsertAll(context.TODO(), rel.Query{}, []string{"id"}, fields, mutations, rel.OnConflict{})
 	assert.NotNil(t, err)
 	assert.Nil(t, ids)
 }
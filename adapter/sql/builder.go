@@ -16,9 +16,9 @@ var fieldCache sync.Map
 
 // Builder defines information of query b.
 type Builder struct {
-	config      Config
-	returnField string
-	count       int
+	config       Config
+	returnFields []string
+	count        int
 }
 
 // Table generates query for table creation and modification.
@@ -284,8 +284,8 @@ func (b *Builder) Find(query rel.Query) (string, []interface{}) {
 
 	// TODO: calculate arguments size and if possible buffer size
 
-	b.fields(&buffer, query.SelectQuery.OnlyDistinct, query.SelectQuery.Fields)
-	b.query(&buffer, query)
+	b.selectQuery(&buffer, query)
+	buffer.WriteString(";")
 
 	return buffer.String(), buffer.Arguments
 }
@@ -296,6 +296,7 @@ func (b *Builder) Aggregate(query rel.Query, mode string, field string) (string,
 		buffer Buffer
 	)
 
+	b.with(&buffer, query.WithQuery)
 	buffer.WriteString("SELECT ")
 	buffer.WriteString(mode)
 	buffer.WriteByte('(')
@@ -309,12 +310,13 @@ func (b *Builder) Aggregate(query rel.Query, mode string, field string) (string,
 	}
 
 	b.query(&buffer, query)
+	buffer.WriteString(";")
 
 	return buffer.String(), buffer.Arguments
 }
 
 func (b *Builder) query(buffer *Buffer, query rel.Query) {
-	b.from(buffer, query.Table)
+	b.from(buffer, query)
 	b.join(buffer, query.Table, query.JoinQuery)
 	b.where(buffer, query.WhereQuery)
 
@@ -323,6 +325,7 @@ func (b *Builder) query(buffer *Buffer, query rel.Query) {
 		b.having(buffer, query.GroupQuery.Filter)
 	}
 
+	b.combine(buffer, query.CombineQuery)
 	b.orderBy(buffer, query.SortQuery)
 	b.limitOffset(buffer, query.LimitQuery, query.OffsetQuery)
 
@@ -330,15 +333,128 @@ func (b *Builder) query(buffer *Buffer, query rel.Query) {
 		buffer.WriteByte(' ')
 		buffer.WriteString(string(query.LockQuery))
 	}
+}
 
-	buffer.WriteString(";")
+// subQuery writes query enclosed in parentheses, placeholders are numbered continuing the outer query.
+func (b *Builder) subQuery(buffer *Buffer, query rel.Query) {
+	buffer.WriteByte('(')
+	b.selectQuery(buffer, query)
+	buffer.WriteByte(')')
+}
+
+func (b *Builder) selectQuery(buffer *Buffer, query rel.Query) {
+	if query.SQLQuery.Statement != "" {
+		b.sqlQuery(buffer, query.SQLQuery)
+		return
+	}
+
+	b.with(buffer, query.WithQuery)
+	b.fields(buffer, query.SelectQuery.OnlyDistinct, query.SelectQuery.Fields, query.SelectQuery.Exprs)
+	b.query(buffer, query)
+}
+
+// sqlQuery writes raw query used inside other query.
+// Ordinal placeholders of raw query starts from 1, so it's renumbered to continue the placeholders of the outer query.
+func (b *Builder) sqlQuery(buffer *Buffer, query rel.SQLQuery) {
+	buffer.Append(query.Values...)
+
+	if !b.config.Ordinal {
+		buffer.WriteString(query.Statement)
+		return
+	}
+
+	var (
+		offset    = b.count
+		statement = query.Statement
+	)
+
+	for i := 0; i < len(statement); {
+		j := strings.Index(statement[i:], b.config.Placeholder)
+		if j < 0 {
+			buffer.WriteString(statement[i:])
+			break
+		}
+
+		var (
+			start = i + j + len(b.config.Placeholder)
+			end   = start
+		)
+
+		for end < len(statement) && statement[end] >= '0' && statement[end] <= '9' {
+			end++
+		}
+
+		buffer.WriteString(statement[i:start])
+		if n, err := strconv.Atoi(statement[start:end]); err == nil {
+			buffer.WriteString(strconv.Itoa(offset + n))
+		}
+
+		i = end
+	}
+
+	b.count += len(query.Values)
+}
+
+func (b *Builder) combine(buffer *Buffer, combines []rel.CombineQuery) {
+	for _, combine := range combines {
+		buffer.WriteByte(' ')
+		buffer.WriteString(combine.Op)
+		buffer.WriteByte(' ')
+
+		// sort and limit of combined query need to be enclosed to not apply to the whole result.
+		// database that doesn't support parenthesised combined query (eg: sqlite) selects it from sub query instead.
+		if len(combine.Query.SortQuery) > 0 || combine.Query.LimitQuery > 0 || combine.Query.OffsetQuery > 0 {
+			if b.config.SubQueryCombine {
+				buffer.WriteString("SELECT * FROM ")
+			}
+
+			b.subQuery(buffer, combine.Query)
+		} else {
+			b.selectQuery(buffer, combine.Query)
+		}
+	}
+}
+
+func (b *Builder) with(buffer *Buffer, withs []rel.WithQuery) {
+	if len(withs) == 0 {
+		return
+	}
+
+	buffer.WriteString("WITH ")
+
+	for _, with := range withs {
+		if with.Recursive != nil {
+			buffer.WriteString("RECURSIVE ")
+			break
+		}
+	}
+
+	for i, with := range withs {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		buffer.WriteString(Escape(b.config, with.Name))
+		buffer.WriteString(" AS (")
+		b.selectQuery(buffer, with.Query)
+
+		if with.Recursive != nil {
+			buffer.WriteString(" UNION ALL ")
+			b.selectQuery(buffer, *with.Recursive)
+		}
+
+		buffer.WriteByte(')')
+	}
+
+	buffer.WriteByte(' ')
 }
 
 // Insert generates query for insert.
-func (b *Builder) Insert(table string, mutates map[string]rel.Mutate) (string, []interface{}) {
+func (b *Builder) Insert(table string, mutates map[string]rel.Mutate, onConflict rel.OnConflict) (string, []interface{}) {
 	var (
 		buffer Buffer
 		count  = len(mutates)
+		fields = make([]string, 0, count)
 	)
 
 	buffer.WriteString("INSERT INTO ")
@@ -357,6 +473,7 @@ func (b *Builder) Insert(table string, mutates map[string]rel.Mutate) (string, [
 				buffer.WriteString(field)
 				buffer.WriteString(b.config.EscapeChar)
 				buffer.Arguments[i] = mut.Value
+				fields = append(fields, field)
 			}
 
 			if i < count-1 {
@@ -378,12 +495,9 @@ func (b *Builder) Insert(table string, mutates map[string]rel.Mutate) (string, [
 		buffer.WriteByte(')')
 	}
 
-	if b.returnField != "" {
-		buffer.WriteString(" RETURNING ")
-		buffer.WriteString(b.config.EscapeChar)
-		buffer.WriteString(b.returnField)
-		buffer.WriteString(b.config.EscapeChar)
-	}
+	b.onConflict(&buffer, fields, onConflict)
+
+	b.returning(&buffer)
 
 	buffer.WriteString(";")
 
@@ -391,7 +505,7 @@ func (b *Builder) Insert(table string, mutates map[string]rel.Mutate) (string, [
 }
 
 // InsertAll generates query for multiple insert.
-func (b *Builder) InsertAll(table string, fields []string, bulkMutates []map[string]rel.Mutate) (string, []interface{}) {
+func (b *Builder) InsertAll(table string, fields []string, bulkMutates []map[string]rel.Mutate, onConflict rel.OnConflict) (string, []interface{}) {
 	var (
 		buffer       Buffer
 		fieldsCount  = len(fields)
@@ -442,18 +556,111 @@ func (b *Builder) InsertAll(table string, fields []string, bulkMutates []map[str
 		}
 	}
 
-	if b.returnField != "" {
-		buffer.WriteString(" RETURNING ")
-		buffer.WriteString(b.config.EscapeChar)
-		buffer.WriteString(b.returnField)
-		buffer.WriteString(b.config.EscapeChar)
-	}
+	b.onConflict(&buffer, fields, onConflict)
+
+	b.returning(&buffer)
 
 	buffer.WriteString(";")
 
 	return buffer.String(), buffer.Arguments
 }
 
+func (b *Builder) returning(buffer *Buffer) {
+	if len(b.returnFields) == 0 {
+		return
+	}
+
+	buffer.WriteString(" RETURNING ")
+
+	for i, field := range b.returnFields {
+		buffer.WriteString(Escape(b.config, field))
+
+		if i < len(b.returnFields)-1 {
+			buffer.WriteByte(',')
+		}
+	}
+}
+
+func (b *Builder) onConflict(buffer *Buffer, fields []string, onConflict rel.OnConflict) {
+	if onConflict.None() {
+		return
+	}
+
+	var (
+		replaceFields = onConflict.ReplaceFields(fields)
+	)
+
+	if b.config.OnDuplicateKeyUpdate {
+		b.onDuplicateKeyUpdate(buffer, fields, replaceFields, onConflict.Keys)
+		return
+	}
+
+	buffer.WriteString(" ON CONFLICT")
+
+	if len(onConflict.Keys) > 0 {
+		buffer.WriteString(" (")
+		for i, key := range onConflict.Keys {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+
+			buffer.WriteString(Escape(b.config, key))
+		}
+		buffer.WriteByte(')')
+	}
+
+	if len(replaceFields) == 0 {
+		buffer.WriteString(" DO NOTHING")
+		return
+	}
+
+	buffer.WriteString(" DO UPDATE SET ")
+	for i, field := range replaceFields {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		buffer.WriteString(Escape(b.config, field))
+		buffer.WriteString("=EXCLUDED.")
+		buffer.WriteString(Escape(b.config, field))
+	}
+}
+
+func (b *Builder) onDuplicateKeyUpdate(buffer *Buffer, fields []string, replaceFields []string, keys []string) {
+	if len(replaceFields) == 0 {
+		// ignore conflict by updating a column to it's own value.
+		var (
+			field string
+		)
+
+		if len(keys) > 0 {
+			field = keys[0]
+		} else if len(fields) > 0 {
+			field = fields[0]
+		} else {
+			return
+		}
+
+		buffer.WriteString(" ON DUPLICATE KEY UPDATE ")
+		buffer.WriteString(Escape(b.config, field))
+		buffer.WriteByte('=')
+		buffer.WriteString(Escape(b.config, field))
+		return
+	}
+
+	buffer.WriteString(" ON DUPLICATE KEY UPDATE ")
+	for i, field := range replaceFields {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		buffer.WriteString(Escape(b.config, field))
+		buffer.WriteString("=VALUES(")
+		buffer.WriteString(Escape(b.config, field))
+		buffer.WriteByte(')')
+	}
+}
+
 // Update generates query for update.
 func (b *Builder) Update(table string, mutates map[string]rel.Mutate, filter rel.FilterQuery) (string, []interface{}) {
 	var (
@@ -494,6 +701,7 @@ func (b *Builder) Update(table string, mutates map[string]rel.Mutate, filter rel
 	}
 
 	b.where(&buffer, filter)
+	b.returning(&buffer)
 
 	buffer.WriteString(";")
 
@@ -518,23 +726,19 @@ func (b *Builder) Delete(table string, filter rel.FilterQuery) (string, []interf
 	return buffer.String(), buffer.Arguments
 }
 
-func (b *Builder) fields(buffer *Buffer, distinct bool, fields []string) {
-	if len(fields) == 0 {
-		if distinct {
-			buffer.WriteString("SELECT DISTINCT *")
-			return
-		}
-		buffer.WriteString("SELECT *")
-		return
-	}
-
+// fields writes select clause, expressions are selected after the fields or after all fields when no field is selected.
+func (b *Builder) fields(buffer *Buffer, distinct bool, fields []string, exprs []rel.SelectExpr) {
 	buffer.WriteString("SELECT ")
 
 	if distinct {
 		buffer.WriteString("DISTINCT ")
 	}
 
-	l := len(fields) - 1
+	if len(fields) == 0 {
+		fields = []string{"*"}
+	}
+
+	l := len(fields) + len(exprs) - 1
 	for i, f := range fields {
 		buffer.WriteString(Escape(b.config, f))
 
@@ -542,12 +746,100 @@ func (b *Builder) fields(buffer *Buffer, distinct bool, fields []string) {
 			buffer.WriteByte(',')
 		}
 	}
+
+	for i, expr := range exprs {
+		b.selectExpr(buffer, expr)
+
+		if len(fields)+i < l {
+			buffer.WriteByte(',')
+		}
+	}
+}
+
+func (b *Builder) selectExpr(buffer *Buffer, expr rel.SelectExpr) {
+	buffer.WriteString(expr.Func)
+	buffer.WriteByte('(')
+
+	for i, f := range expr.Fields {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		buffer.WriteString(Escape(b.config, f))
+	}
+
+	buffer.WriteByte(')')
+
+	if expr.Window != nil {
+		buffer.WriteString(" OVER (")
+		b.window(buffer, *expr.Window)
+		buffer.WriteByte(')')
+	}
+
+	if expr.Alias != "" {
+		buffer.WriteString(" AS ")
+		buffer.WriteString(b.config.EscapeChar)
+		buffer.WriteString(expr.Alias)
+		buffer.WriteString(b.config.EscapeChar)
+	}
 }
 
-func (b *Builder) from(buffer *Buffer, table string) {
+func (b *Builder) window(buffer *Buffer, window rel.WindowQuery) {
+	var (
+		sep = ""
+	)
+
+	if len(window.Partition) > 0 {
+		buffer.WriteString("PARTITION BY ")
+
+		for i, f := range window.Partition {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+
+			buffer.WriteString(Escape(b.config, f))
+		}
+
+		sep = " "
+	}
+
+	if len(window.SortQuery) > 0 {
+		buffer.WriteString(sep)
+		buffer.WriteString("ORDER BY ")
+
+		for i, sort := range window.SortQuery {
+			if i > 0 {
+				buffer.WriteString(", ")
+			}
+
+			buffer.WriteString(Escape(b.config, sort.Field))
+
+			if sort.Asc() {
+				buffer.WriteString(" ASC")
+			} else {
+				buffer.WriteString(" DESC")
+			}
+		}
+
+		sep = " "
+	}
+
+	if window.Frame != "" {
+		buffer.WriteString(sep)
+		buffer.WriteString(window.Frame)
+	}
+}
+
+func (b *Builder) from(buffer *Buffer, query rel.Query) {
 	buffer.WriteString(" FROM ")
+
+	if query.TableQuery != nil {
+		b.subQuery(buffer, *query.TableQuery)
+		buffer.WriteString(" AS ")
+	}
+
 	buffer.WriteString(b.config.EscapeChar)
-	buffer.WriteString(table)
+	buffer.WriteString(query.Table)
 	buffer.WriteString(b.config.EscapeChar)
 }
 
@@ -575,9 +867,7 @@ func (b *Builder) join(buffer *Buffer, table string, joins []rel.JoinQuery) {
 		buffer.WriteByte(' ')
 
 		if join.Table != "" {
-			buffer.WriteString(b.config.EscapeChar)
-			buffer.WriteString(join.Table)
-			buffer.WriteString(b.config.EscapeChar)
+			buffer.WriteString(Escape(b.config, join.Table))
 			buffer.WriteString(" ON ")
 			buffer.WriteString(from)
 			buffer.WriteString("=")
@@ -695,6 +985,15 @@ func (b *Builder) filter(buffer *Buffer, filter rel.FilterQuery) {
 	case rel.FilterFragmentOp:
 		buffer.WriteString(filter.Field)
 		buffer.Append(filter.Value.([]interface{})...)
+	case rel.FilterExistsOp:
+		buffer.WriteString("EXISTS ")
+		b.subQuery(buffer, filter.Value.(rel.Query))
+	case rel.FilterNotExistsOp:
+		buffer.WriteString("NOT EXISTS ")
+		b.subQuery(buffer, filter.Value.(rel.Query))
+	case rel.FilterTupleGtOp,
+		rel.FilterTupleLtOp:
+		b.buildTuple(buffer, filter)
 	}
 }
 
@@ -740,6 +1039,11 @@ func (b *Builder) buildComparison(buffer *Buffer, filter rel.FilterQuery) {
 		buffer.WriteString(">=")
 	}
 
+	if query, ok := filter.Value.(rel.Query); ok {
+		b.subQuery(buffer, query)
+		return
+	}
+
 	buffer.WriteString(b.ph())
 	buffer.Append(filter.Value)
 }
@@ -752,11 +1056,19 @@ func (b *Builder) buildInclusion(buffer *Buffer, filter rel.FilterQuery) {
 	buffer.WriteString(Escape(b.config, filter.Field))
 
 	if filter.Type == rel.FilterInOp {
-		buffer.WriteString(" IN (")
+		buffer.WriteString(" IN ")
 	} else {
-		buffer.WriteString(" NOT IN (")
+		buffer.WriteString(" NOT IN ")
 	}
 
+	if len(values) == 1 {
+		if query, ok := values[0].(rel.Query); ok {
+			b.subQuery(buffer, query)
+			return
+		}
+	}
+
+	buffer.WriteByte('(')
 	buffer.WriteString(b.ph())
 	for i := 1; i <= len(values)-1; i++ {
 		buffer.WriteByte(',')
@@ -766,6 +1078,34 @@ func (b *Builder) buildInclusion(buffer *Buffer, filter rel.FilterQuery) {
 	buffer.Append(values...)
 }
 
+func (b *Builder) buildTuple(buffer *Buffer, filter rel.FilterQuery) {
+	buffer.WriteByte('(')
+	for i, inner := range filter.Inner {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		buffer.WriteString(Escape(b.config, inner.Field))
+	}
+
+	if filter.Type == rel.FilterTupleGtOp {
+		buffer.WriteString(")>(")
+	} else {
+		buffer.WriteString(")<(")
+	}
+
+	for i, inner := range filter.Inner {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		buffer.WriteString(b.ph())
+		buffer.Append(inner.Value)
+	}
+
+	buffer.WriteByte(')')
+}
+
 func (b *Builder) ph() string {
 	if b.config.Ordinal {
 		b.count++
@@ -775,9 +1115,9 @@ func (b *Builder) ph() string {
 	return b.config.Placeholder
 }
 
-// Returning append returning to insert rel.
-func (b *Builder) Returning(field string) *Builder {
-	b.returnField = field
+// Returning append returning to insert or update rel.
+func (b *Builder) Returning(fields ...string) *Builder {
+	b.returnFields = fields
 	return b
 }
 
@@ -307,6 +307,42 @@ func TestBuilder_Find(t *testing.T) {
 			nil,
 			query.Offset(10).Limit(10),
 		},
+		{
+			"SELECT *,RANK() OVER (PARTITION BY `game_id` ORDER BY `score` DESC) AS `rank` FROM `users`;",
+			nil,
+			query.SelectExpr(rel.Rank().Over(rel.Window("game_id").SortDesc("score")).As("rank")),
+		},
+		{
+			"SELECT `id`,`score`,ROW_NUMBER() OVER (ORDER BY `score` DESC, `id` ASC) AS `position`,SUM(`score`) OVER (PARTITION BY `game_id`) AS `total` FROM `users`;",
+			nil,
+			query.Select("id", "score").SelectExpr(
+				rel.RowNumber().Over(rel.Window().SortDesc("score").SortAsc("id")).As("position"),
+				rel.Sum("score").Over(rel.Window("game_id")).As("total"),
+			),
+		},
+		{
+			"SELECT * FROM (SELECT *,ROW_NUMBER() OVER (PARTITION BY `user_id` ORDER BY `created_at` DESC) AS `rn` FROM `users`) AS `latest` WHERE `rn`=?;",
+			[]interface{}{1},
+			rel.FromQuery("latest", query.SelectExpr(rel.RowNumber().Over(rel.Window("user_id").SortDesc("created_at")).As("rn"))).Where(where.Eq("rn", 1)),
+		},
+		{
+			"SELECT `id`,`name` FROM `users` WHERE `active`=? UNION SELECT `id`,`name` FROM `admins` ORDER BY `name` ASC LIMIT 10;",
+			[]interface{}{true},
+			query.Select("id", "name").Where(where.Eq("active", true)).Union(rel.Select("id", "name").From("admins")).SortAsc("name").Limit(10),
+		},
+		{
+			"SELECT `id` FROM `users` UNION ALL SELECT `user_id` FROM `posts` INTERSECT SELECT `user_id` FROM `comments` EXCEPT SELECT `id` FROM `banned_users`;",
+			nil,
+			query.Select("id").
+				UnionAll(rel.Select("user_id").From("posts")).
+				Intersect(rel.Select("user_id").From("comments")).
+				Except(rel.Select("id").From("banned_users")),
+		},
+		{
+			"SELECT `id` FROM `users` UNION (SELECT `id` FROM `admins` ORDER BY `created_at` DESC LIMIT 5) LIMIT 20;",
+			nil,
+			query.Select("id").Union(rel.Select("id").From("admins").SortDesc("created_at").Limit(5)).Limit(20),
+		},
 	}
 
 	for _, test := range tests {
@@ -398,6 +434,44 @@ func TestBuilder_Find_ordinal(t *testing.T) {
 			nil,
 			query.Offset(10).Limit(10),
 		},
+		{
+			"SELECT * FROM \"users\" WHERE (\"age\">$1 AND \"id\" IN (SELECT \"user_id\" FROM \"transactions\" WHERE \"paid\"=$2) AND \"name\"<>$3);",
+			[]interface{}{20, true, "rel"},
+			query.Where(where.Gt("age", 20), where.In("id", rel.Select("user_id").From("transactions").Where(where.Eq("paid", true))), where.Ne("name", "rel")),
+		},
+		{
+			"SELECT * FROM \"users\" WHERE (EXISTS (SELECT * FROM \"transactions\" WHERE (\"transactions\".\"user_id\"=\"users\".\"id\" AND \"status\"=$1)) AND \"age\">$2);",
+			[]interface{}{"paid", 20},
+			query.Where(where.Exists(rel.From("transactions").Wheref("\"transactions\".\"user_id\"=\"users\".\"id\"").Where(where.Eq("status", "paid"))), where.Gt("age", 20)),
+		},
+		{
+			"SELECT * FROM (SELECT * FROM \"users\" WHERE \"age\">$1) AS \"adults\" WHERE \"name\"=$2;",
+			[]interface{}{20, "rel"},
+			rel.FromQuery("adults", query.Where(where.Gt("age", 20))).Where(where.Eq("name", "rel")),
+		},
+		{
+			"WITH \"adults\" AS (SELECT * FROM \"users\" WHERE \"age\">$1),\"admins\" AS (SELECT * FROM \"users\" WHERE \"role\"=$2) SELECT * FROM \"adults\" WHERE (\"id\" IN (SELECT \"id\" FROM \"admins\") AND \"name\"=$3);",
+			[]interface{}{20, "admin", "rel"},
+			rel.With("adults", query.Where(where.Gt("age", 20))).
+				With("admins", query.Where(where.Eq("role", "admin"))).
+				From("adults").Where(where.In("id", rel.Select("id").From("admins")).AndEq("name", "rel")),
+		},
+		{
+			"WITH RECURSIVE \"tree\" AS (SELECT * FROM \"categories\" WHERE \"id\"=$1 UNION ALL SELECT \"categories\".* FROM \"categories\" JOIN \"tree\" ON \"tree\".\"id\"=\"categories\".\"parent_id\" WHERE \"categories\".\"depth\"<$2) SELECT * FROM \"tree\" WHERE \"name\"<>$3;",
+			[]interface{}{1, 5, "rel"},
+			rel.WithRecursive("tree",
+				rel.From("categories").Where(where.Eq("id", 1)),
+				rel.Select("categories.*").From("categories").JoinOn("tree", "tree.id", "categories.parent_id").Where(where.Lt("categories.depth", 5)),
+			).From("tree").Where(where.Ne("name", "rel")),
+		},
+		{
+			"SELECT \"id\" FROM \"users\" WHERE \"age\">$1 UNION SELECT \"id\" FROM \"admins\" WHERE \"role\"=$2 EXCEPT (SELECT \"id\" FROM \"users\" WHERE \"name\"=$3 LIMIT 1) ORDER BY \"id\" ASC LIMIT 10 OFFSET 10;",
+			[]interface{}{20, "admin", "rel"},
+			query.Select("id").Where(where.Gt("age", 20)).
+				Union(rel.Select("id").From("admins").Where(where.Eq("role", "admin"))).
+				Except(rel.Select("id").From("users").Where(where.Eq("name", "rel")).Limit(1)).
+				SortAsc("id").Offset(10).Limit(10),
+		},
 	}
 
 	for _, test := range tests {
@@ -413,6 +487,25 @@ func TestBuilder_Find_ordinal(t *testing.T) {
 	}
 }
 
+func TestBuilder_Find_subQueryCombine(t *testing.T) {
+	var (
+		config = Config{
+			Placeholder:     "?",
+			EscapeChar:      "`",
+			SubQueryCombine: true,
+		}
+		builder = NewBuilder(config)
+		query   = rel.Select("id").From("users").
+			Union(rel.Select("id").From("admins").SortDesc("created_at").Limit(5)).
+			UnionAll(rel.Select("id").From("guests")).
+			Limit(20)
+		qs, args = builder.Find(query)
+	)
+
+	assert.Equal(t, "SELECT `id` FROM `users` UNION SELECT * FROM (SELECT `id` FROM `admins` ORDER BY `created_at` DESC LIMIT 5) UNION ALL SELECT `id` FROM `guests` LIMIT 20;", qs)
+	assert.Nil(t, args)
+}
+
 func TestBuilder_Find_SQLQuery(t *testing.T) {
 	var (
 		config   = Config{}
@@ -460,6 +553,14 @@ func TestBuilder_Aggregate(t *testing.T) {
 	qs, args = builder.Aggregate(query.Group("gender"), "sum", "transactions.total")
 	assert.Nil(t, args)
 	assert.Equal(t, "SELECT sum(`transactions`.`total`) AS sum,`gender` FROM `users` GROUP BY `gender`;", qs)
+
+	qs, args = builder.Aggregate(rel.FromQuery("users", query.Union(rel.From("admins"))), "count", "*")
+	assert.Nil(t, args)
+	assert.Equal(t, "SELECT count(*) AS count FROM (SELECT * FROM `users` UNION SELECT * FROM `admins`) AS `users`;", qs)
+
+	qs, args = builder.Aggregate(rel.With("adults", query.Where(where.Gt("age", 20))).From("adults"), "count", "*")
+	assert.Equal(t, []interface{}{20}, args)
+	assert.Equal(t, "WITH `adults` AS (SELECT * FROM `users` WHERE `age`>?) SELECT count(*) AS count FROM `adults`;", qs)
 }
 
 func BenchmarkBuilder_Insert(b *testing.B) {
@@ -477,7 +578,7 @@ func BenchmarkBuilder_Insert(b *testing.B) {
 	)
 
 	for n := 0; n < b.N; n++ {
-		builder.Insert("users", mutates)
+		builder.Insert("users", mutates, rel.OnConflict{})
 	}
 }
 
@@ -493,7 +594,7 @@ func TestBuilder_Insert(t *testing.T) {
 			"age":   rel.Set("age", 10),
 			"agree": rel.Set("agree", true),
 		}
-		qs, args = builder.Insert("users", mutates)
+		qs, args = builder.Insert("users", mutates, rel.OnConflict{})
 	)
 
 	assert.Regexp(t, fmt.Sprint(`^INSERT INTO `, "`users`", ` \((`, "`", `\w*`, "`", `,?){3}\) VALUES \(\?,\?,\?\);`), qs)
@@ -517,7 +618,7 @@ func TestBuilder_Insert_ordinal(t *testing.T) {
 			"age":   rel.Set("age", 10),
 			"agree": rel.Set("agree", true),
 		}
-		qs, args = builder.Returning("id").Insert("users", mutates)
+		qs, args = builder.Returning("id").Insert("users", mutates, rel.OnConflict{})
 	)
 
 	assert.Regexp(t, `^INSERT INTO \"users\" \(("\w*",?){3}\) VALUES \(\$1,\$2,\$3\) RETURNING \"id\";`, qs)
@@ -536,7 +637,7 @@ func TestBuilder_Insert_defaultValuesDisabled(t *testing.T) {
 		}
 		builder  = NewBuilder(config)
 		mutates  = map[string]rel.Mutate{}
-		qs, args = builder.Insert("users", mutates)
+		qs, args = builder.Insert("users", mutates, rel.OnConflict{})
 	)
 
 	assert.Equal(t, "INSERT INTO `users` () VALUES ();", qs)
@@ -552,13 +653,147 @@ func TestBuilder_Insert_defaultValuesEnabled(t *testing.T) {
 		}
 		builder  = NewBuilder(config)
 		mutates  = map[string]rel.Mutate{}
-		qs, args = builder.Returning("id").Insert("users", mutates)
+		qs, args = builder.Returning("id").Insert("users", mutates, rel.OnConflict{})
 	)
 
 	assert.Equal(t, "INSERT INTO `users` DEFAULT VALUES RETURNING `id`;", qs)
 	assert.Nil(t, args)
 }
 
+func TestBuilder_Insert_returningCompositePrimary(t *testing.T) {
+	var (
+		config = Config{
+			Placeholder: "$",
+			EscapeChar:  "\"",
+			Ordinal:     true,
+		}
+		builder = NewBuilder(config)
+		mutates = map[string]rel.Mutate{
+			"user_id": rel.Set("user_id", 1),
+		}
+		qs, args = builder.Returning("user_id", "role_id").Insert("user_roles", mutates, rel.OnConflict{})
+	)
+
+	assert.Equal(t, `INSERT INTO "user_roles" ("user_id") VALUES ($1) RETURNING "user_id","role_id";`, qs)
+	assert.Equal(t, []interface{}{1}, args)
+}
+
+func TestBuilder_Insert_returningAll(t *testing.T) {
+	var (
+		config = Config{
+			Placeholder: "$",
+			EscapeChar:  "\"",
+			Ordinal:     true,
+		}
+		builder = NewBuilder(config)
+		mutates = map[string]rel.Mutate{
+			"name": rel.Set("name", "foo"),
+		}
+		qs, args = builder.Returning("*").Insert("users", mutates, rel.OnConflict{})
+	)
+
+	assert.Equal(t, `INSERT INTO "users" ("name") VALUES ($1) RETURNING *;`, qs)
+	assert.Equal(t, []interface{}{"foo"}, args)
+}
+
+func TestBuilder_Insert_onConflict(t *testing.T) {
+	var (
+		mutates = map[string]rel.Mutate{
+			"name": rel.Set("name", "foo"),
+		}
+	)
+
+	tests := []struct {
+		name       string
+		config     Config
+		onConflict rel.OnConflict
+		result     string
+	}{
+		{
+			name:       "ignore",
+			config:     Config{Placeholder: "?", EscapeChar: "`"},
+			onConflict: rel.OnConflictKeyIgnore("id"),
+			result:     "INSERT INTO `users` (`name`) VALUES (?) ON CONFLICT (`id`) DO NOTHING;",
+		},
+		{
+			name:       "ignore without keys",
+			config:     Config{Placeholder: "?", EscapeChar: "`"},
+			onConflict: rel.OnConflictIgnore(),
+			result:     "INSERT INTO `users` (`name`) VALUES (?) ON CONFLICT DO NOTHING;",
+		},
+		{
+			name:       "replace",
+			config:     Config{Placeholder: "?", EscapeChar: "`"},
+			onConflict: rel.OnConflictKeyReplace("id"),
+			result:     "INSERT INTO `users` (`name`) VALUES (?) ON CONFLICT (`id`) DO UPDATE SET `name`=EXCLUDED.`name`;",
+		},
+		{
+			name:       "replace only keys",
+			config:     Config{Placeholder: "?", EscapeChar: "`"},
+			onConflict: rel.OnConflictKeyReplace("name"),
+			result:     "INSERT INTO `users` (`name`) VALUES (?) ON CONFLICT (`name`) DO NOTHING;",
+		},
+		{
+			name:       "replace fields",
+			config:     Config{Placeholder: "?", EscapeChar: "`"},
+			onConflict: rel.OnConflictKeysReplaceFields([]string{"name", "age"}, "name", "updated_at"),
+			result:     "INSERT INTO `users` (`name`) VALUES (?) ON CONFLICT (`name`,`age`) DO UPDATE SET `name`=EXCLUDED.`name`,`updated_at`=EXCLUDED.`updated_at`;",
+		},
+		{
+			name:       "on duplicate key ignore",
+			config:     Config{Placeholder: "?", EscapeChar: "`", OnDuplicateKeyUpdate: true},
+			onConflict: rel.OnConflictKeyIgnore("id"),
+			result:     "INSERT INTO `users` (`name`) VALUES (?) ON DUPLICATE KEY UPDATE `id`=`id`;",
+		},
+		{
+			name:       "on duplicate key ignore without keys",
+			config:     Config{Placeholder: "?", EscapeChar: "`", OnDuplicateKeyUpdate: true},
+			onConflict: rel.OnConflictIgnore(),
+			result:     "INSERT INTO `users` (`name`) VALUES (?) ON DUPLICATE KEY UPDATE `name`=`name`;",
+		},
+		{
+			name:       "on duplicate key replace",
+			config:     Config{Placeholder: "?", EscapeChar: "`", OnDuplicateKeyUpdate: true},
+			onConflict: rel.OnConflictKeyReplace("id"),
+			result:     "INSERT INTO `users` (`name`) VALUES (?) ON DUPLICATE KEY UPDATE `name`=VALUES(`name`);",
+		},
+		{
+			name:       "on duplicate key replace fields",
+			config:     Config{Placeholder: "?", EscapeChar: "`", OnDuplicateKeyUpdate: true},
+			onConflict: rel.OnConflictReplaceFields("name", "age"),
+			result:     "INSERT INTO `users` (`name`) VALUES (?) ON DUPLICATE KEY UPDATE `name`=VALUES(`name`),`age`=VALUES(`age`);",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				builder  = NewBuilder(test.config)
+				qs, args = builder.Insert("users", mutates, test.onConflict)
+			)
+
+			assert.Equal(t, test.result, qs)
+			assert.Equal(t, []interface{}{"foo"}, args)
+		})
+	}
+}
+
+func TestBuilder_Insert_onConflictOrdinal(t *testing.T) {
+	var (
+		config = Config{
+			Placeholder:         "$",
+			EscapeChar:          "\"",
+			Ordinal:             true,
+			InsertDefaultValues: true,
+		}
+		builder  = NewBuilder(config)
+		qs, args = builder.Returning("id").Insert("users", map[string]rel.Mutate{}, rel.OnConflictKeyIgnore("id"))
+	)
+
+	assert.Equal(t, `INSERT INTO "users" DEFAULT VALUES ON CONFLICT ("id") DO NOTHING RETURNING "id";`, qs)
+	assert.Nil(t, args)
+}
+
 func BenchmarkBuilder_InsertAll(b *testing.B) {
 	var (
 		config = Config{
@@ -581,7 +816,7 @@ func BenchmarkBuilder_InsertAll(b *testing.B) {
 	)
 
 	for n := 0; n < b.N; n++ {
-		builder.InsertAll("users", []string{"name"}, bulkMutates)
+		builder.InsertAll("users", []string{"name"}, bulkMutates, rel.OnConflict{})
 	}
 }
 
@@ -606,12 +841,12 @@ func TestBuilder_InsertAll(t *testing.T) {
 		}
 	)
 
-	statement, args := builder.InsertAll("users", []string{"name"}, bulkMutates)
+	statement, args := builder.InsertAll("users", []string{"name"}, bulkMutates, rel.OnConflict{})
 	assert.Equal(t, "INSERT INTO `users` (`name`) VALUES (?),(DEFAULT),(?);", statement)
 	assert.Equal(t, []interface{}{"foo", "boo"}, args)
 
 	// with age
-	statement, args = builder.InsertAll("users", []string{"name", "age"}, bulkMutates)
+	statement, args = builder.InsertAll("users", []string{"name", "age"}, bulkMutates, rel.OnConflict{})
 	assert.Equal(t, "INSERT INTO `users` (`name`,`age`) VALUES (?,DEFAULT),(DEFAULT,?),(?,?);", statement)
 	assert.Equal(t, []interface{}{"foo", 10, "boo", 20}, args)
 }
@@ -639,17 +874,47 @@ func TestBuilder_InsertAll_ordinal(t *testing.T) {
 		}
 	)
 
-	statement, args := builder.Returning("id").InsertAll("users", []string{"name"}, bulkMutates)
+	statement, args := builder.Returning("id").InsertAll("users", []string{"name"}, bulkMutates, rel.OnConflict{})
 	assert.Equal(t, "INSERT INTO \"users\" (\"name\") VALUES ($1),(DEFAULT),($2) RETURNING \"id\";", statement)
 	assert.Equal(t, []interface{}{"foo", "boo"}, args)
 
 	// with age
 	builder.count = 0
-	statement, args = builder.Returning("id").InsertAll("users", []string{"name", "age"}, bulkMutates)
+	statement, args = builder.Returning("id").InsertAll("users", []string{"name", "age"}, bulkMutates, rel.OnConflict{})
 	assert.Equal(t, "INSERT INTO \"users\" (\"name\",\"age\") VALUES ($1,DEFAULT),(DEFAULT,$2),($3,$4) RETURNING \"id\";", statement)
 	assert.Equal(t, []interface{}{"foo", 10, "boo", 20}, args)
 }
 
+func TestBuilder_InsertAll_onConflict(t *testing.T) {
+	var (
+		bulkMutates = []map[string]rel.Mutate{
+			{
+				"name": rel.Set("name", "foo"),
+			},
+			{
+				"name": rel.Set("name", "boo"),
+				"age":  rel.Set("age", 20),
+			},
+		}
+	)
+
+	statement, args := NewBuilder(Config{
+		Placeholder: "$",
+		EscapeChar:  "\"",
+		Ordinal:     true,
+	}).Returning("id").InsertAll("users", []string{"name", "age"}, bulkMutates, rel.OnConflictKeyReplace("name"))
+	assert.Equal(t, `INSERT INTO "users" ("name","age") VALUES ($1,DEFAULT),($2,$3) ON CONFLICT ("name") DO UPDATE SET "age"=EXCLUDED."age" RETURNING "id";`, statement)
+	assert.Equal(t, []interface{}{"foo", "boo", 20}, args)
+
+	statement, args = NewBuilder(Config{
+		Placeholder:          "?",
+		EscapeChar:           "`",
+		OnDuplicateKeyUpdate: true,
+	}).InsertAll("users", []string{"name", "age"}, bulkMutates, rel.OnConflictKeyReplace("name"))
+	assert.Equal(t, "INSERT INTO `users` (`name`,`age`) VALUES (?,DEFAULT),(?,?) ON DUPLICATE KEY UPDATE `age`=VALUES(`age`);", statement)
+	assert.Equal(t, []interface{}{"foo", "boo", 20}, args)
+}
+
 func TestBuilder_Update(t *testing.T) {
 	var (
 		config = Config{
@@ -699,6 +964,24 @@ func TestBuilder_Update_ordinal(t *testing.T) {
 	assert.ElementsMatch(t, []interface{}{"foo", 10, true, 1}, args)
 }
 
+func TestBuilder_Update_returning(t *testing.T) {
+	var (
+		config = Config{
+			Placeholder: "$",
+			EscapeChar:  "\"",
+			Ordinal:     true,
+		}
+		builder = NewBuilder(config)
+		mutates = map[string]rel.Mutate{
+			"age": rel.Inc("age"),
+		}
+	)
+
+	qs, args := builder.Returning("*").Update("users", mutates, where.Eq("id", 1))
+	assert.Equal(t, `UPDATE "users" SET "age"="age"+$1 WHERE "id"=$2 RETURNING *;`, qs)
+	assert.Equal(t, []interface{}{1, 1}, args)
+}
+
 func TestBuilder_Update_incDecAndFragment(t *testing.T) {
 	var (
 		config = Config{
@@ -768,6 +1051,7 @@ func TestBuilder_Select(t *testing.T) {
 		result   string
 		distinct bool
 		fields   []string
+		exprs    []rel.SelectExpr
 	}{
 		{
 			result: "SELECT *",
@@ -802,6 +1086,16 @@ func TestBuilder_Select(t *testing.T) {
 			result: "SELECT SUM(`transactions`.`total`) AS `total`",
 			fields: []string{"SUM(transactions.total) AS total"},
 		},
+		{
+			result: "SELECT *,RANK() OVER () AS `rank`",
+			exprs:  []rel.SelectExpr{rel.Rank().Over(rel.Window()).As("rank")},
+		},
+		{
+			result:   "SELECT DISTINCT `id`,COUNT(`id`),SUM(`score`)",
+			distinct: true,
+			fields:   []string{"id"},
+			exprs:    []rel.SelectExpr{rel.Count("id"), rel.Sum("score")},
+		},
 	}
 
 	for _, test := range tests {
@@ -810,7 +1104,58 @@ func TestBuilder_Select(t *testing.T) {
 				buffer Buffer
 			)
 
-			builder.fields(&buffer, test.distinct, test.fields)
+			builder.fields(&buffer, test.distinct, test.fields, test.exprs)
+			assert.Equal(t, test.result, buffer.String())
+		})
+	}
+}
+
+func TestBuilder_SelectExpr(t *testing.T) {
+	var (
+		config = Config{
+			Placeholder: "?",
+			EscapeChar:  "`",
+		}
+		builder = NewBuilder(config)
+	)
+
+	tests := []struct {
+		result string
+		expr   rel.SelectExpr
+	}{
+		{
+			result: "COUNT(*)",
+			expr:   rel.Count("*"),
+		},
+		{
+			result: "MAX(`scores`.`value`) AS `best`",
+			expr:   rel.Max("scores.value").As("best"),
+		},
+		{
+			result: "LAG(`score`,1) OVER (ORDER BY `created_at` ASC) AS `previous`",
+			expr:   rel.NewSelectExpr("LAG", "score", "^1").Over(rel.Window().SortAsc("created_at")).As("previous"),
+		},
+		{
+			result: "DENSE_RANK() OVER () AS `rank`",
+			expr:   rel.DenseRank().Over(rel.Window()).As("rank"),
+		},
+		{
+			result: "AVG(`score`) OVER (PARTITION BY `game_id`,`level` ORDER BY `created_at` ASC ROWS BETWEEN 2 PRECEDING AND CURRENT ROW) AS `moving_avg`",
+			expr:   rel.Avg("score").Over(rel.Window("game_id", "level").SortAsc("created_at").Framef("ROWS BETWEEN 2 PRECEDING AND CURRENT ROW")).As("moving_avg"),
+		},
+		{
+			result: "SUM(`score`) OVER (ROWS UNBOUNDED PRECEDING)",
+			expr:   rel.Sum("score").Over(rel.Window().Framef("ROWS UNBOUNDED PRECEDING")),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.result, func(t *testing.T) {
+			var (
+				buffer Buffer
+			)
+
+			builder.selectExpr(&buffer, test.expr)
 			assert.Equal(t, test.result, buffer.String())
 		})
 	}
@@ -826,10 +1171,25 @@ func TestBuilder_From(t *testing.T) {
 		builder = NewBuilder(config)
 	)
 
-	builder.from(&buffer, "users")
+	builder.from(&buffer, rel.From("users"))
 	assert.Equal(t, " FROM `users`", buffer.String())
 }
 
+func TestBuilder_From_subQuery(t *testing.T) {
+	var (
+		buffer Buffer
+		config = Config{
+			Placeholder: "?",
+			EscapeChar:  "`",
+		}
+		builder = NewBuilder(config)
+	)
+
+	builder.from(&buffer, rel.FromQuery("active_users", rel.From("users").Where(where.Eq("active", true))))
+	assert.Equal(t, " FROM (SELECT * FROM `users` WHERE `active`=?) AS `active_users`", buffer.String())
+	assert.Equal(t, []interface{}{true}, buffer.Arguments)
+}
+
 func TestBuilder_Join(t *testing.T) {
 	var (
 		config = Config{
@@ -863,6 +1223,10 @@ func TestBuilder_Join(t *testing.T) {
 			rel.From("transactions").JoinOn("users", "users.id", "transactions.user_id").
 				JoinOn("payments", "payments.id", "transactions.payment_id"),
 		},
+		{
+			" LEFT JOIN `users` AS `buyer` ON `buyer`.`id`=`transactions`.`user_id`",
+			rel.From("transactions").JoinWith("LEFT JOIN", "users AS buyer", "buyer.id", "transactions.user_id"),
+		},
 	}
 
 	for _, test := range tests {
@@ -1261,6 +1625,46 @@ func TestBuilder_Filter(t *testing.T) {
 			[]interface{}{"%value1%", "%value2%"},
 			where.And(where.Like("field1", "%value1%"), where.NotLike("field2", "%value2%")),
 		},
+		{
+			"`user_id`=(SELECT `id` FROM `users` WHERE `name`=? LIMIT 1)",
+			[]interface{}{"rel"},
+			where.Eq("user_id", rel.Select("id").From("users").Where(where.Eq("name", "rel")).Limit(1)),
+		},
+		{
+			"`user_id` IN (SELECT `id` FROM `users` WHERE `active`=?)",
+			[]interface{}{true},
+			where.In("user_id", rel.Select("id").From("users").Where(where.Eq("active", true))),
+		},
+		{
+			"`user_id` NOT IN (SELECT `id` FROM `users` WHERE `active`=?)",
+			[]interface{}{false},
+			where.Nin("user_id", rel.Select("id").From("users").Where(where.Eq("active", false))),
+		},
+		{
+			"`user_id` IN (SELECT id FROM users WHERE active=?)",
+			[]interface{}{true},
+			where.In("user_id", rel.Build("", rel.SQL("SELECT id FROM users WHERE active=?", true))),
+		},
+		{
+			"EXISTS (SELECT * FROM `transactions` WHERE `status`=?)",
+			[]interface{}{"paid"},
+			where.Exists(rel.From("transactions").Where(where.Eq("status", "paid"))),
+		},
+		{
+			"NOT EXISTS (SELECT * FROM `transactions` WHERE `status`=?)",
+			[]interface{}{"paid"},
+			where.NotExists(rel.From("transactions").Where(where.Eq("status", "paid"))),
+		},
+		{
+			"(`created_at`,`id`)>(?,?)",
+			[]interface{}{"2020-01-01", 10},
+			where.TupleGt([]string{"created_at", "id"}, []interface{}{"2020-01-01", 10}),
+		},
+		{
+			"(`created_at`,`id`)<(?,?)",
+			[]interface{}{"2020-01-01", 10},
+			where.TupleLt([]string{"created_at", "id"}, []interface{}{"2020-01-01", 10}),
+		},
 		{
 			"",
 			nil,
@@ -1388,6 +1792,15 @@ func TestBuilder_Filter_ordinal(t *testing.T) {
 			nil,
 			where.Fragment("FRAGMENT"),
 		},
+		{
+			"(\"field1\"=$1 AND \"field2\" IN (SELECT id FROM users WHERE active=$2 AND age>$3) AND \"field3\"=$4)",
+			[]interface{}{"value1", true, 10, "value3"},
+			where.And(
+				where.Eq("field1", "value1"),
+				where.In("field2", rel.Build("", rel.SQL("SELECT id FROM users WHERE active=$1 AND age>$2", true, 10))),
+				where.Eq("field3", "value3"),
+			),
+		},
 		{
 			"(\"field1\"=$1 AND \"field2\"=$2)",
 			[]interface{}{"value1", "value2"},
@@ -1448,6 +1861,20 @@ func TestBuilder_Filter_ordinal(t *testing.T) {
 			[]interface{}{"%value1%", "%value2%"},
 			where.And(where.Like("field1", "%value1%"), where.NotLike("field2", "%value2%")),
 		},
+		{
+			"(\"field\"=$1 AND \"user_id\" NOT IN (SELECT \"id\" FROM \"users\" WHERE (\"active\"=$2 AND \"age\">$3)) AND NOT EXISTS (SELECT * FROM \"transactions\" WHERE \"status\"=$4))",
+			[]interface{}{"value", true, 20, "paid"},
+			where.And(
+				where.Eq("field", "value"),
+				where.Nin("user_id", rel.Select("id").From("users").Where(where.Eq("active", true), where.Gt("age", 20))),
+				where.Not(where.Exists(rel.From("transactions").Where(where.Eq("status", "paid")))),
+			),
+		},
+		{
+			"(\"active\"=$1 AND (\"created_at\",\"id\")>($2,$3))",
+			[]interface{}{true, "2020-01-01", 10},
+			where.And(where.Eq("active", true), where.TupleGt([]string{"created_at", "id"}, []interface{}{"2020-01-01", 10})),
+		},
 		{
 			"",
 			nil,
@@ -8,15 +8,22 @@ import (
 
 // Config holds configuration for adapter.
 type Config struct {
-	Placeholder         string
-	Ordinal             bool
-	InsertDefaultValues bool
-	DropIndexOnTable    bool
-	EscapeChar          string
-	ErrorFunc           func(error) error
-	IncrementFunc       func(Adapter) int
-	IndexToSQL          func(config Config, buffer *Buffer, index rel.Index) bool
-	MapColumnFunc       func(column *rel.Column) (string, int, int)
+	Placeholder          string
+	Ordinal              bool
+	InsertDefaultValues  bool
+	OnDuplicateKeyUpdate bool
+	Returning            bool
+	DropIndexOnTable     bool
+	SubQueryCombine      bool
+	EscapeChar           string
+	ErrorFunc            func(error) error
+	IncrementFunc        func(Adapter) int
+	IndexToSQL           func(config Config, buffer *Buffer, index rel.Index) bool
+	MapColumnFunc        func(column *rel.Column) (string, int, int)
+
+	// StatementCacheSize is the number of prepared statements cached by the database and by each transaction.
+	// Statement cache is disabled when it's zero.
+	StatementCacheSize int
 }
 
 // MapColumn func.
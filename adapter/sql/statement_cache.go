@@ -0,0 +1,176 @@
+package sql
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/go-rel/rel"
+)
+
+// statementCache is a lru cache of prepared statements.
+// Statements of the database are shared by its transactions, each transaction binds them using Tx.StmtContext
+// and keeps the bound statements in its own cache.
+type statementCache struct {
+	size   int
+	db     *sql.DB
+	tx     *sql.Tx
+	parent *statementCache
+	items  map[string]*list.Element
+	order  *list.List
+	mutex  sync.Mutex
+}
+
+type cachedStatement struct {
+	statement string
+	stmt      *sql.Stmt
+	release   func()
+	refs      int
+	evicted   bool
+}
+
+// get returns prepared statement from the cache, the statement is prepared when it's not cached yet.
+// The returned function must be called when the statement is no longer used, evicted statement is only closed
+// after it's released by every user.
+func (sc *statementCache) get(ctx context.Context, instrumenter rel.Instrumenter, statement string) (*sql.Stmt, func(), error) {
+	sc.mutex.Lock()
+	if elem, ok := sc.items[statement]; ok {
+		var (
+			cs = elem.Value.(*cachedStatement)
+		)
+
+		cs.refs++
+		sc.order.MoveToFront(elem)
+		sc.mutex.Unlock()
+
+		instrumenter.Observe(ctx, "adapter-statement-cache-hit", statement)(nil)
+		return cs.stmt, sc.releaser(cs), nil
+	}
+	sc.mutex.Unlock()
+
+	finish := instrumenter.Observe(ctx, "adapter-statement-cache-miss", statement)
+	stmt, release, err := sc.prepare(ctx, instrumenter, statement)
+	finish(err)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	// the same statement might be prepared concurrently, keep the one that is cached first.
+	if elem, ok := sc.items[statement]; ok {
+		stmt.Close()
+		release()
+
+		var (
+			cs = elem.Value.(*cachedStatement)
+		)
+
+		cs.refs++
+		sc.order.MoveToFront(elem)
+		return cs.stmt, sc.releaser(cs), nil
+	}
+
+	var (
+		cs = &cachedStatement{statement: statement, stmt: stmt, release: release, refs: 1}
+	)
+
+	sc.items[statement] = sc.order.PushFront(cs)
+	for sc.order.Len() > sc.size {
+		sc.evict(sc.order.Back())
+	}
+
+	return stmt, sc.releaser(cs), nil
+}
+
+func (sc *statementCache) prepare(ctx context.Context, instrumenter rel.Instrumenter, statement string) (*sql.Stmt, func(), error) {
+	if sc.tx == nil {
+		stmt, err := sc.db.PrepareContext(ctx, statement)
+		return stmt, func() {}, err
+	}
+
+	// statement of the database is held until the bound statement is closed.
+	stmt, release, err := sc.parent.get(ctx, instrumenter, statement)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sc.tx.StmtContext(ctx, stmt), release, nil
+}
+
+func (sc *statementCache) releaser(cs *cachedStatement) func() {
+	var (
+		once sync.Once
+	)
+
+	return func() {
+		once.Do(func() {
+			sc.mutex.Lock()
+			defer sc.mutex.Unlock()
+
+			cs.refs--
+			if cs.evicted && cs.refs == 0 {
+				sc.close(cs)
+			}
+		})
+	}
+}
+
+func (sc *statementCache) evict(elem *list.Element) {
+	var (
+		cs = elem.Value.(*cachedStatement)
+	)
+
+	sc.order.Remove(elem)
+	delete(sc.items, cs.statement)
+
+	cs.evicted = true
+	if cs.refs == 0 {
+		sc.close(cs)
+	}
+}
+
+func (sc *statementCache) close(cs *cachedStatement) {
+	cs.stmt.Close()
+	cs.release()
+}
+
+// reset evicts all cached statements.
+func (sc *statementCache) reset() {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	for sc.order.Len() > 0 {
+		sc.evict(sc.order.Back())
+	}
+}
+
+// len returns number of cached statements.
+func (sc *statementCache) len() int {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	return sc.order.Len()
+}
+
+func newStatementCache(size int, db *sql.DB) *statementCache {
+	return &statementCache{
+		size:  size,
+		db:    db,
+		items: make(map[string]*list.Element, size),
+		order: list.New(),
+	}
+}
+
+func newTxStatementCache(parent *statementCache, tx *sql.Tx) *statementCache {
+	return &statementCache{
+		size:   parent.size,
+		tx:     tx,
+		parent: parent,
+		items:  make(map[string]*list.Element, parent.size),
+		order:  list.New(),
+	}
+}
@@ -0,0 +1,305 @@
+package sql
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+type statementCounter struct {
+	hits   int
+	misses int
+	mutex  sync.Mutex
+}
+
+func (sc *statementCounter) instrumenter(ctx context.Context, op string, message string) func(err error) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	switch op {
+	case "adapter-statement-cache-hit":
+		sc.hits++
+	case "adapter-statement-cache-miss":
+		sc.misses++
+	}
+
+	return func(error) {}
+}
+
+func (sc *statementCounter) assert(t *testing.T, hits int, misses int) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	assert.Equal(t, hits, sc.hits, "hits")
+	assert.Equal(t, misses, sc.misses, "misses")
+}
+
+func openCached(t *testing.T, size int) (*Adapter, rel.Repository, *statementCounter) {
+	var (
+		adapter = open(t)
+		repo    = rel.New(adapter)
+		counter = &statementCounter{}
+	)
+
+	adapter.Config.StatementCacheSize = size
+	repo.Instrumentation(counter.instrumenter)
+
+	return adapter, repo, counter
+}
+
+func TestAdapter_statementCache(t *testing.T) {
+	var (
+		ctx                    = context.TODO()
+		adapter, repo, counter = openCached(t, 10)
+		name                   = Name{Name: "Luffy"}
+		names                  []Name
+	)
+
+	defer adapter.Close()
+
+	// ddl executed using Exec is never prepared.
+	_, _, err := adapter.Exec(ctx, "CREATE TABLE IF NOT EXISTS names (id INTEGER PRIMARY KEY, name STRING);", nil)
+	assert.Nil(t, err)
+	counter.assert(t, 0, 0)
+
+	assert.Nil(t, repo.Insert(ctx, &name))
+	counter.assert(t, 0, 1)
+
+	for i := 0; i < 2; i++ {
+		assert.Nil(t, repo.FindAll(ctx, &names, where.Eq("name", "Luffy")))
+		assert.NotEmpty(t, names)
+	}
+	counter.assert(t, 1, 2)
+
+	for i := 0; i < 2; i++ {
+		count, err := repo.Count(ctx, "names", where.Eq("name", "Luffy"))
+		assert.Nil(t, err)
+		assert.NotZero(t, count)
+	}
+	counter.assert(t, 2, 3)
+
+	name.Name = "Zoro"
+	assert.Nil(t, repo.Update(ctx, &name))
+	assert.Nil(t, repo.Delete(ctx, &name))
+	counter.assert(t, 2, 5)
+	assert.Equal(t, 5, adapter.statementCache().len())
+}
+
+func TestAdapter_QueryRows_statementCache(t *testing.T) {
+	var (
+		ctx                 = context.TODO()
+		adapter, _, counter = openCached(t, 10)
+	)
+
+	defer adapter.Close()
+
+	for i := 0; i < 2; i++ {
+		var (
+			out       int
+			rows, err = adapter.QueryRows(ctx, "SELECT ?;", []interface{}{i})
+		)
+
+		assert.Nil(t, err)
+		assert.True(t, rows.Next())
+		assert.Nil(t, rows.Scan(&out))
+		assert.Equal(t, i, out)
+		assert.Nil(t, rows.Close())
+	}
+
+	counter.assert(t, 1, 1)
+}
+
+func TestAdapter_statementCache_evict(t *testing.T) {
+	var (
+		ctx                 = context.TODO()
+		adapter, _, counter = openCached(t, 1)
+		cache               = adapter.statementCache()
+		out                 int
+	)
+
+	defer adapter.Close()
+
+	stmt, release, err := cache.get(ctx, adapter.Instrumenter, "SELECT 1;")
+	assert.Nil(t, err)
+
+	// evicted, but still in use.
+	_, releaseOther, err := cache.get(ctx, adapter.Instrumenter, "SELECT 2;")
+	assert.Nil(t, err)
+	releaseOther()
+
+	assert.Equal(t, 1, cache.len())
+	assert.Nil(t, stmt.QueryRowContext(ctx).Scan(&out))
+	assert.Equal(t, 1, out)
+
+	release()
+	release()
+	assert.NotNil(t, stmt.QueryRowContext(ctx).Scan(&out))
+
+	counter.assert(t, 0, 2)
+}
+
+func TestAdapter_statementCache_transaction(t *testing.T) {
+	var (
+		ctx                    = context.TODO()
+		adapter, repo, counter = openCached(t, 10)
+		names                  []Name
+	)
+
+	defer adapter.Close()
+
+	assert.Nil(t, repo.FindAll(ctx, &names))
+	counter.assert(t, 0, 1)
+
+	assert.Nil(t, repo.Transaction(ctx, func(ctx context.Context) error {
+		// bound to transaction using the statement of the database.
+		assert.Nil(t, repo.FindAll(ctx, &names))
+		counter.assert(t, 1, 2)
+
+		assert.Nil(t, repo.FindAll(ctx, &names))
+		counter.assert(t, 2, 2)
+
+		return repo.Transaction(ctx, func(ctx context.Context) error {
+			assert.Nil(t, repo.FindAll(ctx, &names))
+			counter.assert(t, 3, 2)
+
+			return repo.Insert(ctx, &Name{Name: "Nami"})
+		})
+	}))
+
+	assert.Nil(t, repo.FindAll(ctx, &names))
+	counter.assert(t, 4, 4)
+	assert.Equal(t, 2, adapter.statementCache().len())
+
+	for _, tx := range []bool{true, false} {
+		txAdapter, err := adapter.Begin(ctx)
+		assert.Nil(t, err)
+
+		txStatements := txAdapter.(*Adapter).txStatements
+		_, err = txAdapter.Query(ctx, rel.From("names"))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, txStatements.len())
+
+		if tx {
+			assert.Nil(t, txAdapter.Commit(ctx))
+		} else {
+			assert.Nil(t, txAdapter.Rollback(ctx))
+		}
+
+		assert.Equal(t, 0, txStatements.len())
+	}
+}
+
+func TestAdapter_statementCache_concurrent(t *testing.T) {
+	var (
+		ctx                    = context.TODO()
+		adapter, repo, counter = openCached(t, 2)
+		wg                     sync.WaitGroup
+	)
+
+	defer adapter.Close()
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			var (
+				names []Name
+			)
+
+			assert.Nil(t, repo.FindAll(ctx, &names, where.Eq("name", strings.Repeat("a", i%4)), rel.Limit(i%3+1)))
+		}(i)
+	}
+
+	wg.Wait()
+
+	counter.mutex.Lock()
+	assert.Equal(t, 20, counter.hits+counter.misses)
+	counter.mutex.Unlock()
+
+	assert.Equal(t, 2, adapter.statementCache().len())
+}
+
+func TestAdapter_statementCache_prepareError(t *testing.T) {
+	var (
+		ctx           = context.TODO()
+		adapter, _, _ = openCached(t, 10)
+	)
+
+	defer adapter.Close()
+
+	_, err := adapter.Query(ctx, rel.From("unknown_table"))
+	assert.NotNil(t, err)
+
+	_, err = adapter.Delete(ctx, rel.From("unknown_table"))
+	assert.NotNil(t, err)
+
+	txAdapter, err := adapter.Begin(ctx)
+	assert.Nil(t, err)
+
+	_, err = txAdapter.Query(ctx, rel.From("unknown_table"))
+	assert.NotNil(t, err)
+	assert.Nil(t, txAdapter.Rollback(ctx))
+
+	assert.Equal(t, 0, adapter.statementCache().len())
+}
+
+func TestAdapter_statementCache_apply(t *testing.T) {
+	var (
+		ctx                    = context.TODO()
+		adapter, repo, counter = openCached(t, 10)
+		names                  []Name
+	)
+
+	defer adapter.Close()
+
+	assert.Nil(t, repo.FindAll(ctx, &names))
+	assert.Equal(t, 1, adapter.statementCache().len())
+
+	// prepared statements might be invalid after schema changes.
+	assert.Nil(t, adapter.Apply(ctx, rel.Raw("CREATE TABLE IF NOT EXISTS apply_names (id INTEGER PRIMARY KEY);")))
+	assert.Equal(t, 0, adapter.statementCache().len())
+
+	assert.Nil(t, repo.FindAll(ctx, &names))
+	counter.assert(t, 0, 2)
+
+	txAdapter, err := adapter.Begin(ctx)
+	assert.Nil(t, err)
+
+	txStatements := txAdapter.(*Adapter).txStatements
+	_, err = txAdapter.Query(ctx, rel.From("names"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, txStatements.len())
+
+	assert.Nil(t, txAdapter.Apply(ctx, rel.Index{Op: rel.SchemaCreate, Table: "apply_names", Name: "apply_names_id", Columns: []string{"id"}}))
+	assert.Equal(t, 0, txStatements.len())
+	assert.Equal(t, 0, adapter.statementCache().len())
+	assert.Nil(t, txAdapter.Rollback(ctx))
+}
+
+func TestAdapter_statementCache_perAdapter(t *testing.T) {
+	var (
+		ctx             = context.TODO()
+		adapter, _, _   = openCached(t, 10)
+		other, _, _     = openCached(t, 10)
+		cache           = adapter.statementCache()
+		otherCache      = other.statementCache()
+		_, release, err = cache.get(ctx, adapter.Instrumenter, "SELECT 1;")
+	)
+
+	defer adapter.Close()
+	defer other.Close()
+
+	assert.Nil(t, err)
+	release()
+
+	assert.NotSame(t, cache, otherCache)
+	assert.Same(t, cache, adapter.statementCache())
+	assert.Equal(t, 1, cache.len())
+	assert.Equal(t, 0, otherCache.len())
+}
@@ -38,7 +38,7 @@ func Escape(config Config, field string) string {
 	if len(field) > 0 && field[0] == UnescapeCharacter {
 		escapedField = field[1:]
 	} else if i := strings.Index(strings.ToLower(field), " as "); i > -1 {
-		escapedField = Escape(config, field[:i]) + " AS " + Escape(config, field[i+4:])
+		escapedField = Escape(config, field[:i]) + " AS " + escapeAlias(config, field[:i], field[i+4:])
 	} else if start, end := strings.IndexRune(field, '('), strings.IndexRune(field, ')'); start >= 0 && end >= 0 && end > start {
 		escapedField = field[:start+1] + Escape(config, field[start+1:end]) + field[end:]
 	} else if strings.HasSuffix(field, "*") {
@@ -53,6 +53,16 @@ func Escape(config Config, field string) string {
 	return escapedField.(string)
 }
 
+// escapeAlias escapes field of joined association aliased as itself (eg: buyer.id AS buyer.id) as a whole,
+// other alias is escaped as usual.
+func escapeAlias(config Config, field string, alias string) string {
+	if field == alias && strings.Contains(alias, ".") && alias[0] != UnescapeCharacter {
+		return config.EscapeChar + alias + config.EscapeChar
+	}
+
+	return Escape(config, alias)
+}
+
 func toInt64(i interface{}) int64 {
 	var result int64
 
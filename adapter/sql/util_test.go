@@ -47,6 +47,22 @@ func TestEscape(t *testing.T) {
 			field:  "user.address as home_address",
 			result: "`user`.`address` AS `home_address`",
 		},
+		{
+			field:  "buyer.id as buyer.id",
+			result: "`buyer`.`id` AS `buyer.id`",
+		},
+		{
+			field:  "id as buyer.id",
+			result: "`id` AS `buyer`.`id`",
+		},
+		{
+			field:  "buyer.id as ^buyer.id",
+			result: "`buyer`.`id` AS buyer.id",
+		},
+		{
+			field:  "buyer.name as buyer_name",
+			result: "`buyer`.`name` AS `buyer_name`",
+		},
 		{
 			field:  "^FIELD(`gender`, \"male\") AS order",
 			result: "FIELD(`gender`, \"male\") AS order",
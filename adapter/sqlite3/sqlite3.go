@@ -13,8 +13,11 @@
 package sqlite3
 
 import (
+	"context"
 	db "database/sql"
+	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/go-rel/rel"
 	"github.com/go-rel/rel/adapter/sql"
@@ -23,6 +26,13 @@ import (
 // Adapter definition for mysql database.
 type Adapter struct {
 	*sql.Adapter
+	returning *returning
+}
+
+// returning caches whether the database supports RETURNING clause, it's shared by transactions of the adapter.
+type returning struct {
+	once      sync.Once
+	supported bool
 }
 
 var (
@@ -33,6 +43,7 @@ var (
 		Placeholder:         "?",
 		EscapeChar:          "`",
 		InsertDefaultValues: true,
+		SubQueryCombine:     true,
 		IncrementFunc:       incrementFunc,
 		ErrorFunc:           errorFunc,
 		MapColumnFunc:       mapColumnFunc,
@@ -46,6 +57,7 @@ func New(database *db.DB) *Adapter {
 			Config: Config,
 			DB:     database,
 		},
+		returning: &returning{},
 	}
 }
 
@@ -55,6 +67,62 @@ func Open(dsn string) (*Adapter, error) {
 	return New(database), err
 }
 
+// SupportReturning returns true when sqlite version of the connection is 3.35 or later.
+// The version is queried once when it's first needed.
+func (a *Adapter) SupportReturning() bool {
+	if a.returning == nil {
+		return a.Adapter.SupportReturning()
+	}
+
+	a.returning.once.Do(func() {
+		if a.Tx != nil {
+			a.returning.supported = supportReturning(a.Tx)
+		} else if a.DB != nil {
+			a.returning.supported = supportReturning(a.DB)
+		}
+	})
+
+	return a.returning.supported
+}
+
+// Begin begins a new transaction.
+func (a *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	newAdapter, err := a.Adapter.Begin(ctx)
+
+	return &Adapter{
+		Adapter:   newAdapter.(*sql.Adapter),
+		returning: a.returning,
+	}, err
+}
+
+type queryer interface {
+	QueryRow(query string, args ...interface{}) *db.Row
+}
+
+func supportReturning(database queryer) bool {
+	var (
+		version string
+	)
+
+	if err := database.QueryRow("SELECT sqlite_version();").Scan(&version); err != nil {
+		return false
+	}
+
+	return versionAtLeast(version, 3, 35)
+}
+
+func versionAtLeast(version string, major int, minor int) bool {
+	var (
+		vMajor, vMinor int
+	)
+
+	if _, err := fmt.Sscanf(version, "%d.%d", &vMajor, &vMinor); err != nil {
+		return false
+	}
+
+	return vMajor > major || (vMajor == major && vMinor >= minor)
+}
+
 func incrementFunc(adapter sql.Adapter) int {
 	// decrement
 	return -1
@@ -7,6 +7,8 @@ import (
 
 	"github.com/go-rel/rel"
 	"github.com/go-rel/rel/adapter/specs"
+	"github.com/go-rel/rel/adapter/sql"
+	"github.com/go-rel/rel/where"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
 )
@@ -61,6 +63,8 @@ func TestAdapter_specs(t *testing.T) {
 	specs.InsertBelongsTo(t, repo)
 	specs.Inserts(t, repo)
 	specs.InsertAll(t, repo)
+	specs.InsertOnConflictIgnore(t, repo)
+	specs.InsertOnConflictReplace(t, repo)
 	// specs.InsertAllPartialCustomPrimary(t, repo) - not supported
 
 	// Update Specs
@@ -114,3 +118,67 @@ func TestAdapter_Exec_error(t *testing.T) {
 	_, _, err = adapter.Exec(ctx, "error", nil)
 	assert.NotNil(t, err)
 }
+
+type combineItem struct {
+	ID int
+}
+
+func TestAdapter_Find_combine(t *testing.T) {
+	adapter, err := Open(dsn())
+	assert.Nil(t, err)
+	defer adapter.Close()
+
+	_, _, err = adapter.Exec(ctx, "CREATE TABLE combine_items (id INTEGER);", nil)
+	assert.Nil(t, err)
+	defer adapter.Exec(ctx, "DROP TABLE combine_items;", nil)
+
+	_, _, err = adapter.Exec(ctx, "INSERT INTO combine_items (id) VALUES (1), (2), (3), (4), (5);", nil)
+	assert.Nil(t, err)
+
+	var (
+		repo  = rel.New(adapter)
+		items []combineItem
+		query = rel.Select("id").Where(where.Eq("id", 1)).
+			Union(rel.Select("id").From("combine_items").SortDesc("id").Limit(2)).
+			SortAsc("id")
+	)
+
+	assert.Nil(t, repo.FindAll(ctx, &items, query))
+	assert.Equal(t, []combineItem{{ID: 1}, {ID: 4}, {ID: 5}}, items)
+}
+
+func TestSupportReturning(t *testing.T) {
+	adapter, err := Open(dsn())
+	assert.Nil(t, err)
+	defer adapter.Close()
+
+	var version string
+	assert.Nil(t, adapter.DB.QueryRow("SELECT sqlite_version();").Scan(&version))
+
+	// detected inside transaction and shared with the adapter.
+	txAdapter, err := adapter.Begin(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, versionAtLeast(version, 3, 35), txAdapter.(*Adapter).SupportReturning())
+	assert.Nil(t, txAdapter.Rollback(ctx))
+
+	assert.Equal(t, versionAtLeast(version, 3, 35), adapter.SupportReturning())
+}
+
+func TestSupportReturning_closed(t *testing.T) {
+	adapter, err := Open(dsn())
+	assert.Nil(t, err)
+
+	// no query is executed until it's needed.
+	assert.Nil(t, adapter.Close())
+	assert.False(t, adapter.SupportReturning())
+	assert.False(t, (&Adapter{Adapter: &sql.Adapter{}}).SupportReturning())
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	assert.True(t, versionAtLeast("3.35.0", 3, 35))
+	assert.True(t, versionAtLeast("3.40.1", 3, 35))
+	assert.True(t, versionAtLeast("4.0.0", 3, 35))
+	assert.False(t, versionAtLeast("3.33.0", 3, 35))
+	assert.False(t, versionAtLeast("2.40.0", 3, 35))
+	assert.False(t, versionAtLeast("invalid", 3, 35))
+}
@@ -8,10 +8,11 @@ import (
 
 type testAdapter struct {
 	mock.Mock
-	result interface{}
+	result    interface{}
+	returning bool
 }
 
-var _ Adapter = (*testAdapter)(nil)
+var _ ReturningAdapter = (*testAdapter)(nil)
 
 func (ta *testAdapter) Open(dsn string) error {
 	args := ta.Called(dsn)
@@ -41,13 +42,13 @@ func (ta *testAdapter) Query(ctx context.Context, query Query) (Cursor, error) {
 	return args.Get(0).(Cursor), args.Error(1)
 }
 
-func (ta *testAdapter) Insert(ctx context.Context, query Query, primaryField string, mutates map[string]Mutate) (interface{}, error) {
-	args := ta.Called(query, mutates)
+func (ta *testAdapter) Insert(ctx context.Context, query Query, primaryFields []string, mutates map[string]Mutate, onConflict OnConflict) (interface{}, error) {
+	args := ta.Called(query, mutates, onConflict)
 	return args.Get(0), args.Error(1)
 }
 
-func (ta *testAdapter) InsertAll(ctx context.Context, query Query, primaryField string, fields []string, mutates []map[string]Mutate) ([]interface{}, error) {
-	args := ta.Called(query, fields, mutates)
+func (ta *testAdapter) InsertAll(ctx context.Context, query Query, primaryFields []string, fields []string, mutates []map[string]Mutate, onConflict OnConflict) ([]interface{}, error) {
+	args := ta.Called(query, fields, mutates, onConflict)
 	return args.Get(0).([]interface{}), args.Error(1)
 }
 
@@ -56,6 +57,20 @@ func (ta *testAdapter) Update(ctx context.Context, query Query, mutates map[stri
 	return args.Int(0), args.Error(1)
 }
 
+func (ta *testAdapter) SupportReturning() bool {
+	return ta.returning
+}
+
+func (ta *testAdapter) InsertReturning(ctx context.Context, query Query, mutates map[string]Mutate, onConflict OnConflict) (Cursor, error) {
+	args := ta.Called(query, mutates, onConflict)
+	return args.Get(0).(Cursor), args.Error(1)
+}
+
+func (ta *testAdapter) UpdateReturning(ctx context.Context, query Query, mutates map[string]Mutate) (Cursor, error) {
+	args := ta.Called(query, mutates)
+	return args.Get(0).(Cursor), args.Error(1)
+}
+
 func (ta *testAdapter) Delete(ctx context.Context, query Query) (int, error) {
 	args := ta.Called(query)
 	return args.Int(0), args.Error(1)
@@ -1,7 +1,9 @@
 package rel
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 
 	"github.com/serenize/snaker"
@@ -25,14 +27,20 @@ type associationKey struct {
 }
 
 type associationData struct {
-	typ            AssociationType
-	targetIndex    []int
-	referenceField string
-	referenceIndex int
-	foreignField   string
-	foreignIndex   int
-	through        string
-	autosave       bool
+	typ              AssociationType
+	targetIndex      []int
+	referenceField   string
+	referenceIndex   int
+	referenceThrough string
+	foreignField     string
+	foreignIndex     int
+	foreignThrough   string
+	through          string
+	throughTable     string
+	polymorphicType  string
+	polymorphicIndex int
+	polymorphicValue string
+	autosave         bool
 }
 
 var associationCache sync.Map
@@ -100,6 +108,21 @@ func (a Association) Collection() (*Collection, bool) {
 	return NewCollection(rv.Addr()), loaded
 }
 
+// unsetZero reverts pointer to association back to nil when the association is not persisted.
+func (a Association) unsetZero() {
+	var (
+		rv = a.rv.FieldByIndex(a.data.targetIndex)
+	)
+
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return
+	}
+
+	if _, loaded := a.Document(); !loaded {
+		rv.Set(reflect.Zero(rv.Type()))
+	}
+}
+
 // IsZero returns true if association is not loaded.
 func (a Association) IsZero() bool {
 	var (
@@ -147,6 +170,58 @@ func (a Association) Through() string {
 	return a.data.through
 }
 
+// ThroughTable returns join table of many to many association.
+func (a Association) ThroughTable() string {
+	return a.data.throughTable
+}
+
+// ReferenceThrough returns join table field that refers to reference field.
+func (a Association) ReferenceThrough() string {
+	return a.data.referenceThrough
+}
+
+// ForeignThrough returns join table field that refers to foreign field.
+func (a Association) ForeignThrough() string {
+	return a.data.foreignThrough
+}
+
+// PolymorphicType returns field that stores the type of polymorphic association.
+func (a Association) PolymorphicType() string {
+	return a.data.polymorphicType
+}
+
+// PolymorphicValue returns the type stored by polymorphic association.
+// For belongs to association, it's the table of the target, otherwise it's the table of the owner.
+func (a Association) PolymorphicValue() string {
+	return a.data.polymorphicValue
+}
+
+// polymorphicMatch returns false if the type of polymorphic belongs to association refers to other table.
+func (a Association) polymorphicMatch() bool {
+	if a.data.polymorphicType == "" || a.data.typ != BelongsTo {
+		return true
+	}
+
+	return fmt.Sprint(indirect(a.rv.Field(a.data.polymorphicIndex))) == a.data.polymorphicValue
+}
+
+func (a Association) foreignType() reflect.Type {
+	var (
+		rt = a.rv.Type().FieldByIndex(a.data.targetIndex).Type
+	)
+
+	for rt.Kind() == reflect.Ptr || rt.Kind() == reflect.Slice {
+		rt = rt.Elem()
+	}
+
+	rt = rt.Field(a.data.foreignIndex).Type
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	return rt
+}
+
 // Autosave setting when parent is created/updated/deleted.
 func (a Association) Autosave() bool {
 	return a.data.autosave
@@ -188,10 +263,6 @@ func extractAssociationData(rt reflect.Type, index int) associationData {
 		}
 	)
 
-	if assocData.autosave && assocData.through != "" {
-		panic("rel: autosave is not supported for has one/has many through association")
-	}
-
 	for ft.Kind() == reflect.Ptr || ft.Kind() == reflect.Slice {
 		ft = ft.Elem()
 	}
@@ -202,12 +273,13 @@ func extractAssociationData(rt reflect.Type, index int) associationData {
 	)
 
 	// Try to guess ref and fk if not defined.
-	if ref == "" || fk == "" {
+	if polymorphic := sf.Tag.Get("polymorphic"); polymorphic != "" {
+		ref, fk = extractPolymorphicData(rt, ft, refDocData, fkDocData, &assocData, polymorphic)
+	} else if assocData.through != "" {
+		ref, fk = extractThroughData(rt, ft, refDocData, &assocData, ref, fk)
+	} else if ref == "" || fk == "" {
 		// TODO: replace "id" with inferred primary field
-		if assocData.through != "" {
-			ref = "id"
-			fk = "id"
-		} else if _, isBelongsTo := refDocData.index[fName+"_id"]; isBelongsTo {
+		if _, isBelongsTo := refDocData.index[fName+"_id"]; isBelongsTo {
 			ref = fName + "_id"
 			fk = "id"
 		} else {
@@ -245,3 +317,107 @@ func extractAssociationData(rt reflect.Type, index int) associationData {
 
 	return assocData
 }
+
+// extractThroughData resolves join table and its fields of many to many association.
+// Through can either refers to intermediary has many association or the join table name.
+// Join table fields can be defined explicitly using ref and fk tag:
+//
+//	Roles []Role `through:"user_roles" ref:"id:user_id" fk:"role_id:id"`
+func extractThroughData(rt reflect.Type, ft reflect.Type, refDocData documentData, assocData *associationData, ref string, fk string) (string, string) {
+	if i := strings.IndexByte(ref, ':'); i >= 0 {
+		ref, assocData.referenceThrough = ref[:i], ref[i+1:]
+	}
+
+	if i := strings.IndexByte(fk, ':'); i >= 0 {
+		assocData.foreignThrough, fk = fk[:i], fk[i+1:]
+	}
+
+	// TODO: replace "id" with inferred primary field
+	if ref == "" {
+		ref = "id"
+	}
+
+	if fk == "" {
+		fk = "id"
+	}
+
+	if index, exist := refDocData.index[assocData.through]; exist {
+		var (
+			jt    = rt.Field(index).Type
+			jData = extractAssociationData(rt, index)
+		)
+
+		for jt.Kind() == reflect.Ptr || jt.Kind() == reflect.Slice {
+			jt = jt.Elem()
+		}
+
+		var (
+			jPrimaries, _ = searchPrimary(jt)
+		)
+
+		assocData.throughTable = associationTable(jt)
+
+		if assocData.referenceThrough == "" {
+			assocData.referenceThrough = jData.foreignField
+		}
+
+		// join table with composite primary key uses the other primary field to refer the target.
+		if assocData.foreignThrough == "" && len(jPrimaries) == 2 {
+			if jPrimaries[0] == assocData.referenceThrough {
+				assocData.foreignThrough = jPrimaries[1]
+			} else {
+				assocData.foreignThrough = jPrimaries[0]
+			}
+		}
+	} else {
+		assocData.throughTable = assocData.through
+	}
+
+	if assocData.referenceThrough == "" {
+		assocData.referenceThrough = snaker.CamelToSnake(rt.Name()) + "_id"
+	}
+
+	if assocData.foreignThrough == "" {
+		assocData.foreignThrough = snaker.CamelToSnake(ft.Name()) + "_id"
+	}
+
+	return ref, fk
+}
+
+// extractPolymorphicData resolves type field of polymorphic association.
+// Polymorphic association uses pair of <name>_type and <name>_id field to refers to multiple tables:
+//
+//	Comments []Comment `polymorphic:"owner"`
+//	Post     *Post     `polymorphic:"owner"`
+func extractPolymorphicData(rt reflect.Type, ft reflect.Type, refDocData documentData, fkDocData documentData, assocData *associationData, polymorphic string) (string, string) {
+	var (
+		typeField = polymorphic + "_type"
+		idField   = polymorphic + "_id"
+	)
+
+	assocData.polymorphicType = typeField
+
+	if index, isBelongsTo := refDocData.index[typeField]; isBelongsTo {
+		assocData.polymorphicIndex = index
+		assocData.polymorphicValue = associationTable(ft)
+
+		return idField, "id"
+	}
+
+	if index, exist := fkDocData.index[typeField]; !exist {
+		panic("rel: polymorphic type (" + typeField + ") field not found")
+	} else {
+		assocData.polymorphicIndex = index
+		assocData.polymorphicValue = associationTable(rt)
+	}
+
+	return "id", idField
+}
+
+func associationTable(rt reflect.Type) string {
+	if reflect.PtrTo(rt).Implements(rtTable) {
+		return reflect.New(rt).Interface().(table).Table()
+	}
+
+	return tableName(rt)
+}
@@ -169,6 +169,7 @@ func TestAssociation_Collection(t *testing.T) {
 		foreignValue     interface{}
 		foreignThrough   string
 		through          string
+		throughTable     string
 	}{
 		{
 			record:         "User",
@@ -197,60 +198,72 @@ func TestAssociation_Collection(t *testing.T) {
 			foreignValue:   nil,
 		},
 		{
-			record:         "User",
-			field:          "Roles",
-			data:           user,
-			typ:            HasMany,
-			col:            NewCollection(&user.Roles),
-			loaded:         false,
-			isZero:         true,
-			referenceField: "id",
-			referenceValue: user.ID,
-			foreignField:   "id",
-			foreignValue:   nil,
-			through:        "user_roles",
+			record:           "User",
+			field:            "Roles",
+			data:             user,
+			typ:              HasMany,
+			col:              NewCollection(&user.Roles),
+			loaded:           false,
+			isZero:           true,
+			referenceField:   "id",
+			referenceValue:   user.ID,
+			foreignField:     "id",
+			foreignValue:     nil,
+			through:          "user_roles",
+			throughTable:     "user_roles",
+			referenceThrough: "user_id",
+			foreignThrough:   "role_id",
 		},
 		{
-			record:         "Role",
-			field:          "Users",
-			data:           role,
-			typ:            HasMany,
-			col:            NewCollection(&role.Users),
-			loaded:         false,
-			isZero:         true,
-			referenceField: "id",
-			referenceValue: role.ID,
-			foreignField:   "id",
-			foreignValue:   nil,
-			through:        "user_roles",
+			record:           "Role",
+			field:            "Users",
+			data:             role,
+			typ:              HasMany,
+			col:              NewCollection(&role.Users),
+			loaded:           false,
+			isZero:           true,
+			referenceField:   "id",
+			referenceValue:   role.ID,
+			foreignField:     "id",
+			foreignValue:     nil,
+			through:          "user_roles",
+			throughTable:     "user_roles",
+			referenceThrough: "role_id",
+			foreignThrough:   "user_id",
 		},
 		{
-			record:         "User",
-			field:          "Followers",
-			data:           user,
-			typ:            HasMany,
-			col:            NewCollection(&user.Followers),
-			loaded:         false,
-			isZero:         true,
-			referenceField: "id",
-			referenceValue: user.ID,
-			foreignField:   "id",
-			foreignValue:   nil,
-			through:        "followeds",
+			record:           "User",
+			field:            "Followers",
+			data:             user,
+			typ:              HasMany,
+			col:              NewCollection(&user.Followers),
+			loaded:           false,
+			isZero:           true,
+			referenceField:   "id",
+			referenceValue:   user.ID,
+			foreignField:     "id",
+			foreignValue:     nil,
+			through:          "followeds",
+			throughTable:     "follows",
+			referenceThrough: "follower_id",
+			foreignThrough:   "following_id",
 		},
 		{
-			record:         "User",
-			field:          "Followings",
-			data:           user,
-			typ:            HasMany,
-			col:            NewCollection(&user.Followings),
-			loaded:         false,
-			isZero:         true,
-			referenceField: "id",
-			referenceValue: user.ID,
-			foreignField:   "id",
-			foreignValue:   nil,
-			through:        "follows",
+			record:           "User",
+			field:            "Followings",
+			data:             user,
+			typ:              HasMany,
+			col:              NewCollection(&user.Followings),
+			loaded:           false,
+			isZero:           true,
+			referenceField:   "id",
+			referenceValue:   user.ID,
+			foreignField:     "id",
+			foreignValue:     nil,
+			through:          "follows",
+			throughTable:     "follows",
+			referenceThrough: "following_id",
+			foreignThrough:   "follower_id",
 		},
 	}
 
@@ -271,6 +284,9 @@ func TestAssociation_Collection(t *testing.T) {
 			assert.Equal(t, test.referenceValue, assoc.ReferenceValue())
 			assert.Equal(t, test.foreignField, assoc.ForeignField())
 			assert.Equal(t, test.through, assoc.Through())
+			assert.Equal(t, test.throughTable, assoc.ThroughTable())
+			assert.Equal(t, test.referenceThrough, assoc.ReferenceThrough())
+			assert.Equal(t, test.foreignThrough, assoc.ForeignThrough())
 
 			if test.typ == HasMany {
 				assert.Panics(t, func() {
@@ -285,17 +301,158 @@ func TestAssociation_Collection(t *testing.T) {
 	}
 }
 
-func TestAssociation_autosaveWithThrough(t *testing.T) {
-	type Alpha struct {
+func TestAssociation_throughTable(t *testing.T) {
+	type Label struct {
 		ID int
 	}
 
+	type Note struct {
+		ID       int
+		Labels   []Label `through:"note_labels" autosave:"true"`
+		Tags     []Label `through:"note_tags" ref:"id:owner_id" fk:"tag_id:id"`
+		Keywords []Label `through:"note_keywords" ref:"id:note_id"`
+	}
+
+	var (
+		doc = NewDocument(&Note{ID: 1})
+	)
+
+	tests := []struct {
+		field            string
+		throughTable     string
+		referenceThrough string
+		foreignThrough   string
+	}{
+		{
+			field:            "labels",
+			throughTable:     "note_labels",
+			referenceThrough: "note_id",
+			foreignThrough:   "label_id",
+		},
+		{
+			field:            "tags",
+			throughTable:     "note_tags",
+			referenceThrough: "owner_id",
+			foreignThrough:   "tag_id",
+		},
+		{
+			field:            "keywords",
+			throughTable:     "note_keywords",
+			referenceThrough: "note_id",
+			foreignThrough:   "label_id",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.field, func(t *testing.T) {
+			var (
+				assoc = doc.Association(test.field)
+			)
+
+			assert.Equal(t, "id", assoc.ReferenceField())
+			assert.Equal(t, "id", assoc.ForeignField())
+			assert.Equal(t, test.throughTable, assoc.ThroughTable())
+			assert.Equal(t, test.referenceThrough, assoc.ReferenceThrough())
+			assert.Equal(t, test.foreignThrough, assoc.ForeignThrough())
+		})
+	}
+
+	assert.True(t, doc.Association("labels").Autosave())
+}
+
+func TestAssociation_polymorphic(t *testing.T) {
+	var (
+		video   = &Video{ID: 1}
+		comment = &Comment{ID: 2, OwnerType: "videos", OwnerID: 1}
+	)
+
+	tests := []struct {
+		record           string
+		field            string
+		data             interface{}
+		typ              AssociationType
+		referenceField   string
+		foreignField     string
+		polymorphicType  string
+		polymorphicValue string
+		polymorphicMatch bool
+	}{
+		{
+			record:           "Video",
+			field:            "Comments",
+			data:             video,
+			typ:              HasMany,
+			referenceField:   "id",
+			foreignField:     "owner_id",
+			polymorphicType:  "owner_type",
+			polymorphicValue: "videos",
+			polymorphicMatch: true,
+		},
+		{
+			record:           "Video",
+			field:            "Thumbnail",
+			data:             video,
+			typ:              HasOne,
+			referenceField:   "id",
+			foreignField:     "attachable_id",
+			polymorphicType:  "attachable_type",
+			polymorphicValue: "videos",
+			polymorphicMatch: true,
+		},
+		{
+			record:           "Comment",
+			field:            "Video",
+			data:             comment,
+			typ:              BelongsTo,
+			referenceField:   "owner_id",
+			foreignField:     "id",
+			polymorphicType:  "owner_type",
+			polymorphicValue: "videos",
+			polymorphicMatch: true,
+		},
+		{
+			record:           "Comment",
+			field:            "Photo",
+			data:             comment,
+			typ:              BelongsTo,
+			referenceField:   "owner_id",
+			foreignField:     "id",
+			polymorphicType:  "owner_type",
+			polymorphicValue: "photos",
+			polymorphicMatch: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.record+"."+test.field, func(t *testing.T) {
+			var (
+				rv    = reflect.ValueOf(test.data)
+				sf, _ = rv.Type().Elem().FieldByName(test.field)
+				assoc = newAssociation(rv, sf.Index[0])
+			)
+
+			assert.Equal(t, test.typ, assoc.Type())
+			assert.Equal(t, test.referenceField, assoc.ReferenceField())
+			assert.Equal(t, test.foreignField, assoc.ForeignField())
+			assert.Equal(t, test.polymorphicType, assoc.PolymorphicType())
+			assert.Equal(t, test.polymorphicValue, assoc.PolymorphicValue())
+			assert.Equal(t, test.polymorphicMatch, assoc.polymorphicMatch())
+		})
+	}
+}
+
+func TestAssociation_polymorphicTypeNotFound(t *testing.T) {
+	type Alpha struct {
+		ID      int
+		OwnerID int
+	}
+
 	type Beta struct {
-		ID    int
-		Alpha Alpha `through:"other" autosave:"true"`
+		ID     int
+		Alphas []Alpha `polymorphic:"owner"`
 	}
 
-	assert.Panics(t, func() {
+	assert.PanicsWithValue(t, "rel: polymorphic type (owner_type) field not found", func() {
 		NewDocument(&Beta{})
 	})
 }
@@ -1,6 +1,7 @@
 package rel
 
 import (
+	"errors"
 	"reflect"
 	"time"
 )
@@ -11,10 +12,12 @@ type pair = [2]interface{}
 // This allows REL to efficiently to perform update operation only on updated fields and association.
 // The catch is, enabling changeset will duplicates the original struct values which consumes more memory.
 type Changeset struct {
-	doc       *Document
-	snapshot  []interface{}
-	assoc     map[string]Changeset
-	assocMany map[string]map[interface{}]Changeset
+	doc         *Document
+	snapshot    []interface{}
+	assoc       map[string]Changeset
+	assocMany   map[string]map[interface{}]Changeset
+	validators  []Validator
+	constraints []constraint
 }
 
 func (c Changeset) valueChanged(typ reflect.Type, old interface{}, new interface{}) bool {
@@ -52,12 +55,81 @@ func (c Changeset) Changes() map[string]interface{} {
 	return buildChanges(c.doc, c)
 }
 
+// Validate adds validators that will be run when the record is inserted or updated.
+// Validators run after before hooks, so fields set by the hooks are validated as well.
+// Use ValidateAssoc to validate associated records.
+//
+//	ch = ch.Validate(
+//		rel.ValidateRequired("name", "email"),
+//		rel.ValidateFormat("email", emailRegexp),
+//	)
+func (c Changeset) Validate(validators ...Validator) Changeset {
+	c.validators = append(c.validators[:len(c.validators):len(c.validators)], validators...)
+	return c
+}
+
+// UniqueConstraint translates unique constraint error of the field into validation error.
+// Constraint error is matched when its key contains the field name delimited by non alphanumeric characters (eg: users_email_key),
+// or equals to the given key.
+func (c Changeset) UniqueConstraint(field string, key ...string) Changeset {
+	return c.addConstraint(field, key, UniqueConstraint, "has already been taken")
+}
+
+// ForeignKeyConstraint translates foreign key constraint error of the field into validation error.
+// Constraint error is matched when its key contains the field name delimited by non alphanumeric characters (eg: users_email_key),
+// or equals to the given key.
+func (c Changeset) ForeignKeyConstraint(field string, key ...string) Changeset {
+	return c.addConstraint(field, key, ForeignKeyConstraint, "does not exist")
+}
+
+func (c Changeset) addConstraint(field string, key []string, typ ConstraintType, message string) Changeset {
+	cons := constraint{field: field, typ: typ, message: message}
+	if len(key) > 0 {
+		cons.key = key[0]
+	}
+
+	c.constraints = append(c.constraints[:len(c.constraints):len(c.constraints)], cons)
+	return c
+}
+
+// Check runs validators and returns ValidationError if the record is invalid.
+func (c Changeset) Check() error {
+	return validate(c.doc, c.validators)
+}
+
+func (c Changeset) transformError(err error) error {
+	var (
+		ce ConstraintError
+	)
+
+	if !errors.As(err, &ce) {
+		return err
+	}
+
+	for _, cons := range c.constraints {
+		if cons.match(ce) {
+			return ValidationError{Errors: []FieldError{{Field: cons.field, Message: cons.message}}}
+		}
+	}
+
+	return err
+}
+
 // Apply mutation.
+// Validators are stored in the mutation and run when the mutation is executed.
 func (c Changeset) Apply(doc *Document, mut *Mutation) {
 	var (
 		t = now().Truncate(time.Second)
 	)
 
+	if len(c.validators) > 0 {
+		mut.validators = append(mut.validators[:len(mut.validators):len(mut.validators)], c.validators...)
+	}
+
+	if len(c.constraints) > 0 {
+		mut.constraintFunc = ErrorFunc(c.transformError).chain(mut.constraintFunc)
+	}
+
 	for i, field := range c.doc.Fields() {
 		var (
 			typ, _ = c.doc.Type(field)
@@ -146,6 +218,7 @@ func (c Changeset) applyAssocMany(field string, mut *Mutation) {
 		if len(muts) > 0 || len(deletedIDs) > 0 {
 			mut.SetAssoc(field, muts...)
 			mut.SetDeletedIDs(field, deletedIDs)
+			mut.SetTracked(field)
 		}
 	} else {
 		newStructset(c.doc, false).buildAssocMany(field, mut)
@@ -1,6 +1,7 @@
 package rel
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -522,8 +523,110 @@ func TestChangeset_hasMany(t *testing.T) {
 						},
 					},
 					DeletedIDs: []interface{}{12},
+					Tracked:    true,
 				},
 			},
 		}, Apply(doc, changeset))
 	})
 }
+
+func TestChangeset_Validate(t *testing.T) {
+	var (
+		user      = User{Name: "User 1"}
+		doc       = NewDocument(&user)
+		changeset = NewChangeset(&user).Validate(
+			ValidateRequired("name"),
+			ValidateLength("name", 3, 10),
+		)
+	)
+
+	t.Run("valid", func(t *testing.T) {
+		assert.Nil(t, changeset.Check())
+		assert.Nil(t, validate(doc, Apply(doc, changeset).validators))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		user.Name = "Valid User Name"
+		err := ValidationError{
+			Errors: []FieldError{{Field: "name", Message: "length must be at most 10"}},
+		}
+
+		assert.Equal(t, err, changeset.Check())
+		assert.Equal(t, err, validate(doc, Apply(doc, changeset).validators))
+	})
+
+	t.Run("immutable", func(t *testing.T) {
+		var (
+			base    = NewChangeset(&user).Validate(ValidateRequired("name"))
+			derived = base.Validate(ValidateRequired("age"))
+		)
+
+		base.Validate(ValidateRange("age", 1, 100))
+		assert.Len(t, base.validators, 1)
+		assert.Len(t, derived.validators, 2)
+	})
+}
+
+func TestChangeset_constraint(t *testing.T) {
+	var (
+		user      = User{Name: "User 1"}
+		doc       = NewDocument(&user)
+		changeset = NewChangeset(&user).
+				UniqueConstraint("name").
+				ForeignKeyConstraint("address_id", "fk_address")
+		mutation = Apply(doc, changeset)
+	)
+
+	tests := []struct {
+		name   string
+		err    error
+		result error
+	}{
+		{
+			name:   "unique",
+			err:    ConstraintError{Key: "users_name_key", Type: UniqueConstraint},
+			result: ValidationError{Errors: []FieldError{{Field: "name", Message: "has already been taken"}}},
+		},
+		{
+			name:   "foreign key",
+			err:    ConstraintError{Key: "fk_address", Type: ForeignKeyConstraint},
+			result: ValidationError{Errors: []FieldError{{Field: "address_id", Message: "does not exist"}}},
+		},
+		{
+			name:   "not matched",
+			err:    ConstraintError{Key: "users_email_key", Type: UniqueConstraint},
+			result: ConstraintError{Key: "users_email_key", Type: UniqueConstraint},
+		},
+		{
+			name:   "other error",
+			err:    errors.New("error"),
+			result: errors.New("error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.result, mutation.transformError(test.err))
+		})
+	}
+}
+
+func TestChangeset_constraintWithErrorFunc(t *testing.T) {
+	var (
+		user      = User{Name: "User 1"}
+		doc       = NewDocument(&user)
+		changeset = NewChangeset(&user).UniqueConstraint("name")
+		errorFunc = ErrorFunc(func(err error) error {
+			if _, ok := err.(ValidationError); ok {
+				return errors.New("invalid")
+			}
+
+			return err
+		})
+		err = ConstraintError{Key: "users_name_key", Type: UniqueConstraint}
+	)
+
+	// constraint is always translated before custom error func regardless of the order.
+	assert.Equal(t, errors.New("invalid"), Apply(doc, changeset, errorFunc).transformError(err))
+	assert.Equal(t, errors.New("invalid"), Apply(doc, errorFunc, changeset).transformError(err))
+}
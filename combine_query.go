@@ -0,0 +1,44 @@
+package rel
+
+// CombineQuery defines set operation that combines result of the query with another query.
+type CombineQuery struct {
+	Op    string
+	Query Query
+}
+
+// Build query.
+func (cq CombineQuery) Build(query *Query) {
+	query.CombineQuery = append(query.CombineQuery, cq)
+}
+
+// NewUnion combines result with another query, removing duplicate rows.
+func NewUnion(query Query) CombineQuery {
+	return CombineQuery{
+		Op:    "UNION",
+		Query: query,
+	}
+}
+
+// NewUnionAll combines result with another query, including duplicate rows.
+func NewUnionAll(query Query) CombineQuery {
+	return CombineQuery{
+		Op:    "UNION ALL",
+		Query: query,
+	}
+}
+
+// NewIntersect keeps rows that are also returned by another query.
+func NewIntersect(query Query) CombineQuery {
+	return CombineQuery{
+		Op:    "INTERSECT",
+		Query: query,
+	}
+}
+
+// NewExcept removes rows that are returned by another query.
+func NewExcept(query Query) CombineQuery {
+	return CombineQuery{
+		Op:    "EXCEPT",
+		Query: query,
+	}
+}
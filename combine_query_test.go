@@ -0,0 +1,30 @@
+package rel_test
+
+import (
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombineQuery(t *testing.T) {
+	var (
+		query = rel.Select("id", "title").From("photos")
+	)
+
+	tests := []struct {
+		op      string
+		combine rel.CombineQuery
+	}{
+		{"UNION", rel.NewUnion(query)},
+		{"UNION ALL", rel.NewUnionAll(query)},
+		{"INTERSECT", rel.NewIntersect(query)},
+		{"EXCEPT", rel.NewExcept(query)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.op, func(t *testing.T) {
+			assert.Equal(t, rel.CombineQuery{Op: test.op, Query: query}, test.combine)
+		})
+	}
+}
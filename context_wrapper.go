@@ -9,9 +9,14 @@ type contextKey int8
 type contextWrapper struct {
 	ctx     context.Context
 	adapter Adapter
+	tenant  interface{}
 }
 
-var ctxKey contextKey
+var (
+	ctxKey     contextKey
+	tenantKey  contextKey = 1
+	primaryKey contextKey = 2
+)
 
 // fetchContext and use adapter passed by context if exists.
 // it stores contextData values to struct for fast repeated access.
@@ -23,6 +28,7 @@ func fetchContext(ctx context.Context, adapter Adapter) contextWrapper {
 	return contextWrapper{
 		ctx:     ctx,
 		adapter: adapter,
+		tenant:  ctx.Value(tenantKey),
 	}
 }
 
@@ -31,5 +37,6 @@ func wrapContext(ctx context.Context, adapter Adapter) contextWrapper {
 	return contextWrapper{
 		ctx:     context.WithValue(ctx, ctxKey, adapter),
 		adapter: adapter,
+		tenant:  ctx.Value(tenantKey),
 	}
 }
@@ -34,4 +34,13 @@ func TestContextWrapper(t *testing.T) {
 		assert.Equal(t, ctx, cw.ctx)
 		assert.Equal(t, adapter, cw.adapter)
 	})
+
+	t.Run("fetch tenant", func(t *testing.T) {
+		ctx = WithTenant(ctx, 1)
+		cw = fetchContext(ctx, adapter)
+		assert.Equal(t, 1, cw.tenant)
+
+		cw = wrapContext(ctx, adapter)
+		assert.Equal(t, 1, cw.tenant)
+	})
 }
@@ -1,7 +1,6 @@
 package rel
 
 import (
-	"database/sql"
 	"reflect"
 )
 
@@ -28,9 +27,15 @@ func scanOne(cur Cursor, doc *Document) error {
 
 	var (
 		scanners = doc.Scanners(fields)
+		joined   = joinedAssocs(doc.data, fields)
 	)
 
-	return cur.Scan(scanners...)
+	if err := cur.Scan(scanners...); err != nil {
+		return err
+	}
+
+	unsetJoinedAssocs(doc, joined)
+	return nil
 }
 
 func scanAll(cur Cursor, col *Collection) error {
@@ -41,6 +46,10 @@ func scanAll(cur Cursor, col *Collection) error {
 		return err
 	}
 
+	var (
+		joined = joinedAssocs(col.data, fields)
+	)
+
 	for cur.Next() {
 		var (
 			doc      = col.Add()
@@ -50,11 +59,172 @@ func scanAll(cur Cursor, col *Collection) error {
 		if err := cur.Scan(scanners...); err != nil {
 			return err
 		}
+
+		unsetJoinedAssocs(doc, joined)
 	}
 
 	return nil
 }
 
+// scanValue scans the first column of the first row into dest.
+func scanValue(cur Cursor, dest interface{}) error {
+	defer cur.Close()
+
+	if !cur.Next() {
+		return NotFoundError{}
+	}
+
+	return cur.Scan(Nullable(dest))
+}
+
+// scanRecords scans rows into a struct, map[string]interface{} or scalar value, or a slice of them.
+// Unlike scanOne and scanAll, the struct doesn't need to be a record with table and primary key.
+func scanRecords(cur Cursor, result interface{}) error {
+	switch v := result.(type) {
+	case *map[string]interface{}:
+		return scanMap(cur, v)
+	case *[]map[string]interface{}:
+		return scanMaps(cur, v)
+	}
+
+	var (
+		rt = reflect.TypeOf(result)
+	)
+
+	if rt == nil || rt.Kind() != reflect.Ptr {
+		panic("rel: result must be a pointer")
+	}
+
+	if rt = rt.Elem(); rt.Kind() == reflect.Slice && rt != rtBytes {
+		if isScalar(rt.Elem()) {
+			return scanValues(cur, reflect.ValueOf(result).Elem())
+		}
+
+		var (
+			col = NewCollection(result)
+		)
+
+		col.Reset()
+		return scanAll(cur, col)
+	}
+
+	if isScalar(rt) {
+		return scanValue(cur, result)
+	}
+
+	return scanOne(cur, NewDocument(result))
+}
+
+func scanMap(cur Cursor, m *map[string]interface{}) error {
+	var (
+		maps []map[string]interface{}
+	)
+
+	if err := scanMaps(cur, &maps); err != nil {
+		return err
+	}
+
+	if len(maps) == 0 {
+		return NotFoundError{}
+	}
+
+	*m = maps[0]
+	return nil
+}
+
+func scanMaps(cur Cursor, maps *[]map[string]interface{}) error {
+	defer cur.Close()
+
+	fields, err := cur.Fields()
+	if err != nil {
+		return err
+	}
+
+	var (
+		values   = make([]interface{}, len(fields))
+		scanners = make([]interface{}, len(fields))
+	)
+
+	for i := range values {
+		scanners[i] = &values[i]
+	}
+
+	*maps = (*maps)[:0]
+	for cur.Next() {
+		if err := cur.Scan(scanners...); err != nil {
+			return err
+		}
+
+		var (
+			row = make(map[string]interface{}, len(fields))
+		)
+
+		for i, field := range fields {
+			row[field] = values[i]
+		}
+
+		*maps = append(*maps, row)
+	}
+
+	return nil
+}
+
+func scanValues(cur Cursor, rv reflect.Value) error {
+	defer cur.Close()
+
+	rv.Set(rv.Slice(0, 0))
+	for cur.Next() {
+		var (
+			value = reflect.New(rv.Type().Elem())
+		)
+
+		if err := cur.Scan(Nullable(value.Interface())); err != nil {
+			return err
+		}
+
+		rv.Set(reflect.Append(rv, value.Elem()))
+	}
+
+	return nil
+}
+
+// isScalar returns true if values of the type is scanned from a single column.
+func isScalar(rt reflect.Type) bool {
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	if rt.Kind() != reflect.Struct || rt == rtTime {
+		return true
+	}
+
+	return reflect.PtrTo(rt).Implements(rtScanner)
+}
+
+// joinedAssocs returns the name of associations that are scanned together with the record.
+func joinedAssocs(data documentData, fields []string) []string {
+	var (
+		names []string
+	)
+
+	for _, field := range fields {
+		if name, _, joined := splitJoinedField(data, field); joined {
+			if len(names) == 0 || names[len(names)-1] != name {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// unsetJoinedAssocs reverts pointer to joined associations back to nil when no record is joined.
+func unsetJoinedAssocs(doc *Document, names []string) {
+	for _, name := range names {
+		doc.Association(name).unsetZero()
+	}
+}
+
 func scanMulti(cur Cursor, keyField string, keyType reflect.Type, cols map[interface{}][]slice) error {
 	defer cur.Close()
 
@@ -76,7 +246,7 @@ func scanMulti(cur Cursor, keyField string, keyType reflect.Type, cols map[inter
 		} else {
 			// need to create distinct copies
 			// otherwise next scan result will be corrupted
-			keyScanners[i] = &sql.RawBytes{}
+			keyScanners[i] = new(interface{})
 		}
 	}
 
@@ -92,6 +92,42 @@ func TestScanOne(t *testing.T) {
 	cur.AssertExpectations(t)
 }
 
+func TestScanOne_joinAssoc(t *testing.T) {
+	var (
+		address Address
+		cur     = &testCursor{}
+		doc     = NewDocument(&address)
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user.id", "user.name"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(1, nil, nil).Once()
+
+	assert.Nil(t, scanOne(cur, doc))
+	assert.Equal(t, Address{ID: 1}, address)
+
+	cur.AssertExpectations(t)
+}
+
+func TestScanOne_scanError(t *testing.T) {
+	var (
+		address Address
+		cur     = &testCursor{}
+		doc     = NewDocument(&address)
+		err     = errors.New("scan error")
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user.id"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.On("Scan", mock.Anything, mock.Anything).Return(err).Once()
+
+	assert.Equal(t, err, scanOne(cur, doc))
+
+	cur.AssertExpectations(t)
+}
+
 func TestScanOne_fieldsError(t *testing.T) {
 	var (
 		user User
@@ -141,6 +177,30 @@ func TestScanAll(t *testing.T) {
 	cur.AssertExpectations(t)
 }
 
+func TestScanAll_joinAssoc(t *testing.T) {
+	var (
+		addresses []Address
+		cur       = &testCursor{}
+		col       = NewCollection(&addresses)
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "street", "user.id", "user.name"}, nil).Once()
+
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(1, "Pasteur", 10, "Del Piero").Once()
+	cur.MockScan(2, "Dago", nil, nil).Once()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, scanAll(cur, col))
+	assert.Equal(t, []Address{
+		{ID: 1, Street: "Pasteur", User: &User{ID: 10, Name: "Del Piero"}},
+		{ID: 2, Street: "Dago"},
+	}, addresses)
+
+	cur.AssertExpectations(t)
+}
+
 func TestScanAll_scanError(t *testing.T) {
 	var (
 		users []User
@@ -305,3 +365,54 @@ func TestScanMulti_fieldsError(t *testing.T) {
 	assert.Equal(t, err, scanMulti(cur, keyField, keyType, cols))
 	cur.AssertExpectations(t)
 }
+
+func TestScanMaps_fieldsError(t *testing.T) {
+	var (
+		maps []map[string]interface{}
+		cur  = &testCursor{}
+		err  = errors.New("fields error")
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{}, err).Once()
+
+	assert.Equal(t, err, scanRecords(cur, &maps))
+	cur.AssertExpectations(t)
+}
+
+func TestScanValues_scanError(t *testing.T) {
+	var (
+		ids []int
+		cur = &testCursor{}
+		err = errors.New("scan error")
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.On("Scan", mock.Anything).Return(err).Once()
+
+	assert.Equal(t, err, scanRecords(cur, &ids))
+	cur.AssertExpectations(t)
+}
+
+func TestIsScalar(t *testing.T) {
+	tests := []struct {
+		typ    reflect.Type
+		scalar bool
+	}{
+		{reflect.TypeOf(0), true},
+		{reflect.TypeOf(""), true},
+		{reflect.TypeOf([]byte{}), true},
+		{reflect.TypeOf(time.Time{}), true},
+		{reflect.TypeOf(&time.Time{}), true},
+		{reflect.TypeOf(sql.NullString{}), true},
+		{reflect.TypeOf(User{}), false},
+		{reflect.TypeOf(&User{}), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.typ.String(), func(t *testing.T) {
+			assert.Equal(t, test.scalar, isScalar(test.typ))
+		})
+	}
+}
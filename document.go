@@ -28,6 +28,10 @@ const (
 	HasUpdatedAt
 	// HasDeletedAt flag.
 	HasDeletedAt
+	// HasLockVersion flag.
+	HasLockVersion
+	// HasTenantID flag.
+	HasTenantID
 )
 
 var (
@@ -35,6 +39,8 @@ var (
 	primariesCache    sync.Map
 	documentDataCache sync.Map
 	rtTime            = reflect.TypeOf(time.Time{})
+	rtBytes           = reflect.TypeOf([]byte(nil))
+	rtScanner         = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
 	rtTable           = reflect.TypeOf((*table)(nil)).Elem()
 	rtPrimary         = reflect.TypeOf((*primary)(nil)).Elem()
 )
@@ -292,8 +298,14 @@ func (d Document) Scanners(fields []string) []interface{} {
 			} else {
 				result[index] = Nullable(fv.Addr().Interface())
 			}
+		} else if name, assocField, joined := splitJoinedField(d.data, field); joined {
+			var (
+				assocDoc, _ = d.Association(name).Document()
+			)
+
+			result[index] = assocDoc.Scanners([]string{assocField})[0]
 		} else {
-			result[index] = &sql.RawBytes{}
+			result[index] = new(interface{})
 		}
 	}
 
@@ -421,14 +433,14 @@ func extractDocumentData(rt reflect.Type, skipAssoc bool) documentData {
 			typ = typ.Elem()
 		}
 
-		if typ.Kind() != reflect.Struct {
+		if flag := extractFlag(typ, name); flag != Invalid {
 			data.fields = append(data.fields, name)
+			data.flag |= flag
 			continue
 		}
 
-		if flag := extractFlag(typ, name); flag != Invalid {
+		if typ.Kind() != reflect.Struct {
 			data.fields = append(data.fields, name)
-			data.flag |= flag
 			continue
 		}
 
@@ -460,8 +472,53 @@ func extractDocumentData(rt reflect.Type, skipAssoc bool) documentData {
 	return data
 }
 
+// splitJoinedField splits field prefixed by name of belongs to or has one association.
+func splitJoinedField(data documentData, field string) (string, string, bool) {
+	if i := strings.IndexByte(field, '.'); i > 0 && isJoinable(data, field[:i]) {
+		return field[:i], field[i+1:], true
+	}
+
+	return "", "", false
+}
+
+// isJoinable returns true if name is a belongs to or has one association.
+func isJoinable(data documentData, name string) bool {
+	for _, assoc := range data.belongsTo {
+		if assoc == name {
+			return true
+		}
+	}
+
+	for _, assoc := range data.hasOne {
+		if assoc == name {
+			return true
+		}
+	}
+
+	return false
+}
+
 func extractFlag(rt reflect.Type, name string) DocumentFlag {
 	flag := Invalid
+
+	if name == "tenant_id" {
+		if isTenantType(rt) {
+			flag = HasTenantID
+		}
+
+		return flag
+	}
+
+	if name == "lock_version" {
+		switch rt.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			flag = HasLockVersion
+		}
+
+		return flag
+	}
+
 	if rt != rtTime {
 		return flag
 	}
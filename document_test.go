@@ -1,7 +1,6 @@
 package rel
 
 import (
-	"database/sql"
 	"fmt"
 	"reflect"
 	"testing"
@@ -359,11 +358,27 @@ func TestDocument_Scanners(t *testing.T) {
 		scanners = []interface{}{
 			Nullable(&record.Name),
 			Nullable(&record.ID),
-			&sql.RawBytes{},
+			new(interface{}),
 			Nullable(&record.Data),
 			Nullable(&record.Number),
 			&record.Address,
-			&sql.RawBytes{},
+			new(interface{}),
+		}
+	)
+
+	assert.Equal(t, scanners, doc.Scanners(fields))
+}
+
+func TestDocument_Scanners_joinAssoc(t *testing.T) {
+	var (
+		trx      Transaction
+		doc      = NewDocument(&trx)
+		fields   = []string{"id", "buyer.id", "buyer.name", "histories.id"}
+		scanners = []interface{}{
+			Nullable(&trx.ID),
+			Nullable(&trx.Buyer.ID),
+			Nullable(&trx.Buyer.Name),
+			new(interface{}),
 		}
 	)
 
@@ -490,6 +505,30 @@ func TestDocument(t *testing.T) {
 	}
 }
 
+func TestDocument_Flag(t *testing.T) {
+	tests := []struct {
+		record interface{}
+		flag   DocumentFlag
+		result bool
+	}{
+		{record: &User{}, flag: HasCreatedAt, result: true},
+		{record: &User{}, flag: HasUpdatedAt, result: true},
+		{record: &User{}, flag: HasDeletedAt, result: false},
+		{record: &User{}, flag: HasLockVersion, result: false},
+		{record: &Address{}, flag: HasDeletedAt, result: true},
+		{record: &Ticket{}, flag: HasLockVersion, result: true},
+		{record: &Post{}, flag: HasLockVersion, result: true},
+		{record: &struct {
+			ID          int
+			LockVersion string
+		}{}, flag: HasLockVersion, result: false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.result, NewDocument(test.record).Flag(test.flag))
+	}
+}
+
 func TestDocument_notPtr(t *testing.T) {
 	assert.Panics(t, func() {
 		NewDocument(User{}).Table()
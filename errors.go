@@ -1,9 +1,31 @@
 package rel
 
+import (
+	"errors"
+	"strings"
+)
+
 var (
 	// ErrNotFound returned when records not found.
 	ErrNotFound = NotFoundError{}
 
+	// ErrStaleObject returned when record is modified by other process since it's loaded.
+	ErrStaleObject = StaleObjectError{}
+
+	// ErrCrossTenant returned when writing a record that belongs to other tenant than the tenant in context.
+	ErrCrossTenant = CrossTenantError{}
+
+	// ErrTenantRawQuery returned when finding a record that has tenant_id field using raw SQL query with tenant in context.
+	// Raw SQL query can't be filtered by the tenant, use Unscoped or WithoutTenant to run it anyway.
+	ErrTenantRawQuery = errors.New("rel: raw SQL query can't be filtered by the tenant in context")
+
+	// ErrNotInserted returned when a record is ignored due to conflict, and it can't be reloaded because on conflict keys is not defined.
+	// Use OnConflictKeyIgnore to reload the conflicting record instead.
+	ErrNotInserted = errors.New("rel: record is not inserted due to conflict")
+
+	// ErrInvalidKeyset returned when keyset pagination cursor is malformed.
+	ErrInvalidKeyset = errors.New("Invalid keyset cursor")
+
 	// ErrCheckConstraint is an auxiliary variable for error handling.
 	// This is only to be used when checking error with errors.Is(err, ErrCheckConstraint).
 	ErrCheckConstraint = ConstraintError{Type: CheckConstraint}
@@ -23,6 +45,14 @@ var (
 	// ErrForeignKeyConstraint is an auxiliary variable for error handling.
 	// This is only to be used when checking error with errors.Is(err, ErrForeignKeyConstraint).
 	ErrForeignKeyConstraint = ConstraintError{Type: ForeignKeyConstraint}
+
+	// ErrSerializationFailure is an auxiliary variable for error handling.
+	// This is only to be used when checking error with errors.Is(err, ErrSerializationFailure).
+	ErrSerializationFailure = TransactionConflictError{Type: SerializationFailure}
+
+	// ErrDeadlock is an auxiliary variable for error handling.
+	// This is only to be used when checking error with errors.Is(err, ErrDeadlock).
+	ErrDeadlock = TransactionConflictError{Type: Deadlock}
 )
 
 // NotFoundError returned whenever Find returns no result.
@@ -33,6 +63,22 @@ func (nfe NotFoundError) Error() string {
 	return "Record not found"
 }
 
+// StaleObjectError returned when updating or deleting a record with outdated lock version.
+type StaleObjectError struct{}
+
+// Error message.
+func (soe StaleObjectError) Error() string {
+	return "Attempted to update or delete a stale record"
+}
+
+// CrossTenantError returned when writing a record that belongs to other tenant than the tenant in context.
+type CrossTenantError struct{}
+
+// Error message.
+func (cte CrossTenantError) Error() string {
+	return "Attempted to write a record of another tenant"
+}
+
 // ConstraintType defines the type of constraint error.
 type ConstraintType int8
 
@@ -96,3 +142,99 @@ func (ce ConstraintError) Error() string {
 
 	return ce.Type.String() + "Error"
 }
+
+// TransactionConflictType defines the type of transaction conflict error.
+type TransactionConflictType int8
+
+const (
+	// SerializationFailure error type.
+	SerializationFailure TransactionConflictType = iota
+	// Deadlock error type.
+	Deadlock
+)
+
+// String representation of the transaction conflict type.
+func (tct TransactionConflictType) String() string {
+	switch tct {
+	case SerializationFailure:
+		return "SerializationFailure"
+	case Deadlock:
+		return "Deadlock"
+	default:
+		return ""
+	}
+}
+
+// TransactionConflictError returned when transaction is aborted by database because of concurrent transactions.
+// The transaction can be retried using Retry option.
+type TransactionConflictError struct {
+	Type TransactionConflictType
+	Err  error
+}
+
+// Is returns true when target error have the same type.
+func (tce TransactionConflictError) Is(target error) bool {
+	if err, ok := target.(TransactionConflictError); ok {
+		return tce.Type == err.Type
+	}
+
+	return false
+}
+
+// Unwrap internal error returned by database driver.
+func (tce TransactionConflictError) Unwrap() error {
+	return tce.Err
+}
+
+// Error message.
+func (tce TransactionConflictError) Error() string {
+	if tce.Err != nil {
+		return tce.Type.String() + "Error: " + tce.Err.Error()
+	}
+
+	return tce.Type.String() + "Error"
+}
+
+// FieldError describes why a field is invalid.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Error message.
+func (fe FieldError) Error() string {
+	return fe.Field + " " + fe.Message
+}
+
+// ValidationError returned when one or more fields of a record are invalid.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Messages returns all error messages of the given field.
+func (ve ValidationError) Messages(field string) []string {
+	var (
+		messages []string
+	)
+
+	for _, fe := range ve.Errors {
+		if fe.Field == field {
+			messages = append(messages, fe.Message)
+		}
+	}
+
+	return messages
+}
+
+// Error message.
+func (ve ValidationError) Error() string {
+	var (
+		messages = make([]string, len(ve.Errors))
+	)
+
+	for i := range ve.Errors {
+		messages[i] = ve.Errors[i].Error()
+	}
+
+	return "Validation failed: " + strings.Join(messages, ", ")
+}
@@ -4,6 +4,7 @@ package rel
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -48,3 +49,12 @@ func TestConstraintError_ErrorsIs(t *testing.T) {
 		})
 	}
 }
+
+func TestTransactionConflictError_ErrorsIs(t *testing.T) {
+	var (
+		err = fmt.Errorf("insert: %w", TransactionConflictError{Type: SerializationFailure, Err: errors.New("could not serialize access")})
+	)
+
+	assert.True(t, errors.Is(err, ErrSerializationFailure))
+	assert.False(t, errors.Is(err, ErrDeadlock))
+}
@@ -11,6 +11,14 @@ func TestNoResultError(t *testing.T) {
 	assert.Equal(t, "Record not found", NotFoundError{}.Error())
 }
 
+func TestCrossTenantError(t *testing.T) {
+	assert.Equal(t, "Attempted to write a record of another tenant", CrossTenantError{}.Error())
+}
+
+func TestStaleObjectError(t *testing.T) {
+	assert.Equal(t, "Attempted to update or delete a stale record", StaleObjectError{}.Error())
+}
+
 func TestConstraintType(t *testing.T) {
 	assert.Equal(t, "CheckConstraint", CheckConstraint.String())
 	assert.Equal(t, "NotNullConstraint", NotNullConstraint.String())
@@ -69,3 +77,44 @@ func TestConstraintError_Is(t *testing.T) {
 		})
 	}
 }
+
+func TestTransactionConflictType(t *testing.T) {
+	assert.Equal(t, "SerializationFailure", SerializationFailure.String())
+	assert.Equal(t, "Deadlock", Deadlock.String())
+	assert.Equal(t, "", TransactionConflictType(100).String())
+}
+
+func TestTransactionConflictError(t *testing.T) {
+	err := TransactionConflictError{Type: Deadlock, Err: errors.New("deadlock detected")}
+	assert.NotNil(t, err.Unwrap())
+	assert.Equal(t, "DeadlockError: deadlock detected", err.Error())
+
+	err = TransactionConflictError{Type: SerializationFailure}
+	assert.Nil(t, err.Unwrap())
+	assert.Equal(t, "SerializationFailureError", err.Error())
+}
+
+func TestTransactionConflictError_Is(t *testing.T) {
+	assert.True(t, TransactionConflictError{Type: Deadlock, Err: errors.New("deadlock")}.Is(ErrDeadlock))
+	assert.False(t, TransactionConflictError{Type: Deadlock}.Is(ErrSerializationFailure))
+	assert.False(t, ErrSerializationFailure.Is(ErrUniqueConstraint))
+}
+
+func TestFieldError(t *testing.T) {
+	assert.Equal(t, "name can't be blank", FieldError{Field: "name", Message: "can't be blank"}.Error())
+}
+
+func TestValidationError(t *testing.T) {
+	err := ValidationError{
+		Errors: []FieldError{
+			{Field: "name", Message: "can't be blank"},
+			{Field: "age", Message: "is not a number"},
+			{Field: "name", Message: "length must be at least 3"},
+		},
+	}
+
+	assert.Equal(t, "Validation failed: name can't be blank, age is not a number, name length must be at least 3", err.Error())
+	assert.Equal(t, []string{"can't be blank", "length must be at least 3"}, err.Messages("name"))
+	assert.Equal(t, []string{"is not a number"}, err.Messages("age"))
+	assert.Nil(t, err.Messages("email"))
+}
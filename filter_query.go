@@ -46,6 +46,16 @@ const (
 
 	// FilterFragmentOp is filter type for custom filter.
 	FilterFragmentOp
+
+	// FilterExistsOp is filter type for exists subquery.
+	FilterExistsOp
+	// FilterNotExistsOp is filter type for not exists subquery.
+	FilterNotExistsOp
+
+	// FilterTupleGtOp is filter type for greater than comparison of multiple fields.
+	FilterTupleGtOp
+	// FilterTupleLtOp is filter type for less than comparison of multiple fields.
+	FilterTupleLtOp
 )
 
 // FilterQuery defines details of a coundition type.
@@ -292,6 +302,8 @@ func Not(inner ...FilterQuery) FilterQuery {
 			fq.Type = FilterNinOp
 		case FilterLikeOp:
 			fq.Type = FilterNotLikeOp
+		case FilterExistsOp:
+			fq.Type = FilterNotExistsOp
 		default:
 			return FilterQuery{
 				Type:  FilterNotOp,
@@ -501,6 +513,51 @@ func FilterFragment(expr string, values ...interface{}) FilterQuery {
 	}
 }
 
+// Exists check whether the subquery returns any rows.
+func Exists(query Query) FilterQuery {
+	return FilterQuery{
+		Type:  FilterExistsOp,
+		Value: query,
+	}
+}
+
+// NotExists check whether the subquery returns no rows.
+func NotExists(query Query) FilterQuery {
+	return FilterQuery{
+		Type:  FilterNotExistsOp,
+		Value: query,
+	}
+}
+
+// TupleGt compares that tuple of fields is greater than tuple of values, eg: (a, b) > (1, 2).
+func TupleGt(fields []string, values []interface{}) FilterQuery {
+	return filterTuple(FilterTupleGtOp, fields, values)
+}
+
+// TupleLt compares that tuple of fields is less than tuple of values, eg: (a, b) < (1, 2).
+func TupleLt(fields []string, values []interface{}) FilterQuery {
+	return filterTuple(FilterTupleLtOp, fields, values)
+}
+
+func filterTuple(op FilterOp, fields []string, values []interface{}) FilterQuery {
+	if len(fields) != len(values) {
+		panic("rel: fields and values length must be equal")
+	}
+
+	var (
+		inner = make([]FilterQuery, len(fields))
+	)
+
+	for i := range fields {
+		inner[i] = Eq(fields[i], values[i])
+	}
+
+	return FilterQuery{
+		Type:  op,
+		Inner: inner,
+	}
+}
+
 func filterDocument(doc *Document) FilterQuery {
 	var (
 		pFields = doc.PrimaryFields()
@@ -576,6 +633,14 @@ func filterBelongsTo(assoc Association) (FilterQuery, error) {
 		}
 	}
 
+	if !assoc.polymorphicMatch() {
+		return filter, ConstraintError{
+			Key:  assoc.PolymorphicType(),
+			Type: ForeignKeyConstraint,
+			Err:  errors.New("rel: inconsistent polymorphic type"),
+		}
+	}
+
 	return filter, nil
 }
 
@@ -584,7 +649,7 @@ func filterHasOne(assoc Association, asssocDoc *Document) (FilterQuery, error) {
 		fField = assoc.ForeignField()
 		fValue = assoc.ForeignValue()
 		rValue = assoc.ReferenceValue()
-		filter = filterDocument(asssocDoc).AndEq(fField, rValue)
+		filter = filterPolymorphic(assoc, filterDocument(asssocDoc).AndEq(fField, rValue))
 	)
 
 	if rValue != fValue {
@@ -597,3 +662,12 @@ func filterHasOne(assoc Association, asssocDoc *Document) (FilterQuery, error) {
 
 	return filter, nil
 }
+
+// filterPolymorphic adds type filter for the target of polymorphic has one or has many association.
+func filterPolymorphic(assoc Association, filter FilterQuery) FilterQuery {
+	if typeField := assoc.PolymorphicType(); typeField != "" && assoc.Type() != BelongsTo {
+		filter = filter.AndEq(typeField, assoc.PolymorphicValue())
+	}
+
+	return filter
+}
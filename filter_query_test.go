@@ -429,6 +429,11 @@ func TestFilterQuery_Not(t *testing.T) {
 			FilterLikeOp,
 			FilterNotLikeOp,
 		},
+		{
+			`Not Exists`,
+			FilterExistsOp,
+			FilterNotExistsOp,
+		},
 		{
 			`And Op`,
 			FilterAndOp,
@@ -914,6 +919,40 @@ func TestFilterFragment(t *testing.T) {
 	}, FilterFragment("expr", "value"))
 }
 
+func TestExists(t *testing.T) {
+	assert.Equal(t, FilterQuery{
+		Type:  FilterExistsOp,
+		Value: From("users").Where(Eq("active", true)),
+	}, Exists(From("users").Where(Eq("active", true))))
+}
+
+func TestNotExists(t *testing.T) {
+	assert.Equal(t, FilterQuery{
+		Type:  FilterNotExistsOp,
+		Value: From("users").Where(Eq("active", true)),
+	}, NotExists(From("users").Where(Eq("active", true))))
+}
+
+func TestTupleGt(t *testing.T) {
+	assert.Equal(t, FilterQuery{
+		Type:  FilterTupleGtOp,
+		Inner: []FilterQuery{Eq("created_at", "2020-01-01"), Eq("id", 1)},
+	}, TupleGt([]string{"created_at", "id"}, []interface{}{"2020-01-01", 1}))
+}
+
+func TestTupleLt(t *testing.T) {
+	assert.Equal(t, FilterQuery{
+		Type:  FilterTupleLtOp,
+		Inner: []FilterQuery{Eq("created_at", "2020-01-01"), Eq("id", 1)},
+	}, TupleLt([]string{"created_at", "id"}, []interface{}{"2020-01-01", 1}))
+}
+
+func TestTuple_lengthMismatch(t *testing.T) {
+	assert.Panics(t, func() {
+		TupleGt([]string{"created_at", "id"}, []interface{}{1})
+	})
+}
+
 func TestFilterDocument(t *testing.T) {
 	var (
 		user = User{ID: 1}
@@ -0,0 +1,224 @@
+package rel
+
+import (
+	"context"
+	"reflect"
+)
+
+// BeforeSaveHook is implemented by record that needs to run a function before it's inserted or updated.
+type BeforeSaveHook interface {
+	BeforeSave(ctx context.Context) error
+}
+
+// AfterSaveHook is implemented by record that needs to run a function after it's inserted or updated.
+type AfterSaveHook interface {
+	AfterSave(ctx context.Context) error
+}
+
+// BeforeInsertHook is implemented by record that needs to run a function before it's inserted.
+type BeforeInsertHook interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// AfterInsertHook is implemented by record that needs to run a function after it's inserted.
+type AfterInsertHook interface {
+	AfterInsert(ctx context.Context) error
+}
+
+// BeforeUpdateHook is implemented by record that needs to run a function before it's updated.
+type BeforeUpdateHook interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// AfterUpdateHook is implemented by record that needs to run a function after it's updated.
+type AfterUpdateHook interface {
+	AfterUpdate(ctx context.Context) error
+}
+
+// BeforeDeleteHook is implemented by record that needs to run a function before it's deleted.
+type BeforeDeleteHook interface {
+	BeforeDelete(ctx context.Context) error
+}
+
+// AfterDeleteHook is implemented by record that needs to run a function after it's deleted.
+type AfterDeleteHook interface {
+	AfterDelete(ctx context.Context) error
+}
+
+// AfterFindHook is implemented by record that needs to run a function after it's loaded from database.
+type AfterFindHook interface {
+	AfterFind(ctx context.Context) error
+}
+
+// hasMutationHook returns true if record implements any of insert, update, delete or save hook.
+// Operation on such record is wrapped in a transaction, so it can be rolled back when a hook fails.
+func hasMutationHook(v interface{}) bool {
+	switch v.(type) {
+	case BeforeSaveHook, AfterSaveHook,
+		BeforeInsertHook, AfterInsertHook,
+		BeforeUpdateHook, AfterUpdateHook,
+		BeforeDeleteHook, AfterDeleteHook:
+		return true
+	}
+
+	return false
+}
+
+func beforeInsert(ctx context.Context, doc *Document, mutation *Mutation) error {
+	var (
+		saveHook, save     = doc.v.(BeforeSaveHook)
+		insertHook, insert = doc.v.(BeforeInsertHook)
+	)
+
+	if !save && !insert {
+		return nil
+	}
+
+	values := snapshot(doc)
+
+	if save {
+		if err := saveHook.BeforeSave(ctx); err != nil {
+			return err
+		}
+	}
+
+	if insert {
+		if err := insertHook.BeforeInsert(ctx); err != nil {
+			return err
+		}
+	}
+
+	syncMutates(doc, mutation, values)
+	return nil
+}
+
+func afterInsert(ctx context.Context, doc *Document) error {
+	if hook, ok := doc.v.(AfterInsertHook); ok {
+		if err := hook.AfterInsert(ctx); err != nil {
+			return err
+		}
+	}
+
+	if hook, ok := doc.v.(AfterSaveHook); ok {
+		return hook.AfterSave(ctx)
+	}
+
+	return nil
+}
+
+func beforeUpdate(ctx context.Context, doc *Document, mutation *Mutation) error {
+	var (
+		saveHook, save     = doc.v.(BeforeSaveHook)
+		updateHook, update = doc.v.(BeforeUpdateHook)
+	)
+
+	if !save && !update {
+		return nil
+	}
+
+	values := snapshot(doc)
+
+	if save {
+		if err := saveHook.BeforeSave(ctx); err != nil {
+			return err
+		}
+	}
+
+	if update {
+		if err := updateHook.BeforeUpdate(ctx); err != nil {
+			return err
+		}
+	}
+
+	syncMutates(doc, mutation, values)
+	return nil
+}
+
+func afterUpdate(ctx context.Context, doc *Document) error {
+	if hook, ok := doc.v.(AfterUpdateHook); ok {
+		if err := hook.AfterUpdate(ctx); err != nil {
+			return err
+		}
+	}
+
+	if hook, ok := doc.v.(AfterSaveHook); ok {
+		return hook.AfterSave(ctx)
+	}
+
+	return nil
+}
+
+func beforeDelete(ctx context.Context, doc *Document) error {
+	if hook, ok := doc.v.(BeforeDeleteHook); ok {
+		return hook.BeforeDelete(ctx)
+	}
+
+	return nil
+}
+
+func afterDelete(ctx context.Context, doc *Document) error {
+	if hook, ok := doc.v.(AfterDeleteHook); ok {
+		return hook.AfterDelete(ctx)
+	}
+
+	return nil
+}
+
+func afterFind(ctx context.Context, doc *Document) error {
+	if hook, ok := doc.v.(AfterFindHook); ok {
+		return hook.AfterFind(ctx)
+	}
+
+	return nil
+}
+
+func afterFindAll(ctx context.Context, col *Collection) error {
+	if col.Len() == 0 {
+		return nil
+	}
+
+	if _, ok := col.Get(0).v.(AfterFindHook); !ok {
+		return nil
+	}
+
+	for i := 0; i < col.Len(); i++ {
+		if err := afterFind(ctx, col.Get(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// snapshot returns value of every fields of the record, used to find fields modified by before hooks.
+func snapshot(doc *Document) []interface{} {
+	var (
+		fields = doc.Fields()
+		values = make([]interface{}, len(fields))
+	)
+
+	for i, field := range fields {
+		values[i], _ = doc.Value(field)
+	}
+
+	return values
+}
+
+// syncMutates updates value of set mutates using the current value of the record,
+// and adds set mutate for fields modified since the snapshot was taken.
+// This allows before hooks to modify fields that are going to be saved.
+func syncMutates(doc *Document, mutation *Mutation, values []interface{}) {
+	for i, field := range doc.Fields() {
+		var (
+			value, _ = doc.Value(field)
+			mut, ok  = mutation.Mutates[field]
+		)
+
+		if ok && mut.Type == ChangeSetOp {
+			mut.Value = value
+			mutation.Mutates[field] = mut
+		} else if !ok && !reflect.DeepEqual(values[i], value) {
+			mutation.Add(Set(field, value))
+		}
+	}
+}
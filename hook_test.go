@@ -0,0 +1,390 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type Hook struct {
+	ID          int
+	Name        string
+	Slug        string
+	HookOwnerID int
+	Calls       []string         `db:"-"`
+	Errs        map[string]error `db:"-"`
+}
+
+func (h *Hook) call(name string) error {
+	h.Calls = append(h.Calls, name)
+	return h.Errs[name]
+}
+
+func (h *Hook) BeforeSave(ctx context.Context) error {
+	h.Slug = strings.ToLower(h.Name)
+	return h.call("BeforeSave")
+}
+
+func (h *Hook) AfterSave(ctx context.Context) error {
+	return h.call("AfterSave")
+}
+
+func (h *Hook) BeforeInsert(ctx context.Context) error {
+	return h.call("BeforeInsert")
+}
+
+func (h *Hook) AfterInsert(ctx context.Context) error {
+	return h.call("AfterInsert")
+}
+
+func (h *Hook) BeforeUpdate(ctx context.Context) error {
+	return h.call("BeforeUpdate")
+}
+
+func (h *Hook) AfterUpdate(ctx context.Context) error {
+	return h.call("AfterUpdate")
+}
+
+func (h *Hook) BeforeDelete(ctx context.Context) error {
+	return h.call("BeforeDelete")
+}
+
+func (h *Hook) AfterDelete(ctx context.Context) error {
+	return h.call("AfterDelete")
+}
+
+func (h *Hook) AfterFind(ctx context.Context) error {
+	return h.call("AfterFind")
+}
+
+type HookOwner struct {
+	ID    int
+	Hooks []Hook `autosave:"true"`
+}
+
+func TestRepository_Insert_hook(t *testing.T) {
+	var (
+		hook    = Hook{Name: "Rel"}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		mutates = map[string]Mutate{
+			"name":          Set("name", "Rel"),
+			"slug":          Set("slug", "rel"),
+			"hook_owner_id": Set("hook_owner_id", 0),
+		}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("hooks"), mutates, OnConflict{}).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &hook))
+	assert.Equal(t, 1, hook.ID)
+	assert.Equal(t, "rel", hook.Slug)
+	assert.Equal(t, []string{"BeforeSave", "BeforeInsert", "AfterInsert", "AfterSave"}, hook.Calls)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_beforeHookError(t *testing.T) {
+	var (
+		err     = errors.New("error")
+		hook    = Hook{Name: "Rel", Errs: map[string]error{"BeforeInsert": err}}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Insert(context.TODO(), &hook))
+	assert.Equal(t, []string{"BeforeSave", "BeforeInsert"}, hook.Calls)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_hookValidation(t *testing.T) {
+	var (
+		hook      = Hook{Name: "Rel"}
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		changeset = NewChangeset(&hook).Validate(ValidateRequired("slug"))
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("hooks"), mock.Anything, OnConflict{}).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	// slug is set by before hook.
+	assert.Nil(t, repo.Insert(context.TODO(), &hook, changeset))
+	assert.Equal(t, "rel", hook.Slug)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_hookValidationError(t *testing.T) {
+	var (
+		hook      = Hook{Name: "Rel Go", Slug: "rel"}
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		changeset = NewChangeset(&hook).Validate(ValidateFormat("slug", regexp.MustCompile(`^[a-z]+$`)))
+		err       = ValidationError{Errors: []FieldError{{Field: "slug", Message: "has invalid format"}}}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Insert(context.TODO(), &hook, changeset))
+	assert.Equal(t, []string{"BeforeSave", "BeforeInsert"}, hook.Calls)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_afterHookError(t *testing.T) {
+	var (
+		err     = errors.New("error")
+		hook    = Hook{Name: "Rel", Errs: map[string]error{"AfterInsert": err}}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("hooks"), mock.Anything, OnConflict{}).Return(1, nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Insert(context.TODO(), &hook))
+	assert.Equal(t, []string{"BeforeSave", "BeforeInsert", "AfterInsert"}, hook.Calls)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_cascadeHook(t *testing.T) {
+	var (
+		owner = HookOwner{
+			Hooks: []Hook{{Name: "Rel"}},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		mutates = []map[string]Mutate{
+			{
+				"name":          Set("name", "Rel"),
+				"slug":          Set("slug", "rel"),
+				"hook_owner_id": Set("hook_owner_id", 1),
+			},
+		}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("hook_owners"), map[string]Mutate(nil), OnConflict{}).Return(1, nil).Once()
+	adapter.On("InsertAll", From("hooks"), mock.Anything, mutates, OnConflict{}).Return([]interface{}{2}, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &owner))
+	assert.Equal(t, 2, owner.Hooks[0].ID)
+	assert.Equal(t, []string{"BeforeSave", "BeforeInsert", "AfterInsert", "AfterSave"}, owner.Hooks[0].Calls)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_InsertAll_hook(t *testing.T) {
+	var (
+		hooks   = []Hook{{Name: "Rel"}, {Name: "Go"}}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		mutates = []map[string]Mutate{
+			{
+				"name":          Set("name", "Rel"),
+				"slug":          Set("slug", "rel"),
+				"hook_owner_id": Set("hook_owner_id", 0),
+			},
+			{
+				"name":          Set("name", "Go"),
+				"slug":          Set("slug", "go"),
+				"hook_owner_id": Set("hook_owner_id", 0),
+			},
+		}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("InsertAll", From("hooks"), mock.Anything, mutates, OnConflict{}).Return([]interface{}{1, 2}, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.InsertAll(context.TODO(), &hooks))
+	assert.Equal(t, []string{"BeforeSave", "BeforeInsert", "AfterInsert", "AfterSave"}, hooks[0].Calls)
+	assert.Equal(t, []string{"BeforeSave", "BeforeInsert", "AfterInsert", "AfterSave"}, hooks[1].Calls)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_InsertAll_hookError(t *testing.T) {
+	var (
+		err     = errors.New("error")
+		hooks   = []Hook{{Name: "Rel"}, {Name: "Go", Errs: map[string]error{"BeforeSave": err}}}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.InsertAll(context.TODO(), &hooks))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_hook(t *testing.T) {
+	var (
+		hook    = Hook{ID: 1, Name: "Rel"}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		mutates = map[string]Mutate{
+			"id":            Set("id", 1),
+			"name":          Set("name", "Rel"),
+			"slug":          Set("slug", "rel"),
+			"hook_owner_id": Set("hook_owner_id", 0),
+		}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("hooks").Where(Eq("id", 1)), mutates).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Update(context.TODO(), &hook))
+	assert.Equal(t, []string{"BeforeSave", "BeforeUpdate", "AfterUpdate", "AfterSave"}, hook.Calls)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_hookModifiesField(t *testing.T) {
+	var (
+		hook    = Hook{ID: 1, Name: "Rel", Slug: "rel"}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		mutates = map[string]Mutate{
+			"name": Set("name", "Go"),
+			"slug": Set("slug", "go"),
+		}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("hooks").Where(Eq("id", 1)), mutates).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Update(context.TODO(), &hook, Set("name", "Go")))
+	assert.Equal(t, "go", hook.Slug)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_hookError(t *testing.T) {
+	var (
+		err     = errors.New("error")
+		hook    = Hook{ID: 1, Name: "Rel", Errs: map[string]error{"AfterSave": err}}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("hooks").Where(Eq("id", 1)), mock.Anything).Return(1, nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Update(context.TODO(), &hook))
+	assert.Equal(t, []string{"BeforeSave", "BeforeUpdate", "AfterUpdate", "AfterSave"}, hook.Calls)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Delete_hook(t *testing.T) {
+	var (
+		hook    = Hook{ID: 1}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Delete", From("hooks").Where(Eq("id", 1))).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Delete(context.TODO(), &hook))
+	assert.Equal(t, []string{"BeforeDelete", "AfterDelete"}, hook.Calls)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Delete_hookError(t *testing.T) {
+	var (
+		err     = errors.New("error")
+		hook    = Hook{ID: 1, Errs: map[string]error{"BeforeDelete": err}}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Delete(context.TODO(), &hook))
+	assert.Equal(t, []string{"BeforeDelete"}, hook.Calls)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Find_hook(t *testing.T) {
+	var (
+		hook    Hook
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		cur     = createCursor(1)
+	)
+
+	adapter.On("Query", From("hooks").Limit(1)).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Find(context.TODO(), &hook))
+	assert.Equal(t, 10, hook.ID)
+	assert.Equal(t, []string{"AfterFind"}, hook.Calls)
+	assert.False(t, cur.Next())
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Find_hookError(t *testing.T) {
+	var (
+		err     = errors.New("error")
+		hook    = Hook{Errs: map[string]error{"AfterFind": err}}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		cur     = createCursor(1)
+	)
+
+	adapter.On("Query", From("hooks").Limit(1)).Return(cur, nil).Once()
+
+	assert.Equal(t, err, repo.Find(context.TODO(), &hook))
+	assert.False(t, cur.Next())
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_FindAll_hook(t *testing.T) {
+	var (
+		hooks   []Hook
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		cur     = createCursor(2)
+	)
+
+	adapter.On("Query", From("hooks")).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.FindAll(context.TODO(), &hooks))
+	assert.Len(t, hooks, 2)
+	assert.Equal(t, []string{"AfterFind"}, hooks[0].Calls)
+	assert.Equal(t, []string{"AfterFind"}, hooks[1].Calls)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
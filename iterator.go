@@ -49,12 +49,27 @@ func Finish(id ...interface{}) IteratorOption {
 	return finish(id)
 }
 
+type keyset bool
+
+func (k keyset) apply(i *iterator) {
+	i.keyset = bool(k)
+}
+
+// Keyset specifies iterator to fetch the next batch using the sort and primary values of the last record instead of offset.
+// It's faster for large table, and rows inserted or deleted while iterating are not skipped or repeated.
+// Sort fields must not be nullable, since null value can't be positioned using comparison.
+func Keyset() IteratorOption {
+	return keyset(true)
+}
+
 type iterator struct {
 	ctx       context.Context
 	start     []interface{}
 	finish    []interface{}
 	batchSize int
 	current   int
+	keyset    bool
+	last      []interface{}
 	query     Query
 	adapter   Adapter
 	cursor    Cursor
@@ -88,19 +103,46 @@ func (i *iterator) Next(record interface{}) error {
 	)
 
 	i.current++
-	return i.cursor.Scan(scanners...)
+	if err := i.cursor.Scan(scanners...); err != nil {
+		return err
+	}
+
+	if i.keyset {
+		last, err := keysetValues(doc, i.query.SortQuery)
+		if err != nil {
+			return err
+		}
+
+		i.last = last
+	}
+
+	return nil
 }
 
 func (i *iterator) fetch(ctx context.Context, record interface{}) error {
 	if i.current == 0 {
-		i.init(record)
+		if err := i.init(record); err != nil {
+			return err
+		}
 	} else {
 		i.cursor.Close()
 	}
 
-	i.query = i.query.Limit(i.batchSize).Offset(i.current)
+	var (
+		query Query
+	)
 
-	cursor, err := i.adapter.Query(ctx, i.query)
+	if i.keyset {
+		query = i.query.Limit(i.batchSize)
+		if i.last != nil {
+			query = query.Where(keysetFilter(i.query.SortQuery, i.last))
+		}
+	} else {
+		i.query = i.query.Limit(i.batchSize).Offset(i.current)
+		query = i.query
+	}
+
+	cursor, err := i.adapter.Query(ctx, query)
 	if err != nil {
 		return err
 	}
@@ -116,7 +158,7 @@ func (i *iterator) fetch(ctx context.Context, record interface{}) error {
 	return nil
 }
 
-func (i *iterator) init(record interface{}) {
+func (i *iterator) init(record interface{}) error {
 	var (
 		doc = NewDocument(record)
 	)
@@ -133,7 +175,18 @@ func (i *iterator) init(record interface{}) {
 		i.query = i.query.Where(filterDocumentPrimary(doc.PrimaryFields(), i.finish, FilterLteOp))
 	}
 
+	if tenant, ok := TenantFromContext(i.ctx); ok {
+		i.query = withDocumentTenant(tenant, doc.data.flag, i.query)
+	}
+
+	if i.keyset {
+		// primary fields is used as tie breaker, so the position of each record is unique.
+		i.query.SortQuery = keysetSorts(i.query.SortQuery, doc.PrimaryFields())
+		return keysetCheck(doc, i.query.SortQuery)
+	}
+
 	i.query = i.query.SortAsc(doc.PrimaryFields()...)
+	return nil
 }
 
 func newIterator(ctx context.Context, adapter Adapter, query Query, options []IteratorOption) Iterator {
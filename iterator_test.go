@@ -52,6 +52,88 @@ func TestIterator(t *testing.T) {
 	cur3.AssertExpectations(t)
 }
 
+func TestIterator_keyset(t *testing.T) {
+	var (
+		user    User
+		adapter = &testAdapter{}
+		query   = From("users").SortDesc("name")
+		cur1    = createCursor(5)
+		cur2    = createCursor(2)
+		options = []IteratorOption{BatchSize(5), Keyset()}
+		it      = newIterator(context.TODO(), adapter, query, options)
+	)
+
+	query = From("users").SortDesc("name").SortDesc("id").Limit(5)
+	adapter.On("Query", query).Return(cur1, nil).Once()
+	adapter.On("Query", query.Where(TupleLt([]string{"name", "id"}, []interface{}{"", 10}))).Return(cur2, nil).Once()
+
+	recordsCount := 0
+	for {
+		if err := it.Next(&user); err == io.EOF {
+			break
+		} else {
+			assert.Nil(t, err)
+		}
+
+		recordsCount++
+	}
+	it.Close()
+
+	assert.Equal(t, 7, recordsCount)
+
+	// the last next is not called because it's already refetched.
+	cur1.Next()
+
+	adapter.AssertExpectations(t)
+	cur1.AssertExpectations(t)
+	cur2.AssertExpectations(t)
+}
+
+func TestIterator_keysetPrimary(t *testing.T) {
+	var (
+		user    User
+		adapter = &testAdapter{}
+		query   = From("users")
+		cur1    = createCursor(5)
+		cur2    = createCursor(0)
+		options = []IteratorOption{BatchSize(5), Keyset()}
+		it      = newIterator(context.TODO(), adapter, query, options)
+	)
+
+	query = From("users").SortAsc("id").Limit(5)
+	adapter.On("Query", query).Return(cur1, nil).Once()
+	adapter.On("Query", query.Where(Gt("id", 10))).Return(cur2, nil).Once()
+
+	recordsCount := 0
+	for {
+		if err := it.Next(&user); err == io.EOF {
+			break
+		} else {
+			assert.Nil(t, err)
+		}
+
+		recordsCount++
+	}
+	it.Close()
+
+	assert.Equal(t, 5, recordsCount)
+
+	adapter.AssertExpectations(t)
+	cur2.AssertExpectations(t)
+}
+
+func TestIterator_keysetUnknownField(t *testing.T) {
+	var (
+		user    User
+		adapter = &testAdapter{}
+		it      = newIterator(context.TODO(), adapter, From("users").SortAsc("unknown"), []IteratorOption{Keyset()})
+	)
+
+	assert.EqualError(t, it.Next(&user), "rel: keyset field unknown not found in rel.User")
+
+	adapter.AssertExpectations(t)
+}
+
 func TestIterator_setTableName(t *testing.T) {
 	var (
 		user    User
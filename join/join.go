@@ -26,4 +26,6 @@ var (
 	Full = rel.NewFullJoin
 	// FullOn is alias for rel.NewFullJoinOn
 	FullOn = rel.NewFullJoinOn
+	// Assoc is alias for rel.NewJoinAssoc
+	Assoc = rel.NewJoinAssoc
 )
@@ -7,6 +7,7 @@ type JoinQuery struct {
 	From      string
 	To        string
 	Arguments []interface{}
+	Assoc     string
 }
 
 // Build query.
@@ -24,6 +25,15 @@ func NewJoinWith(mode string, table string, from string, to string) JoinQuery {
 	}
 }
 
+// NewJoinAssoc defines a left join to belongs to or has one association of the queried record.
+// Fields of the association will be selected and scanned together with the record.
+func NewJoinAssoc(assoc string) JoinQuery {
+	return JoinQuery{
+		Mode:  "LEFT JOIN",
+		Assoc: assoc,
+	}
+}
+
 // NewJoinFragment defines a join clause using raw query.
 func NewJoinFragment(expr string, args ...interface{}) JoinQuery {
 	if args == nil {
@@ -16,6 +16,13 @@ func TestJoinWith(t *testing.T) {
 	}, rel.NewJoinWith("JOIN", "transactions", "user_id", "id"))
 }
 
+func TestJoinAssoc(t *testing.T) {
+	assert.Equal(t, rel.JoinQuery{
+		Mode:  "LEFT JOIN",
+		Assoc: "buyer",
+	}, rel.NewJoinAssoc("buyer"))
+}
+
 func TestJoinFragment(t *testing.T) {
 	assert.Equal(t, rel.JoinQuery{
 		Mode:      "JOIN transactions ON id=?",
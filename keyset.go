@@ -0,0 +1,193 @@
+package rel
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// KeysetPage holds position of the next page of keyset pagination.
+type KeysetPage struct {
+	// Next is an opaque cursor to fetch the next page, it's empty when there's no next page.
+	Next    string
+	HasNext bool
+}
+
+// keysetSorts returns sorts used for keyset pagination.
+// Missing primary fields are appended as tie breaker, so every row has a unique position.
+func keysetSorts(sorts []SortQuery, primaryFields []string) []SortQuery {
+	var (
+		result = append([]SortQuery(nil), sorts...)
+		sort   = 1
+	)
+
+	if len(sorts) > 0 {
+		sort = sorts[len(sorts)-1].Sort
+	}
+
+	for _, field := range primaryFields {
+		var (
+			found bool
+		)
+
+		for i := range sorts {
+			if keysetField(sorts[i].Field) == field {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			result = append(result, SortQuery{Field: field, Sort: sort})
+		}
+	}
+
+	return result
+}
+
+// keysetFilter returns filter to fetch rows positioned after the given values.
+// Tuple comparison is used when all fields are sorted in the same direction.
+func keysetFilter(sorts []SortQuery, values []interface{}) FilterQuery {
+	var (
+		fields = make([]string, len(sorts))
+		mixed  bool
+	)
+
+	for i := range sorts {
+		fields[i] = sorts[i].Field
+		mixed = mixed || sorts[i].Asc() != sorts[0].Asc()
+	}
+
+	if !mixed {
+		switch {
+		case len(sorts) == 1 && sorts[0].Asc():
+			return Gt(fields[0], values[0])
+		case len(sorts) == 1:
+			return Lt(fields[0], values[0])
+		case sorts[0].Asc():
+			return TupleGt(fields, values)
+		default:
+			return TupleLt(fields, values)
+		}
+	}
+
+	var (
+		filters = make([]FilterQuery, len(sorts))
+	)
+
+	for i := range sorts {
+		var (
+			inner = make([]FilterQuery, i+1)
+		)
+
+		for j := 0; j < i; j++ {
+			inner[j] = Eq(fields[j], values[j])
+		}
+
+		if sorts[i].Asc() {
+			inner[i] = Gt(fields[i], values[i])
+		} else {
+			inner[i] = Lt(fields[i], values[i])
+		}
+
+		filters[i] = And(inner...)
+	}
+
+	return Or(filters...)
+}
+
+// keysetField strips table name from a qualified field.
+func keysetField(field string) string {
+	if i := strings.LastIndexByte(field, '.'); i >= 0 {
+		return field[i+1:]
+	}
+
+	return field
+}
+
+// keysetCheck returns error when sort field is not found or nullable.
+// Null can't be compared using greater or less than operator, so nullable field can't be used to position a record.
+func keysetCheck(doc *Document, sorts []SortQuery) error {
+	for i := range sorts {
+		index, ok := doc.data.index[keysetField(sorts[i].Field)]
+		if !ok {
+			return fmt.Errorf("rel: keyset field %s not found in %s", sorts[i].Field, doc.rt.String())
+		}
+
+		if doc.rt.Field(index).Type.Kind() == reflect.Ptr {
+			return fmt.Errorf("rel: keyset field %s must not be nullable", sorts[i].Field)
+		}
+	}
+
+	return nil
+}
+
+// keysetValues returns values of sort fields, value of nullable type such as sql.NullString must be valid.
+func keysetValues(doc *Document, sorts []SortQuery) ([]interface{}, error) {
+	var (
+		values = make([]interface{}, len(sorts))
+	)
+
+	for i := range sorts {
+		values[i], _ = doc.Value(keysetField(sorts[i].Field))
+
+		if valuer, ok := values[i].(driver.Valuer); ok {
+			if value, err := valuer.Value(); err == nil && value == nil {
+				return nil, fmt.Errorf("rel: keyset field %s must not be null", sorts[i].Field)
+			}
+		}
+	}
+
+	return values, nil
+}
+
+func encodeKeyset(values []interface{}) (string, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeKeyset decodes cursor into values using the type of sort fields in the document.
+func decodeKeyset(cursor string, doc *Document, sorts []SortQuery) ([]interface{}, error) {
+	var (
+		raws []json.RawMessage
+	)
+
+	if err := keysetCheck(doc, sorts); err != nil {
+		return nil, err
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || json.Unmarshal(data, &raws) != nil || len(raws) != len(sorts) {
+		return nil, ErrInvalidKeyset
+	}
+
+	var (
+		values = make([]interface{}, len(sorts))
+	)
+
+	for i := range sorts {
+		var (
+			typ, _ = doc.Type(keysetField(sorts[i].Field))
+			value  = reflect.New(typ)
+		)
+
+		if string(raws[i]) == "null" {
+			return nil, ErrInvalidKeyset
+		}
+
+		if err := json.Unmarshal(raws[i], value.Interface()); err != nil {
+			return nil, ErrInvalidKeyset
+		}
+
+		values[i] = value.Elem().Interface()
+	}
+
+	return values, nil
+}
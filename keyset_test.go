@@ -0,0 +1,158 @@
+package rel
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeysetSorts(t *testing.T) {
+	tests := []struct {
+		name   string
+		sorts  []SortQuery
+		result []SortQuery
+	}{
+		{
+			name:   "empty",
+			sorts:  nil,
+			result: []SortQuery{NewSortAsc("id")},
+		},
+		{
+			name:   "append primary",
+			sorts:  []SortQuery{NewSortDesc("age")},
+			result: []SortQuery{NewSortDesc("age"), NewSortDesc("id")},
+		},
+		{
+			name:   "primary exists",
+			sorts:  []SortQuery{NewSortDesc("users.id"), NewSortAsc("age")},
+			result: []SortQuery{NewSortDesc("users.id"), NewSortAsc("age")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.result, keysetSorts(test.sorts, []string{"id"}))
+		})
+	}
+}
+
+func TestKeysetFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		sorts  []SortQuery
+		values []interface{}
+		result FilterQuery
+	}{
+		{
+			name:   "asc",
+			sorts:  []SortQuery{NewSortAsc("id")},
+			values: []interface{}{1},
+			result: Gt("id", 1),
+		},
+		{
+			name:   "desc",
+			sorts:  []SortQuery{NewSortDesc("id")},
+			values: []interface{}{1},
+			result: Lt("id", 1),
+		},
+		{
+			name:   "tuple asc",
+			sorts:  []SortQuery{NewSortAsc("age"), NewSortAsc("id")},
+			values: []interface{}{20, 1},
+			result: TupleGt([]string{"age", "id"}, []interface{}{20, 1}),
+		},
+		{
+			name:   "tuple desc",
+			sorts:  []SortQuery{NewSortDesc("age"), NewSortDesc("id")},
+			values: []interface{}{20, 1},
+			result: TupleLt([]string{"age", "id"}, []interface{}{20, 1}),
+		},
+		{
+			name:   "mixed",
+			sorts:  []SortQuery{NewSortDesc("age"), NewSortAsc("name"), NewSortAsc("id")},
+			values: []interface{}{20, "rel", 1},
+			result: Or(
+				And(Lt("age", 20)),
+				And(Eq("age", 20), Gt("name", "rel")),
+				And(Eq("age", 20), Eq("name", "rel"), Gt("id", 1)),
+			),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.result, keysetFilter(test.sorts, test.values))
+		})
+	}
+}
+
+func TestKeyset_encodeDecode(t *testing.T) {
+	var (
+		doc   = NewDocument(&User{})
+		sorts = []SortQuery{NewSortDesc("users.age"), NewSortAsc("name"), NewSortAsc("id")}
+	)
+
+	values, err := keysetValues(NewDocument(&User{ID: 1, Name: "rel", Age: 20}), sorts)
+	assert.Nil(t, err)
+
+	cursor, err := encodeKeyset(values)
+	assert.Nil(t, err)
+
+	values, err = decodeKeyset(cursor, doc, sorts)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{20, "rel", 1}, values)
+}
+
+func TestKeyset_decodeInvalid(t *testing.T) {
+	var (
+		doc   = NewDocument(&User{})
+		sorts = []SortQuery{NewSortAsc("age"), NewSortAsc("id")}
+	)
+
+	tests := []string{
+		"%%%",
+		"bm90IGpzb24", // not json
+		"WzFd",        // [1]
+		"WyJhIiwxXQ",  // ["a",1]
+		"W251bGwsMV0", // [null,1]
+	}
+
+	for _, cursor := range tests {
+		t.Run(cursor, func(t *testing.T) {
+			values, err := decodeKeyset(cursor, doc, sorts)
+			assert.Nil(t, values)
+			assert.Equal(t, ErrInvalidKeyset, err)
+		})
+	}
+}
+
+func TestKeyset_decodeUnknownField(t *testing.T) {
+	values, err := decodeKeyset("WzFd", NewDocument(&User{}), []SortQuery{NewSortAsc("unknown")})
+	assert.Nil(t, values)
+	assert.EqualError(t, err, "rel: keyset field unknown not found in rel.User")
+}
+
+func TestKeyset_nullable(t *testing.T) {
+	type Event struct {
+		ID       int
+		Title    sql.NullString
+		StartsAt *time.Time
+	}
+
+	var (
+		doc = NewDocument(&Event{ID: 1})
+	)
+
+	assert.EqualError(t, keysetCheck(doc, []SortQuery{NewSortAsc("starts_at")}), "rel: keyset field starts_at must not be nullable")
+	assert.Nil(t, keysetCheck(doc, []SortQuery{NewSortAsc("title")}))
+
+	values, err := keysetValues(doc, []SortQuery{NewSortAsc("title")})
+	assert.Nil(t, values)
+	assert.EqualError(t, err, "rel: keyset field title must not be null")
+
+	values, err = keysetValues(NewDocument(&Event{ID: 1, Title: sql.NullString{String: "rel", Valid: true}}), []SortQuery{NewSortAsc("title")})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{sql.NullString{String: "rel", Valid: true}}, values)
+}
@@ -50,6 +50,7 @@ func (m Map) Apply(doc *Document, mutation *Mutation) {
 
 			mutation.SetAssoc(field, muts...)
 			mutation.SetDeletedIDs(field, deletedIDs)
+			mutation.SetTracked(field)
 		default:
 			if field == pField {
 				if v != pValue {
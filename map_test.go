@@ -39,6 +39,7 @@ func TestMap(t *testing.T) {
 	userMutation.SetAssoc("transactions", transaction1Mutation, transaction2Mutation)
 	userMutation.SetAssoc("address", addressMutation)
 	userMutation.SetDeletedIDs("transactions", []interface{}{})
+	userMutation.SetTracked("transactions")
 
 	assert.Equal(t, userMutation, Apply(doc, data))
 	assert.Equal(t, User{
@@ -125,6 +126,7 @@ func TestMap_update(t *testing.T) {
 	userMutation.SetAssoc("transactions", transaction1Mutation, transaction2Mutation)
 	userMutation.SetAssoc("address", addressMutation)
 	userMutation.SetDeletedIDs("transactions", []interface{}{})
+	userMutation.SetTracked("transactions")
 
 	assert.Equal(t, userMutation, Apply(doc, data))
 	assert.Equal(t, User{
@@ -168,6 +170,7 @@ func TestMap_hasManyUpdateDeleteInsert(t *testing.T) {
 
 	userMutation.SetAssoc("transactions", transaction2Mutation, transaction1Mutation)
 	userMutation.SetDeletedIDs("transactions", []interface{}{2})
+	userMutation.SetTracked("transactions")
 
 	assert.Equal(t, userMutation, Apply(doc, data))
 	assert.Equal(t, User{
@@ -23,7 +23,7 @@ func Apply(doc *Document, mutators ...Mutator) Mutation {
 
 	for i := range mutators {
 		switch mut := mutators[i].(type) {
-		case Unscoped, Reload, Cascade:
+		case Unscoped, Reload, Cascade, OnConflict:
 			optionsCount++
 			mut.Apply(doc, &mutation)
 		default:
@@ -43,17 +43,29 @@ func Apply(doc *Document, mutators ...Mutator) Mutation {
 type AssocMutation struct {
 	Mutations  []Mutation
 	DeletedIDs []interface{} // This is array of single id, and doesn't support composite primary key.
+	Tracked    bool          // Tracked is true when mutations of persisted records only contain changes, such as built by changeset or map.
 }
 
 // Mutation represents value to be inserted or updated to database.
 // It's not safe to be used multiple time. some operation my alter mutation data.
+// Validators declared by changeset run after before hooks of Insert, InsertAll and Update, and ValidationError is returned without executing the mutation,
+// UpdateAll and Delete don't accept mutators, so changeset validation doesn't apply to them.
 type Mutation struct {
-	Mutates   map[string]Mutate
-	Assoc     map[string]AssocMutation
-	Unscoped  Unscoped
-	Reload    Reload
-	Cascade   Cascade
-	ErrorFunc ErrorFunc
+	Mutates        map[string]Mutate
+	Assoc          map[string]AssocMutation
+	OnConflict     OnConflict
+	Unscoped       Unscoped
+	Reload         Reload
+	Cascade        Cascade
+	ErrorFunc      ErrorFunc
+	validators     []Validator
+	constraintFunc ErrorFunc
+}
+
+// transformError translates constraint error declared by changeset into validation error,
+// before it's transformed by error func.
+func (m Mutation) transformError(err error) error {
+	return m.ErrorFunc.transform(m.constraintFunc.transform(err))
 }
 
 func (m *Mutation) initMutates() {
@@ -109,6 +121,15 @@ func (m *Mutation) SetDeletedIDs(field string, ids []interface{}) {
 	m.Assoc[field] = assoc
 }
 
+// SetTracked marks mutations of association to only contain changes of persisted records.
+func (m *Mutation) SetTracked(field string) {
+	m.initAssoc()
+
+	assoc := m.Assoc[field]
+	assoc.Tracked = true
+	m.Assoc[field] = assoc
+}
+
 // ChangeOp represents type of mutate operation.
 type ChangeOp int
 
@@ -240,6 +261,21 @@ func (ef ErrorFunc) Apply(doc *Document, mutation *Mutation) {
 	mutation.ErrorFunc = ef
 }
 
+// chain returns error func that calls prev before calling ef.
+func (ef ErrorFunc) chain(prev ErrorFunc) ErrorFunc {
+	if ef == nil {
+		return prev
+	}
+
+	if prev == nil {
+		return ef
+	}
+
+	return func(err error) error {
+		return ef(prev(err))
+	}
+}
+
 func (ef ErrorFunc) transform(err error) error {
 	if ef != nil && err != nil {
 		return ef(err)
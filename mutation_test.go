@@ -1,6 +1,7 @@
 package rel
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -126,3 +127,17 @@ func TestApplyMutation_Cascade(t *testing.T) {
 	assert.Equal(t, mutation, Apply(doc, mutators...))
 	assert.Equal(t, "string", record.Field1)
 }
+
+func TestApplyMutation_ErrorFunc(t *testing.T) {
+	var (
+		record   = TestRecord{}
+		doc      = NewDocument(&record)
+		mutators = []Mutator{
+			ErrorFunc(func(err error) error { return errors.New("first") }),
+			ErrorFunc(func(err error) error { return errors.New("second") }),
+		}
+	)
+
+	// the last error func replaces the previous one.
+	assert.Equal(t, errors.New("second"), Apply(doc, mutators...).transformError(errors.New("error")))
+}
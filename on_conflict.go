@@ -0,0 +1,100 @@
+package rel
+
+// OnConflict mutator.
+// This mutator can only be used to modify insert and insert all behaviour when a conflict occurs.
+// When Keys is not defined, ignore applies to any conflict, while replace fallbacks to use the primary fields of the record.
+// Ignored record without Keys can't be reloaded, so ErrNotInserted is returned unless its primary values are set.
+type OnConflict struct {
+	Keys    []string
+	Ignore  bool
+	Replace bool
+	Fields  []string
+}
+
+// Apply mutation.
+func (ocm OnConflict) Apply(doc *Document, mutation *Mutation) {
+	if ocm.Replace && len(ocm.Keys) == 0 {
+		ocm.Keys = doc.PrimaryFields()
+	}
+
+	mutation.OnConflict = ocm
+}
+
+// None returns true if no on conflict behaviour is defined.
+func (ocm OnConflict) None() bool {
+	return !ocm.Ignore && !ocm.Replace
+}
+
+// ReplaceFields returns list of fields to be replaced when conflict occurs.
+// When fields is not explicitly defined, all inserted fields except the conflict keys will be returned.
+func (ocm OnConflict) ReplaceFields(fields []string) []string {
+	if !ocm.Replace {
+		return nil
+	}
+
+	if len(ocm.Fields) > 0 {
+		return ocm.Fields
+	}
+
+	var (
+		result = make([]string, 0, len(fields))
+	)
+
+	for _, field := range fields {
+		if !ocm.isKey(field) {
+			result = append(result, field)
+		}
+	}
+
+	return result
+}
+
+func (ocm OnConflict) isKey(field string) bool {
+	for i := range ocm.Keys {
+		if ocm.Keys[i] == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OnConflictIgnore insertion when conflict happens.
+func OnConflictIgnore() OnConflict {
+	return OnConflict{Ignore: true}
+}
+
+// OnConflictKeyIgnore insertion when conflict happens on specific key.
+func OnConflictKeyIgnore(key string) OnConflict {
+	return OnConflictKeysIgnore([]string{key})
+}
+
+// OnConflictKeysIgnore insertion when conflict happens on specific keys.
+func OnConflictKeysIgnore(keys []string) OnConflict {
+	return OnConflict{Keys: keys, Ignore: true}
+}
+
+// OnConflictReplace replaces all inserted fields when conflict happens.
+func OnConflictReplace() OnConflict {
+	return OnConflict{Replace: true}
+}
+
+// OnConflictKeyReplace replaces all inserted fields when conflict happens on specific key.
+func OnConflictKeyReplace(key string) OnConflict {
+	return OnConflictKeysReplace([]string{key})
+}
+
+// OnConflictKeysReplace replaces all inserted fields when conflict happens on specific keys.
+func OnConflictKeysReplace(keys []string) OnConflict {
+	return OnConflict{Keys: keys, Replace: true}
+}
+
+// OnConflictReplaceFields replaces only the given fields when conflict happens.
+func OnConflictReplaceFields(fields ...string) OnConflict {
+	return OnConflict{Replace: true, Fields: fields}
+}
+
+// OnConflictKeysReplaceFields replaces only the given fields when conflict happens on specific keys.
+func OnConflictKeysReplaceFields(keys []string, fields ...string) OnConflict {
+	return OnConflict{Keys: keys, Replace: true, Fields: fields}
+}
@@ -0,0 +1,101 @@
+package rel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnConflict_Apply(t *testing.T) {
+	var (
+		doc = NewDocument(&User{})
+	)
+
+	tests := []struct {
+		name       string
+		onConflict OnConflict
+		result     OnConflict
+	}{
+		{
+			name:       "ignore",
+			onConflict: OnConflictIgnore(),
+			result:     OnConflict{Ignore: true},
+		},
+		{
+			name:       "key ignore",
+			onConflict: OnConflictKeyIgnore("name"),
+			result:     OnConflict{Keys: []string{"name"}, Ignore: true},
+		},
+		{
+			name:       "keys ignore",
+			onConflict: OnConflictKeysIgnore([]string{"name", "age"}),
+			result:     OnConflict{Keys: []string{"name", "age"}, Ignore: true},
+		},
+		{
+			name:       "replace",
+			onConflict: OnConflictReplace(),
+			result:     OnConflict{Keys: []string{"id"}, Replace: true},
+		},
+		{
+			name:       "key replace",
+			onConflict: OnConflictKeyReplace("name"),
+			result:     OnConflict{Keys: []string{"name"}, Replace: true},
+		},
+		{
+			name:       "keys replace",
+			onConflict: OnConflictKeysReplace([]string{"name", "age"}),
+			result:     OnConflict{Keys: []string{"name", "age"}, Replace: true},
+		},
+		{
+			name:       "replace fields",
+			onConflict: OnConflictReplaceFields("age"),
+			result:     OnConflict{Keys: []string{"id"}, Replace: true, Fields: []string{"age"}},
+		},
+		{
+			name:       "keys replace fields",
+			onConflict: OnConflictKeysReplaceFields([]string{"name"}, "age"),
+			result:     OnConflict{Keys: []string{"name"}, Replace: true, Fields: []string{"age"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				mutation Mutation
+			)
+
+			test.onConflict.Apply(doc, &mutation)
+			assert.Equal(t, test.result, mutation.OnConflict)
+			assert.False(t, mutation.OnConflict.None())
+		})
+	}
+}
+
+func TestOnConflict_Apply_compositePrimaryFields(t *testing.T) {
+	var (
+		mutation Mutation
+		doc      = NewDocument(&UserRole{})
+	)
+
+	OnConflictIgnore().Apply(doc, &mutation)
+	assert.Equal(t, OnConflict{Ignore: true}, mutation.OnConflict)
+
+	OnConflictReplace().Apply(doc, &mutation)
+	assert.Equal(t, OnConflict{Keys: []string{"user_id", "role_id"}, Replace: true}, mutation.OnConflict)
+}
+
+func TestOnConflict_None(t *testing.T) {
+	assert.True(t, OnConflict{}.None())
+	assert.True(t, OnConflict{Keys: []string{"id"}}.None())
+}
+
+func TestOnConflict_ReplaceFields(t *testing.T) {
+	var (
+		fields = []string{"id", "name", "age"}
+	)
+
+	assert.Nil(t, OnConflictKeyIgnore("id").ReplaceFields(fields))
+	assert.Equal(t, []string{"name", "age"}, OnConflictKeyReplace("id").ReplaceFields(fields))
+	assert.Equal(t, []string{"id", "age"}, OnConflictKeyReplace("name").ReplaceFields(fields))
+	assert.Equal(t, []string{"age"}, OnConflictKeysReplaceFields([]string{"id"}, "age").ReplaceFields(fields))
+}
@@ -0,0 +1,37 @@
+package rel
+
+// Page holds metadata of offset pagination.
+type Page struct {
+	Number  int
+	PerPage int
+	// Total and TotalPages are zero when count is skipped using SkipCount.
+	Total      int
+	TotalPages int
+	HasNext    bool
+	HasPrev    bool
+}
+
+func newPage(number int, perPage int, total int) Page {
+	var (
+		totalPages = (total + perPage - 1) / perPage
+	)
+
+	return Page{
+		Number:     number,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    number < totalPages,
+		HasPrev:    number > 1,
+	}
+}
+
+// SkipCount skips the count query when paginating.
+// One more record is fetched instead to check whether next page exists, Total and TotalPages won't be available.
+// Default to false.
+type SkipCount bool
+
+// Build query.
+func (sc SkipCount) Build(query *Query) {
+	query.SkipCountQuery = sc
+}
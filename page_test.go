@@ -0,0 +1,53 @@
+package rel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPage(t *testing.T) {
+	tests := []struct {
+		name   string
+		number int
+		total  int
+		page   Page
+	}{
+		{
+			name:   "empty",
+			number: 1,
+			total:  0,
+			page:   Page{Number: 1, PerPage: 10},
+		},
+		{
+			name:   "first page",
+			number: 1,
+			total:  25,
+			page:   Page{Number: 1, PerPage: 10, Total: 25, TotalPages: 3, HasNext: true},
+		},
+		{
+			name:   "middle page",
+			number: 2,
+			total:  25,
+			page:   Page{Number: 2, PerPage: 10, Total: 25, TotalPages: 3, HasNext: true, HasPrev: true},
+		},
+		{
+			name:   "last page",
+			number: 3,
+			total:  30,
+			page:   Page{Number: 3, PerPage: 10, Total: 30, TotalPages: 3, HasPrev: true},
+		},
+		{
+			name:   "out of range",
+			number: 5,
+			total:  30,
+			page:   Page{Number: 5, PerPage: 10, Total: 30, TotalPages: 3, HasPrev: true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.page, newPage(test.number, 10, test.total))
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package rel
+
+// PreloadQuery defines an association to be preloaded along with its nested associations.
+// Each preload uses its own queriers, which are applied only to the query of its association.
+type PreloadQuery struct {
+	Field    string
+	Queriers []Querier
+	Nested   []PreloadQuery
+}
+
+// With adds nested preloads for associations of the preloaded records.
+func (pq PreloadQuery) With(nested ...PreloadQuery) PreloadQuery {
+	pq.Nested = append(append([]PreloadQuery(nil), pq.Nested...), nested...)
+	return pq
+}
+
+// NewPreload defines preload of an association using the given queriers.
+func NewPreload(field string, queriers ...Querier) PreloadQuery {
+	return PreloadQuery{
+		Field:    field,
+		Queriers: queriers,
+	}
+}
+
+// FlattenPreloads returns preloads in breadth-first order with field expanded to its full path.
+// This ensures every association is preloaded only after all of its parents are loaded.
+func FlattenPreloads(preloads []PreloadQuery) []PreloadQuery {
+	var (
+		result []PreloadQuery
+		level  = preloads
+	)
+
+	for len(level) > 0 {
+		var (
+			next []PreloadQuery
+		)
+
+		for _, pq := range level {
+			result = append(result, PreloadQuery{Field: pq.Field, Queriers: pq.Queriers})
+
+			for _, nested := range pq.Nested {
+				nested.Field = pq.Field + "." + nested.Field
+				next = append(next, nested)
+			}
+		}
+
+		level = next
+	}
+
+	return result
+}
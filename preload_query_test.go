@@ -0,0 +1,46 @@
+package rel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPreload(t *testing.T) {
+	assert.Equal(t, PreloadQuery{
+		Field:    "transactions",
+		Queriers: []Querier{Eq("status", "paid")},
+	}, NewPreload("transactions", Eq("status", "paid")))
+}
+
+func TestPreloadQuery_With(t *testing.T) {
+	var (
+		parent = NewPreload("transactions").With(NewPreload("histories"))
+		result = parent.With(NewPreload("address"))
+	)
+
+	assert.Equal(t, []PreloadQuery{NewPreload("histories")}, parent.Nested)
+	assert.Equal(t, []PreloadQuery{NewPreload("histories"), NewPreload("address")}, result.Nested)
+}
+
+func TestFlattenPreloads(t *testing.T) {
+	var (
+		preloads = []PreloadQuery{
+			NewPreload("transactions", Eq("status", "paid")).With(
+				NewPreload("histories", Limit(5)),
+				NewPreload("address").With(
+					NewPreload("user"),
+				),
+			),
+			NewPreload("address"),
+		}
+	)
+
+	assert.Equal(t, []PreloadQuery{
+		{Field: "transactions", Queriers: []Querier{Eq("status", "paid")}},
+		{Field: "address"},
+		{Field: "transactions.histories", Queriers: []Querier{Limit(5)}},
+		{Field: "transactions.address"},
+		{Field: "transactions.address.user"},
+	}, FlattenPreloads(preloads))
+}
@@ -0,0 +1,105 @@
+package rel
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var primaryGenerators sync.Map
+
+// PrimaryGenerator generates primary value on client side.
+type PrimaryGenerator func() interface{}
+
+// RegisterPrimaryGenerator registers generator for primary field that have the same type as sample.
+// Generator will be used to fill zero primary value before a record is inserted.
+//
+//	rel.RegisterPrimaryGenerator(uuid.UUID{}, func() interface{} {
+//		return uuid.New()
+//	})
+func RegisterPrimaryGenerator(sample interface{}, generator PrimaryGenerator) {
+	var (
+		rt = reflect.TypeOf(sample)
+	)
+
+	if generator == nil {
+		primaryGenerators.Delete(rt)
+		return
+	}
+
+	primaryGenerators.Store(rt, generator)
+}
+
+func generatePrimary(doc *Document, mutation *Mutation) error {
+	for _, field := range doc.PrimaryFields() {
+		var (
+			rt, _    = doc.Type(field)
+			value, _ = doc.Value(field)
+		)
+
+		if !isPrimaryZero(value) {
+			continue
+		}
+
+		if generator, ok := primaryGenerators.Load(rt); ok {
+			value = generator.(PrimaryGenerator)()
+			if !doc.SetValue(field, value) {
+				return fmt.Errorf("rel: cannot assign generated primary value %v as %s into %s", value, field, doc.Table())
+			}
+
+			mutation.Add(Set(field, value))
+		}
+	}
+
+	return nil
+}
+
+// isPrimaryZero is similar to isZero, but it also checks elements of array and fields of struct,
+// so primary key of array type such as uuid is only zero when all of its bytes are zero.
+func isPrimaryZero(value interface{}) bool {
+	if _, ok := value.(isZeroer); ok {
+		return isZero(value)
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Array, reflect.Struct:
+		return isDeepZero(reflect.ValueOf(value), 1)
+	default:
+		return isZero(value)
+	}
+}
+
+// setPrimaryValue assigns primary value returned by adapter to zero primary fields.
+// Value is converted to the actual type of the field the same way query result is scanned.
+// Composite primary value is expected as a slice of values in the same order of primary fields.
+func setPrimaryValue(doc *Document, pFields []string, pValue interface{}) error {
+	var (
+		pValues []interface{}
+	)
+
+	if len(pFields) == 1 {
+		pValues = []interface{}{pValue}
+	} else if values, ok := pValue.([]interface{}); ok && len(values) == len(pFields) {
+		pValues = values
+	}
+
+	for i, value := range pValues {
+		if current, _ := doc.Value(pFields[i]); isZero(value) || !isPrimaryZero(current) {
+			continue
+		}
+
+		var (
+			rt, _ = doc.Type(pFields[i])
+			rv    = reflect.New(rt)
+		)
+
+		if err := Nullable(rv.Interface()).(sql.Scanner).Scan(value); err != nil {
+			return err
+		}
+
+		doc.SetValue(pFields[i], rv.Elem())
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package rel
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Token [4]byte
+
+func (t *Token) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok || len(b) != len(t) {
+		return errors.New("invalid token")
+	}
+
+	copy(t[:], b)
+	return nil
+}
+
+type Session struct {
+	ID     Token
+	UserID int
+}
+
+type Article struct {
+	Slug  *string `db:",primary"`
+	Title string
+}
+
+func TestRegisterPrimaryGenerator(t *testing.T) {
+	var (
+		session  Session
+		doc      = NewDocument(&session)
+		mutation = Apply(doc, newStructset(doc, false))
+	)
+
+	RegisterPrimaryGenerator(Token{}, func() interface{} {
+		return Token{1, 2, 3, 4}
+	})
+	defer RegisterPrimaryGenerator(Token{}, nil)
+
+	assert.Nil(t, generatePrimary(doc, &mutation))
+	assert.Equal(t, Token{1, 2, 3, 4}, session.ID)
+	assert.Equal(t, Set("id", Token{1, 2, 3, 4}), mutation.Mutates["id"])
+}
+
+func TestRegisterPrimaryGenerator_notZero(t *testing.T) {
+	var (
+		session  = Session{ID: Token{4, 3, 2, 1}}
+		doc      = NewDocument(&session)
+		mutation = Apply(doc, newStructset(doc, false))
+	)
+
+	RegisterPrimaryGenerator(Token{}, func() interface{} {
+		return Token{1, 2, 3, 4}
+	})
+	defer RegisterPrimaryGenerator(Token{}, nil)
+
+	assert.Nil(t, generatePrimary(doc, &mutation))
+	assert.Equal(t, Token{4, 3, 2, 1}, session.ID)
+}
+
+func TestRegisterPrimaryGenerator_unregistered(t *testing.T) {
+	var (
+		session  Session
+		doc      = NewDocument(&session)
+		mutation = Apply(doc, newStructset(doc, false))
+	)
+
+	assert.Nil(t, generatePrimary(doc, &mutation))
+	assert.Equal(t, Token{}, session.ID)
+	assert.NotContains(t, mutation.Mutates, "id")
+}
+
+func TestRegisterPrimaryGenerator_invalidValue(t *testing.T) {
+	var (
+		session  Session
+		doc      = NewDocument(&session)
+		mutation = Apply(doc, newStructset(doc, false))
+	)
+
+	RegisterPrimaryGenerator(Token{}, func() interface{} {
+		return "token"
+	})
+	defer RegisterPrimaryGenerator(Token{}, nil)
+
+	assert.EqualError(t, generatePrimary(doc, &mutation), "rel: cannot assign generated primary value token as id into sessions")
+}
+
+func TestSetPrimaryValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  interface{}
+		pValue  interface{}
+		result  interface{}
+		wantErr bool
+	}{
+		{
+			name:   "int",
+			record: &User{},
+			pValue: int64(1),
+			result: &User{ID: 1},
+		},
+		{
+			name:   "nil",
+			record: &User{},
+			pValue: nil,
+			result: &User{},
+		},
+		{
+			name:   "already set",
+			record: &User{ID: 2},
+			pValue: int64(1),
+			result: &User{ID: 2},
+		},
+		{
+			name:   "scanner",
+			record: &Session{},
+			pValue: []byte{1, 2, 3, 4},
+			result: &Session{ID: Token{1, 2, 3, 4}},
+		},
+		{
+			name:   "pointer",
+			record: &Article{},
+			pValue: []byte("rel"),
+			result: &Article{Slug: func(s string) *string { return &s }("rel")},
+		},
+		{
+			name:   "composite",
+			record: &UserRole{UserID: 1},
+			pValue: []interface{}{int64(1), int64(2)},
+			result: &UserRole{UserID: 1, RoleID: 2},
+		},
+		{
+			name:   "composite invalid",
+			record: &UserRole{},
+			pValue: int64(1),
+			result: &UserRole{},
+		},
+		{
+			name:    "error",
+			record:  &User{},
+			pValue:  "abc",
+			result:  &User{},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				doc = NewDocument(test.record)
+				err = setPrimaryValue(doc, doc.PrimaryFields(), test.pValue)
+			)
+
+			assert.Equal(t, test.wantErr, err != nil)
+			assert.Equal(t, test.result, test.record)
+		})
+	}
+}
+
+func TestIsPrimaryZero(t *testing.T) {
+	assert.True(t, isPrimaryZero(Token{}))
+	assert.False(t, isPrimaryZero(Token{1}))
+	assert.True(t, isPrimaryZero(0))
+	assert.False(t, isPrimaryZero("token"))
+	assert.True(t, isPrimaryZero(nil))
+}
@@ -22,6 +22,10 @@ func Build(table string, queriers ...Querier) Query {
 			q.Build(&query)
 		case JoinQuery:
 			q.Build(&query)
+		case WithQuery:
+			q.Build(&query)
+		case CombineQuery:
+			q.Build(&query)
 		case FilterQuery:
 			q.Build(&query)
 		case GroupQuery:
@@ -34,10 +38,16 @@ func Build(table string, queriers ...Querier) Query {
 			q.Build(&query)
 		case Lock:
 			q.Build(&query)
+		case ScopeQuery:
+			q.Build(&query)
+		case UnscopeQuery:
+			q.Build(&query)
 		case Unscoped:
 			q.Build(&query)
 		case Reload:
 			q.Build(&query)
+		case SkipCount:
+			q.Build(&query)
 		case SQLQuery:
 			q.Build(&query)
 		}
@@ -52,19 +62,25 @@ func Build(table string, queriers ...Querier) Query {
 
 // Query defines information about query generated by query builder.
 type Query struct {
-	empty         bool // TODO: use bitmask to mark what is updated and use it when merging two queries
-	Table         string
-	SelectQuery   SelectQuery
-	JoinQuery     []JoinQuery
-	WhereQuery    FilterQuery
-	GroupQuery    GroupQuery
-	SortQuery     []SortQuery
-	OffsetQuery   Offset
-	LimitQuery    Limit
-	LockQuery     Lock
-	UnscopedQuery Unscoped
-	ReloadQuery   Reload
-	SQLQuery      SQLQuery
+	empty          bool // TODO: use bitmask to mark what is updated and use it when merging two queries
+	Table          string
+	TableQuery     *Query
+	WithQuery      []WithQuery
+	CombineQuery   []CombineQuery
+	SelectQuery    SelectQuery
+	JoinQuery      []JoinQuery
+	WhereQuery     FilterQuery
+	GroupQuery     GroupQuery
+	SortQuery      []SortQuery
+	OffsetQuery    Offset
+	LimitQuery     Limit
+	LockQuery      Lock
+	ScopeQuery     ScopeQuery
+	UnscopeQuery   UnscopeQuery
+	UnscopedQuery  Unscoped
+	ReloadQuery    Reload
+	SkipCountQuery SkipCount
+	SQLQuery       SQLQuery
 }
 
 // Build query.
@@ -75,13 +91,18 @@ func (q Query) Build(query *Query) {
 		// manual merge
 		if q.Table != "" {
 			query.Table = q.Table
+			query.TableQuery = q.TableQuery
 		}
 
-		if q.SelectQuery.Fields != nil {
+		if q.SelectQuery.Fields != nil || q.SelectQuery.Exprs != nil {
 			query.SelectQuery = q.SelectQuery
 		}
 
+		query.WithQuery = append(query.WithQuery, q.WithQuery...)
 		query.JoinQuery = append(query.JoinQuery, q.JoinQuery...)
+		query.ScopeQuery = append(query.ScopeQuery, q.ScopeQuery...)
+		query.UnscopeQuery = append(query.UnscopeQuery, q.UnscopeQuery...)
+		query.CombineQuery = append(query.CombineQuery, q.CombineQuery...)
 
 		if !q.WhereQuery.None() {
 			query.WhereQuery = query.WhereQuery.And(q.WhereQuery)
@@ -106,18 +127,34 @@ func (q Query) Build(query *Query) {
 		}
 
 		query.ReloadQuery = q.ReloadQuery
+		query.SkipCountQuery = q.SkipCountQuery
 	}
 }
 
 // Select filter fields to be selected from database.
 func (q Query) Select(fields ...string) Query {
-	q.SelectQuery = NewSelect(fields...)
+	q.SelectQuery = NewSelect(fields...).Expr(q.SelectQuery.Exprs...)
 	return q
 }
 
 // From set the table to be used for query.
 func (q Query) From(table string) Query {
 	q.Table = table
+	q.TableQuery = nil
+	return q
+}
+
+// FromQuery set a subquery aliased as table to be used for query.
+func (q Query) FromQuery(alias string, query Query) Query {
+	q.Table = alias
+	q.TableQuery = &query
+	return q
+}
+
+// SelectExpr appends computed expressions such as window functions to be selected.
+// When no fields are selected, the expressions are selected together with all fields.
+func (q Query) SelectExpr(exprs ...SelectExpr) Query {
+	q.SelectQuery = q.SelectQuery.Expr(exprs...)
 	return q
 }
 
@@ -127,6 +164,20 @@ func (q Query) Distinct() Query {
 	return q
 }
 
+// With defines a common table expression that can be referenced as a table in the query.
+func (q Query) With(name string, query Query) Query {
+	NewWith(name, query).Build(&q)
+
+	return q
+}
+
+// WithRecursive defines a recursive common table expression that can be referenced as a table in the query.
+func (q Query) WithRecursive(name string, query Query, recursive Query) Query {
+	NewWithRecursive(name, query, recursive).Build(&q)
+
+	return q
+}
+
 // Join current table with other table.
 func (q Query) Join(table string) Query {
 	return q.JoinOn(table, "", "")
@@ -151,6 +202,13 @@ func (q Query) Joinf(expr string, args ...interface{}) Query {
 	return q
 }
 
+// JoinAssoc current table with belongs to or has one association.
+func (q Query) JoinAssoc(assoc string) Query {
+	NewJoinAssoc(assoc).Build(&q)
+
+	return q
+}
+
 // Where query.
 func (q Query) Where(filters ...FilterQuery) Query {
 	q.WhereQuery = q.WhereQuery.And(filters...)
@@ -175,6 +233,38 @@ func (q Query) OrWheref(expr string, args ...interface{}) Query {
 	return q
 }
 
+// Union combines result with another query, removing duplicate rows.
+// Sort, offset and limit of this query are applied to the combined result.
+func (q Query) Union(query Query) Query {
+	NewUnion(query).Build(&q)
+
+	return q
+}
+
+// UnionAll combines result with another query, including duplicate rows.
+// Sort, offset and limit of this query are applied to the combined result.
+func (q Query) UnionAll(query Query) Query {
+	NewUnionAll(query).Build(&q)
+
+	return q
+}
+
+// Intersect keeps rows that are also returned by another query.
+// Sort, offset and limit of this query are applied to the combined result.
+func (q Query) Intersect(query Query) Query {
+	NewIntersect(query).Build(&q)
+
+	return q
+}
+
+// Except removes rows that are returned by another query.
+// Sort, offset and limit of this query are applied to the combined result.
+func (q Query) Except(query Query) Query {
+	NewExcept(query).Build(&q)
+
+	return q
+}
+
 // Group query.
 func (q Query) Group(fields ...string) Query {
 	q.GroupQuery.Fields = fields
@@ -256,18 +346,36 @@ func (q Query) Lock(lock string) Query {
 	return q
 }
 
-// Unscoped allows soft-delete to be ignored.
+// Unscoped allows soft-delete and other default scopes to be ignored.
 func (q Query) Unscoped() Query {
 	q.UnscopedQuery = true
 	return q
 }
 
+// Scope applies named scopes registered for the model.
+func (q Query) Scope(names ...string) Query {
+	q.ScopeQuery = append(q.ScopeQuery, names...)
+	return q
+}
+
+// Unscope disables default scopes by name.
+func (q Query) Unscope(names ...string) Query {
+	q.UnscopeQuery = append(q.UnscopeQuery, names...)
+	return q
+}
+
 // Reload force reloading association on preload.
 func (q Query) Reload() Query {
 	q.ReloadQuery = true
 	return q
 }
 
+// SkipCount skips the count query when paginating.
+func (q Query) SkipCount() Query {
+	q.SkipCountQuery = true
+	return q
+}
+
 // Select query create a query with chainable syntax, using select as the starting point.
 func Select(fields ...string) Query {
 	return Query{
@@ -284,6 +392,28 @@ func From(table string) Query {
 	}
 }
 
+// FromQuery create a query with chainable syntax, using subquery aliased as table as the starting point.
+func FromQuery(alias string, query Query) Query {
+	return Query{
+		Table:      alias,
+		TableQuery: &query,
+	}
+}
+
+// With create a query with chainable syntax, using common table expression as the starting point.
+func With(name string, query Query) Query {
+	return Query{
+		WithQuery: []WithQuery{NewWith(name, query)},
+	}
+}
+
+// WithRecursive create a query with chainable syntax, using recursive common table expression as the starting point.
+func WithRecursive(name string, query Query, recursive Query) Query {
+	return Query{
+		WithQuery: []WithQuery{NewWithRecursive(name, query, recursive)},
+	}
+}
+
 // Join create a query with chainable syntax, using join as the starting point.
 func Join(table string) Query {
 	return JoinOn(table, "", "")
@@ -321,6 +451,15 @@ func Joinf(expr string, args ...interface{}) Query {
 	// return q
 }
 
+// JoinAssoc create a query with chainable syntax, using join association as the starting point.
+func JoinAssoc(assoc string) Query {
+	return Query{
+		JoinQuery: []JoinQuery{
+			NewJoinAssoc(assoc),
+		},
+	}
+}
+
 // Where create a query with chainable syntax, using where as the starting point.
 func Where(filters ...FilterQuery) Query {
 	return Query{
@@ -145,6 +145,21 @@ func TestQuerier(t *testing.T) {
 				LockQuery:   "FOR UPDATE",
 			},
 		},
+		{
+			name: "where id=1 skip count",
+			queriers: [][]rel.Querier{
+				{
+					where.Eq("id", 1), rel.SkipCount(true),
+				},
+				{
+					rel.Where(where.Eq("id", 1)).SkipCount(),
+				},
+			},
+			query: rel.Query{
+				WhereQuery:     where.Eq("id", 1),
+				SkipCountQuery: true,
+			},
+		},
 		{
 			name: "sql query",
 			queriers: [][]rel.Querier{
@@ -198,6 +213,99 @@ func TestQuery_Distinct(t *testing.T) {
 	}, rel.From("users").Select("*").Distinct())
 }
 
+func TestQuery_FromQuery(t *testing.T) {
+	var (
+		sub    = rel.From("users").Where(where.Gt("age", 20))
+		result = rel.Query{
+			Table:      "adults",
+			TableQuery: &sub,
+			WhereQuery: where.Eq("name", "rel"),
+		}
+	)
+
+	assert.Equal(t, result, rel.FromQuery("adults", sub).Where(where.Eq("name", "rel")))
+	assert.Equal(t, result, rel.Where(where.Eq("name", "rel")).FromQuery("adults", sub))
+	assert.Equal(t, result, rel.Build("users", rel.FromQuery("adults", sub), rel.Where(where.Eq("name", "rel"))))
+	assert.Equal(t, rel.From("users"), rel.FromQuery("adults", sub).From("users"))
+}
+
+func TestQuery_With(t *testing.T) {
+	var (
+		sub    = rel.From("users").Where(where.Eq("active", true))
+		result = rel.Query{
+			Table: "active_users",
+			WithQuery: []rel.WithQuery{
+				{Name: "active_users", Query: sub},
+			},
+		}
+	)
+
+	assert.Equal(t, result, rel.With("active_users", sub).From("active_users"))
+	assert.Equal(t, result, rel.From("active_users").With("active_users", sub))
+	assert.Equal(t, result, rel.Build("active_users", rel.NewWith("active_users", sub)))
+	assert.Equal(t, result, rel.Build("", rel.From("active_users"), rel.With("active_users", sub)))
+}
+
+func TestQuery_WithRecursive(t *testing.T) {
+	var (
+		sub       = rel.From("categories").Where(where.Nil("parent_id"))
+		recursive = rel.Select("categories.*").From("categories").JoinOn("tree", "tree.id", "categories.parent_id")
+		result    = rel.Query{
+			Table: "tree",
+			WithQuery: []rel.WithQuery{
+				{Name: "tree", Query: sub, Recursive: &recursive},
+			},
+		}
+	)
+
+	assert.Equal(t, result, rel.WithRecursive("tree", sub, recursive).From("tree"))
+	assert.Equal(t, result, rel.From("tree").WithRecursive("tree", sub, recursive))
+}
+
+func TestQuery_SelectExpr(t *testing.T) {
+	var (
+		rank   = rel.Rank().Over(rel.Window("game_id").SortDesc("score")).As("rank")
+		result = rel.Query{
+			Table: "scores",
+			SelectQuery: rel.SelectQuery{
+				Fields: []string{"id", "score"},
+				Exprs:  []rel.SelectExpr{rank},
+			},
+		}
+	)
+
+	assert.Equal(t, result, rel.From("scores").Select("id", "score").SelectExpr(rank))
+	assert.Equal(t, result, rel.From("scores").SelectExpr(rank).Select("id", "score"))
+	assert.Equal(t, result, rel.Build("", rel.From("scores"), rel.Select("id", "score").SelectExpr(rank)))
+	assert.Equal(t, result, rel.Build("", rel.Select("id", "score").SelectExpr(rank), rel.From("scores")))
+}
+
+func TestQuery_Combine(t *testing.T) {
+	var (
+		photos = rel.Select("id", "title").From("photos")
+		videos = rel.Select("id", "title").From("videos")
+		result = rel.Query{
+			Table:       "posts",
+			SelectQuery: rel.NewSelect("id", "title"),
+			CombineQuery: []rel.CombineQuery{
+				{Op: "UNION", Query: photos},
+				{Op: "UNION ALL", Query: videos},
+				{Op: "INTERSECT", Query: photos},
+				{Op: "EXCEPT", Query: videos},
+			},
+			SortQuery:  []rel.SortQuery{rel.NewSortDesc("title")},
+			LimitQuery: 10,
+		}
+	)
+
+	assert.Equal(t, result, rel.Select("id", "title").From("posts").
+		Union(photos).UnionAll(videos).Intersect(photos).Except(videos).
+		SortDesc("title").Limit(10))
+	assert.Equal(t, result, rel.Build("posts", rel.Select("id", "title"),
+		rel.NewUnion(photos), rel.NewUnionAll(videos), rel.NewIntersect(photos), rel.NewExcept(videos),
+		rel.NewSortDesc("title"), rel.Limit(10)))
+}
+
 func TestQuery_Join(t *testing.T) {
 	result := rel.Query{
 		Table: "users",
@@ -214,6 +322,21 @@ func TestQuery_Join(t *testing.T) {
 	assert.Equal(t, result, rel.Build("users", rel.Join("transactions")))
 }
 
+func TestQuery_JoinAssoc(t *testing.T) {
+	result := rel.Query{
+		Table: "transactions",
+		JoinQuery: []rel.JoinQuery{
+			{
+				Mode:  "LEFT JOIN",
+				Assoc: "buyer",
+			},
+		},
+	}
+
+	assert.Equal(t, result, rel.From("transactions").JoinAssoc("buyer"))
+	assert.Equal(t, result, rel.JoinAssoc("buyer").From("transactions"))
+}
+
 func TestQuery_JoinOn(t *testing.T) {
 	result := rel.Query{
 		Table: "users",
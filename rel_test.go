@@ -100,3 +100,55 @@ type UserRole struct {
 	UserID int `db:",primary"`
 	RoleID int `db:",primary"`
 }
+
+type Todo struct {
+	ID    int
+	Title string
+	Tags  []Tag `through:"todo_tags" autosave:"true"`
+}
+
+type Tag struct {
+	ID   int
+	Name string
+}
+
+type Video struct {
+	ID        int
+	Title     string
+	Comments  []Comment  `polymorphic:"owner" autosave:"true"`
+	Thumbnail Attachment `polymorphic:"attachable" autosave:"true"`
+}
+
+type Photo struct {
+	ID       int
+	Comments []Comment `polymorphic:"owner"`
+}
+
+type Comment struct {
+	ID        int
+	Body      string
+	OwnerType string
+	OwnerID   int
+	Video     *Video `polymorphic:"owner" autosave:"true"`
+	Photo     *Photo `polymorphic:"owner"`
+}
+
+type Attachment struct {
+	ID             int
+	URL            string
+	AttachableType string
+	AttachableID   int
+}
+
+type Ticket struct {
+	ID          int
+	Name        string
+	LockVersion int
+}
+
+type Post struct {
+	ID          int
+	Title       string
+	LockVersion uint
+	DeletedAt   *time.Time
+}
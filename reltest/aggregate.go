@@ -1,6 +1,12 @@
 package reltest
 
-import "github.com/go-rel/rel"
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-rel/rel"
+	"github.com/stretchr/testify/mock"
+)
 
 // Aggregate asserts and simulate aggregate function for test.
 type Aggregate struct {
@@ -32,6 +38,47 @@ func ExpectAggregate(r *Repository, query rel.Query, aggregate string, field str
 	}
 }
 
+// AggregateInto asserts and simulate aggregate into function for test.
+type AggregateInto struct {
+	*Expect
+}
+
+// Result sets the result of this query.
+func (ai *AggregateInto) Result(result interface{}) {
+	ai.Arguments[1] = mock.AnythingOfType(fmt.Sprintf("*%T", result))
+
+	ai.Run(func(args mock.Arguments) {
+		reflect.ValueOf(args[1]).Elem().Set(reflect.ValueOf(result))
+	})
+}
+
+// ExpectAggregateInto to be called with given field and queries.
+func ExpectAggregateInto(r *Repository, query rel.Query, aggregate string, field string) *AggregateInto {
+	return &AggregateInto{
+		Expect: newExpect(r, "AggregateInto",
+			[]interface{}{r.ctxData, mock.Anything, query, aggregate, field},
+			[]interface{}{nil},
+		),
+	}
+}
+
+// AggregateAll asserts and simulate aggregate all function for test.
+type AggregateAll struct {
+	*FindAll
+}
+
+// ExpectAggregateAll to be called with given query.
+func ExpectAggregateAll(r *Repository, query rel.Query) *AggregateAll {
+	return &AggregateAll{
+		FindAll: &FindAll{
+			Expect: newExpect(r, "AggregateAll",
+				[]interface{}{r.ctxData, mock.Anything, query},
+				[]interface{}{nil},
+			),
+		},
+	}
+}
+
 // ExpectCount to be called with given field and queries.
 func ExpectCount(r *Repository, collection string, queriers []rel.Querier) *Aggregate {
 	return &Aggregate{
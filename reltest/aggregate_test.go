@@ -84,3 +84,78 @@ func TestAggregate_Count_error(t *testing.T) {
 	})
 	repo.AssertExpectations(t)
 }
+
+func TestAggregateInto(t *testing.T) {
+	var (
+		repo = New()
+		avg  float64
+	)
+
+	repo.ExpectAggregateInto(rel.From("books"), "avg", "price").Result(12.5)
+	assert.Nil(t, repo.AggregateInto(context.TODO(), &avg, rel.From("books"), "avg", "price"))
+	assert.Equal(t, 12.5, avg)
+	repo.AssertExpectations(t)
+
+	repo.ExpectAggregateInto(rel.From("books"), "avg", "price").Result(10.0)
+	assert.NotPanics(t, func() {
+		repo.MustAggregateInto(context.TODO(), &avg, rel.From("books"), "avg", "price")
+	})
+	assert.Equal(t, 10.0, avg)
+	repo.AssertExpectations(t)
+}
+
+func TestAggregateInto_error(t *testing.T) {
+	var (
+		repo = New()
+		avg  float64
+	)
+
+	repo.ExpectAggregateInto(rel.From("books"), "avg", "price").ConnectionClosed()
+	assert.Equal(t, sql.ErrConnDone, repo.AggregateInto(context.TODO(), &avg, rel.From("books"), "avg", "price"))
+	repo.AssertExpectations(t)
+
+	repo.ExpectAggregateInto(rel.From("books"), "avg", "price").ConnectionClosed()
+	assert.Panics(t, func() {
+		repo.MustAggregateInto(context.TODO(), &avg, rel.From("books"), "avg", "price")
+	})
+	repo.AssertExpectations(t)
+}
+
+func TestAggregateAll(t *testing.T) {
+	var (
+		repo   = New()
+		query  = rel.From("books").Group("author").SelectExpr(rel.Count("id").As("count"))
+		result []map[string]interface{}
+		expect = []map[string]interface{}{{"author": "rel", "count": 2}}
+	)
+
+	repo.ExpectAggregateAll(query).Result(expect)
+	assert.Nil(t, repo.AggregateAll(context.TODO(), &result, query))
+	assert.Equal(t, expect, result)
+	repo.AssertExpectations(t)
+
+	repo.ExpectAggregateAll(query).Result(expect)
+	assert.NotPanics(t, func() {
+		repo.MustAggregateAll(context.TODO(), &result, query)
+	})
+	assert.Equal(t, expect, result)
+	repo.AssertExpectations(t)
+}
+
+func TestAggregateAll_error(t *testing.T) {
+	var (
+		repo   = New()
+		query  = rel.From("books").Group("author").SelectExpr(rel.Count("id").As("count"))
+		result []map[string]interface{}
+	)
+
+	repo.ExpectAggregateAll(query).ConnectionClosed()
+	assert.Equal(t, sql.ErrConnDone, repo.AggregateAll(context.TODO(), &result, query))
+	repo.AssertExpectations(t)
+
+	repo.ExpectAggregateAll(query).ConnectionClosed()
+	assert.Panics(t, func() {
+		repo.MustAggregateAll(context.TODO(), &result, query)
+	})
+	repo.AssertExpectations(t)
+}
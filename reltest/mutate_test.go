@@ -200,6 +200,24 @@ func TestMutate_InsertAll(t *testing.T) {
 	repo.AssertExpectations(t)
 }
 
+func TestMutate_InsertAll_onConflict(t *testing.T) {
+	var (
+		repo    = New()
+		results = []Book{
+			{Title: "Golang for dummies"},
+			{Title: "Rel for dummies"},
+		}
+	)
+
+	repo.ExpectInsertAllWith(rel.OnConflictIgnore())
+	assert.Nil(t, repo.InsertAll(context.TODO(), &results, rel.OnConflictIgnore()))
+	repo.AssertExpectations(t)
+
+	repo.ExpectInsertAllWith(rel.OnConflictReplace()).ConnectionClosed()
+	assert.Equal(t, ErrConnectionClosed, repo.InsertAll(context.TODO(), &results, rel.OnConflictReplace()))
+	repo.AssertExpectations(t)
+}
+
 func TestMutate_Update(t *testing.T) {
 	var (
 		repo   = New()
@@ -32,11 +32,11 @@ func (na *nopAdapter) Delete(ctx context.Context, query rel.Query) (int, error)
 	return 1, nil
 }
 
-func (na *nopAdapter) Insert(ctx context.Context, query rel.Query, primaryField string, mutates map[string]rel.Mutate) (interface{}, error) {
+func (na *nopAdapter) Insert(ctx context.Context, query rel.Query, primaryFields []string, mutates map[string]rel.Mutate, onConflict rel.OnConflict) (interface{}, error) {
 	return 1, nil
 }
 
-func (na *nopAdapter) InsertAll(ctx context.Context, query rel.Query, primaryField string, fields []string, bulkMutates []map[string]rel.Mutate) ([]interface{}, error) {
+func (na *nopAdapter) InsertAll(ctx context.Context, query rel.Query, primaryFields []string, fields []string, bulkMutates []map[string]rel.Mutate, onConflict rel.OnConflict) ([]interface{}, error) {
 	var (
 		ids = make([]interface{}, len(bulkMutates))
 	)
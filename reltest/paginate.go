@@ -0,0 +1,43 @@
+package reltest
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-rel/rel"
+	"github.com/stretchr/testify/mock"
+)
+
+// Paginate asserts and simulate paginate function for test.
+type Paginate struct {
+	*Expect
+}
+
+// Result sets the records and page of this query.
+func (p *Paginate) Result(records interface{}, page rel.Page) {
+	p.Arguments[1] = mock.AnythingOfType(fmt.Sprintf("*%T", records))
+
+	p.Run(func(args mock.Arguments) {
+		reflect.ValueOf(args[1]).Elem().Set(reflect.ValueOf(records))
+	}).Return(page, nil)
+}
+
+// Error sets error to be returned.
+func (p *Paginate) Error(err error) {
+	p.Return(rel.Page{}, err)
+}
+
+// ConnectionClosed sets this error to be returned.
+func (p *Paginate) ConnectionClosed() {
+	p.Error(ErrConnectionClosed)
+}
+
+// ExpectPaginate to be called with given page, per page and queries.
+func ExpectPaginate(r *Repository, page int, perPage int, queriers []rel.Querier) *Paginate {
+	return &Paginate{
+		Expect: newExpect(r, "Paginate",
+			[]interface{}{r.ctxData, mock.Anything, page, perPage, queriers},
+			[]interface{}{rel.Page{}, nil},
+		),
+	}
+}
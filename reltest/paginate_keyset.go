@@ -0,0 +1,43 @@
+package reltest
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-rel/rel"
+	"github.com/stretchr/testify/mock"
+)
+
+// PaginateKeyset asserts and simulate keyset pagination function for test.
+type PaginateKeyset struct {
+	*Expect
+}
+
+// Result sets the records and page of this query.
+func (pk *PaginateKeyset) Result(records interface{}, page rel.KeysetPage) {
+	pk.Arguments[1] = mock.AnythingOfType(fmt.Sprintf("*%T", records))
+
+	pk.Run(func(args mock.Arguments) {
+		reflect.ValueOf(args[1]).Elem().Set(reflect.ValueOf(records))
+	}).Return(page, nil)
+}
+
+// Error sets error to be returned.
+func (pk *PaginateKeyset) Error(err error) {
+	pk.Return(rel.KeysetPage{}, err)
+}
+
+// ConnectionClosed sets this error to be returned.
+func (pk *PaginateKeyset) ConnectionClosed() {
+	pk.Error(ErrConnectionClosed)
+}
+
+// ExpectPaginateKeyset to be called with given cursor, limit and queries.
+func ExpectPaginateKeyset(r *Repository, after string, limit int, queriers []rel.Querier) *PaginateKeyset {
+	return &PaginateKeyset{
+		Expect: newExpect(r, "PaginateKeyset",
+			[]interface{}{r.ctxData, mock.Anything, after, limit, queriers},
+			[]interface{}{rel.KeysetPage{}, nil},
+		),
+	}
+}
@@ -0,0 +1,59 @@
+package reltest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginateKeyset(t *testing.T) {
+	var (
+		repo   = New()
+		result []Book
+		books  = []Book{
+			{ID: 1, Title: "Golang for dummies"},
+			{ID: 2, Title: "Rel for dummies"},
+		}
+		query = rel.Where(where.Like("title", "%dummies%"))
+		page  = rel.KeysetPage{Next: "WzJd", HasNext: true}
+	)
+
+	repo.ExpectPaginateKeyset("", 2, query).Result(books, page)
+
+	actual, err := repo.PaginateKeyset(context.TODO(), &result, "", 2, query)
+	assert.Nil(t, err)
+	assert.Equal(t, page, actual)
+	assert.Equal(t, books, result)
+	repo.AssertExpectations(t)
+
+	repo.ExpectPaginateKeyset("", 2, query).Result(books, page)
+	assert.NotPanics(t, func() {
+		assert.Equal(t, page, repo.MustPaginateKeyset(context.TODO(), &result, "", 2, query))
+		assert.Equal(t, books, result)
+	})
+	repo.AssertExpectations(t)
+}
+
+func TestPaginateKeyset_error(t *testing.T) {
+	var (
+		repo   = New()
+		result []Book
+		query  = rel.Where(where.Like("title", "%dummies%"))
+	)
+
+	repo.ExpectPaginateKeyset("WzJd", 2, query).ConnectionClosed()
+
+	page, err := repo.PaginateKeyset(context.TODO(), &result, "WzJd", 2, query)
+	assert.Equal(t, ErrConnectionClosed, err)
+	assert.Equal(t, rel.KeysetPage{}, page)
+	repo.AssertExpectations(t)
+
+	repo.ExpectPaginateKeyset("WzJd", 2, query).ConnectionClosed()
+	assert.Panics(t, func() {
+		repo.MustPaginateKeyset(context.TODO(), &result, "WzJd", 2, query)
+	})
+	repo.AssertExpectations(t)
+}
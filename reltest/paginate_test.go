@@ -0,0 +1,59 @@
+package reltest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginate(t *testing.T) {
+	var (
+		repo   = New()
+		result []Book
+		books  = []Book{
+			{ID: 1, Title: "Golang for dummies"},
+			{ID: 2, Title: "Rel for dummies"},
+		}
+		query = rel.Where(where.Like("title", "%dummies%"))
+		page  = rel.Page{Number: 1, PerPage: 2, Total: 3, TotalPages: 2, HasNext: true}
+	)
+
+	repo.ExpectPaginate(1, 2, query).Result(books, page)
+
+	actual, err := repo.Paginate(context.TODO(), &result, 1, 2, query)
+	assert.Nil(t, err)
+	assert.Equal(t, page, actual)
+	assert.Equal(t, books, result)
+	repo.AssertExpectations(t)
+
+	repo.ExpectPaginate(1, 2, query).Result(books, page)
+	assert.NotPanics(t, func() {
+		assert.Equal(t, page, repo.MustPaginate(context.TODO(), &result, 1, 2, query))
+		assert.Equal(t, books, result)
+	})
+	repo.AssertExpectations(t)
+}
+
+func TestPaginate_error(t *testing.T) {
+	var (
+		repo   = New()
+		result []Book
+		query  = rel.Where(where.Like("title", "%dummies%"))
+	)
+
+	repo.ExpectPaginate(2, 2, query).ConnectionClosed()
+
+	page, err := repo.Paginate(context.TODO(), &result, 2, 2, query)
+	assert.Equal(t, ErrConnectionClosed, err)
+	assert.Equal(t, rel.Page{}, page)
+	repo.AssertExpectations(t)
+
+	repo.ExpectPaginate(2, 2, query).ConnectionClosed()
+	assert.Panics(t, func() {
+		repo.MustPaginate(context.TODO(), &result, 2, 2, query)
+	})
+	repo.AssertExpectations(t)
+}
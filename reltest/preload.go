@@ -1,6 +1,7 @@
 package reltest
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
 
@@ -11,6 +12,7 @@ import (
 // Preload asserts and simulate preload function for test.
 type Preload struct {
 	*Expect
+	links map[interface{}][]interface{}
 }
 
 // Result sets the result of Preload query.
@@ -22,10 +24,21 @@ func (p *Preload) Result(records interface{}) {
 			path   = strings.Split(args[2].(string), ".")
 		)
 
-		preload(target, result, path)
+		preload(target, result, path, p.links)
 	})
 }
 
+// Link declares rows of join table used to preload many to many association.
+// Result is only assigned to the record which reference value is linked to its foreign value.
+func (p *Preload) Link(reference interface{}, foreigns ...interface{}) *Preload {
+	if p.links == nil {
+		p.links = make(map[interface{}][]interface{})
+	}
+
+	p.links[reference] = append(p.links[reference], foreigns...)
+	return p
+}
+
 // For match expect calls for given record.
 func (p *Preload) For(record interface{}) *Preload {
 	p.Arguments[1] = record
@@ -74,7 +87,7 @@ func asSlice(v interface{}, readonly bool) slice {
 	return sl
 }
 
-func preload(target slice, result slice, path []string) {
+func preload(target slice, result slice, path []string, links map[interface{}][]interface{}) {
 	type frame struct {
 		index int
 		doc   *rel.Document
@@ -107,7 +120,7 @@ func preload(target slice, result slice, path []string) {
 				fField = assocs.ForeignField()
 			)
 
-			if rValue == nil {
+			if rValue == nil || !polymorphicMatch(top.doc, assocs) {
 				continue
 			}
 
@@ -119,6 +132,25 @@ func preload(target slice, result slice, path []string) {
 
 			curr.Reset()
 
+			// result of many to many association is assigned using the declared links.
+			if hasMany && assocs.Through() != "" {
+				if mappedResult == nil {
+					mappedResult = mapResult(result, fField, false)
+				}
+
+				var (
+					rv = curr.ReflectValue()
+				)
+
+				for _, fValue := range links[rValue] {
+					if value, ok := mappedResult[fValue]; ok {
+						rv.Set(reflect.Append(rv, value))
+					}
+				}
+
+				continue
+			}
+
 			if mappedResult == nil {
 				mappedResult = mapResult(result, fField, hasMany)
 			}
@@ -155,6 +187,15 @@ func preload(target slice, result slice, path []string) {
 	}
 }
 
+func polymorphicMatch(doc *rel.Document, assoc rel.Association) bool {
+	if assoc.PolymorphicType() == "" || assoc.Type() != rel.BelongsTo {
+		return true
+	}
+
+	typeValue, _ := doc.Value(assoc.PolymorphicType())
+	return fmt.Sprint(typeValue) == assoc.PolymorphicValue()
+}
+
 func mapResult(result slice, fField string, hasMany bool) map[interface{}]reflect.Value {
 	var (
 		mapResult = make(map[interface{}]reflect.Value)
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"testing"
 
+	"github.com/go-rel/rel"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -150,6 +151,59 @@ func TestPreload_nilReferenceValue(t *testing.T) {
 	repo.AssertExpectations(t)
 }
 
+func TestPreload_manyToMany(t *testing.T) {
+	type Shelf struct {
+		ID    int
+		Books []Book `through:"shelf_books"`
+	}
+
+	var (
+		repo   = New()
+		result = []Shelf{{ID: 1}, {ID: 2}}
+		books  = []Book{
+			{ID: 1, Title: "Golang for dummies"},
+			{ID: 2, Title: "Rel for dummies"},
+		}
+	)
+
+	repo.ExpectPreload("books").Link(1, 1, 2).Link(2, 2).Result(books)
+	assert.Nil(t, repo.Preload(context.TODO(), &result, "books"))
+	assert.Equal(t, books, result[0].Books)
+	assert.Equal(t, books[1:], result[1].Books)
+	repo.AssertExpectations(t)
+
+	// not linked.
+	repo.ExpectPreload("books").Result(books)
+	assert.Nil(t, repo.Preload(context.TODO(), &result, "books"))
+	assert.Empty(t, result[0].Books)
+	assert.Empty(t, result[1].Books)
+	repo.AssertExpectations(t)
+}
+
+func TestPreload_polymorphic(t *testing.T) {
+	type Comment struct {
+		ID        int
+		OwnerType string
+		OwnerID   int
+		Book      *Book `polymorphic:"owner"`
+	}
+
+	var (
+		repo   = New()
+		result = []Comment{
+			{ID: 1, OwnerType: "books", OwnerID: 1},
+			{ID: 2, OwnerType: "authors", OwnerID: 1},
+		}
+		book = Book{ID: 1, Title: "Rel for dummies"}
+	)
+
+	repo.ExpectPreload("book").Result(book)
+	assert.Nil(t, repo.Preload(context.TODO(), &result, "book"))
+	assert.Equal(t, &book, result[0].Book)
+	assert.Nil(t, result[1].Book)
+	repo.AssertExpectations(t)
+}
+
 func TestPreload_For(t *testing.T) {
 	var (
 		repo     = New()
@@ -203,3 +257,49 @@ func TestPreload_error(t *testing.T) {
 	})
 	repo.AssertExpectations(t)
 }
+
+func TestPreloadTree(t *testing.T) {
+	var (
+		repo    = New()
+		result  = Author{ID: 1, Name: "Kia"}
+		books   = []Book{{ID: 2, Title: "Rel for dummies", AuthorID: &result.ID}}
+		ratings = []Rating{{ID: 3, Score: 5, BookID: 2}}
+	)
+
+	repo.ExpectPreload("books", rel.Eq("views", 0)).Result(books)
+	repo.ExpectPreload("books.ratings").Result(ratings)
+	assert.Nil(t, repo.PreloadTree(context.TODO(), &result,
+		rel.NewPreload("books", rel.Eq("views", 0)).With(
+			rel.NewPreload("ratings"),
+		),
+	))
+	assert.Len(t, result.Books, 1)
+	assert.Equal(t, ratings, result.Books[0].Ratings)
+	repo.AssertExpectations(t)
+
+	repo.ExpectPreload("books", rel.Eq("views", 0)).Result(books)
+	repo.ExpectPreload("books.ratings").Result(ratings)
+	assert.NotPanics(t, func() {
+		repo.MustPreloadTree(context.TODO(), &result,
+			rel.NewPreload("books", rel.Eq("views", 0)).With(
+				rel.NewPreload("ratings"),
+			),
+		)
+	})
+	repo.AssertExpectations(t)
+}
+
+func TestPreloadTree_error(t *testing.T) {
+	var (
+		repo   = New()
+		result = Author{ID: 1, Name: "Kia"}
+	)
+
+	repo.ExpectPreload("books").ConnectionClosed()
+	assert.Equal(t, sql.ErrConnDone, repo.PreloadTree(context.TODO(), &result,
+		rel.NewPreload("books").With(
+			rel.NewPreload("ratings"),
+		),
+	))
+	repo.AssertExpectations(t)
+}
@@ -35,7 +35,8 @@ func (r *Repository) Ping(ctx context.Context) error {
 
 // Iterate through a collection of records from database in batches.
 // This function returns iterator that can be used to loop all records.
-// Limit, Offset and Sort query is automatically ignored.
+// Limit and Offset query is automatically ignored. Sort query is ignored too unless Keyset option is used,
+// in which case records are sorted by the sort query followed by primary fields as tie breaker.
 func (r *Repository) Iterate(ctx context.Context, query rel.Query, options ...rel.IteratorOption) rel.Iterator {
 	ret := r.mock.Called(fetchContext(ctx), query, options).Get(0)
 	return (*iterator)(ret.(*Iterate))
@@ -65,6 +66,38 @@ func (r *Repository) ExpectAggregate(query rel.Query, aggregate string, field st
 	return ExpectAggregate(r, query, aggregate, field)
 }
 
+// AggregateInto provides a mock function with given fields: result, query, aggregate, field
+func (r *Repository) AggregateInto(ctx context.Context, result interface{}, query rel.Query, aggregate string, field string) error {
+	r.repo.AggregateInto(ctx, result, query, aggregate, field)
+	return r.mock.Called(fetchContext(ctx), result, query, aggregate, field).Error(0)
+}
+
+// MustAggregateInto provides a mock function with given fields: result, query, aggregate, field
+func (r *Repository) MustAggregateInto(ctx context.Context, result interface{}, query rel.Query, aggregate string, field string) {
+	must(r.AggregateInto(ctx, result, query, aggregate, field))
+}
+
+// ExpectAggregateInto apply mocks and expectations for AggregateInto
+func (r *Repository) ExpectAggregateInto(query rel.Query, aggregate string, field string) *AggregateInto {
+	return ExpectAggregateInto(r, query, aggregate, field)
+}
+
+// AggregateAll provides a mock function with given fields: records, query
+func (r *Repository) AggregateAll(ctx context.Context, records interface{}, query rel.Query) error {
+	r.repo.AggregateAll(ctx, records, query)
+	return r.mock.Called(fetchContext(ctx), records, query).Error(0)
+}
+
+// MustAggregateAll provides a mock function with given fields: records, query
+func (r *Repository) MustAggregateAll(ctx context.Context, records interface{}, query rel.Query) {
+	must(r.AggregateAll(ctx, records, query))
+}
+
+// ExpectAggregateAll apply mocks and expectations for AggregateAll
+func (r *Repository) ExpectAggregateAll(query rel.Query) *AggregateAll {
+	return ExpectAggregateAll(r, query)
+}
+
 // Count provides a mock function with given fields: collection, queriers
 func (r *Repository) Count(ctx context.Context, collection string, queriers ...rel.Querier) (int, error) {
 	r.repo.Count(ctx, collection, queriers...)
@@ -135,6 +168,60 @@ func (r *Repository) ExpectFindAndCountAll(queriers ...rel.Querier) *FindAndCoun
 	return ExpectFindAndCountAll(r, queriers)
 }
 
+// Paginate provides a mock function with given fields: records, page, perPage, queriers
+func (r *Repository) Paginate(ctx context.Context, records interface{}, page int, perPage int, queriers ...rel.Querier) (rel.Page, error) {
+	r.repo.Paginate(ctx, records, page, perPage, queriers...)
+	ret := r.mock.Called(fetchContext(ctx), records, page, perPage, queriers)
+	return ret.Get(0).(rel.Page), ret.Error(1)
+}
+
+// MustPaginate provides a mock function with given fields: records, page, perPage, queriers
+func (r *Repository) MustPaginate(ctx context.Context, records interface{}, page int, perPage int, queriers ...rel.Querier) rel.Page {
+	result, err := r.Paginate(ctx, records, page, perPage, queriers...)
+	must(err)
+	return result
+}
+
+// ExpectPaginate apply mocks and expectations for Paginate
+func (r *Repository) ExpectPaginate(page int, perPage int, queriers ...rel.Querier) *Paginate {
+	return ExpectPaginate(r, page, perPage, queriers)
+}
+
+// PaginateKeyset provides a mock function with given fields: records, after, limit, queriers
+func (r *Repository) PaginateKeyset(ctx context.Context, records interface{}, after string, limit int, queriers ...rel.Querier) (rel.KeysetPage, error) {
+	r.repo.PaginateKeyset(ctx, records, after, limit, queriers...)
+	ret := r.mock.Called(fetchContext(ctx), records, after, limit, queriers)
+	return ret.Get(0).(rel.KeysetPage), ret.Error(1)
+}
+
+// MustPaginateKeyset provides a mock function with given fields: records, after, limit, queriers
+func (r *Repository) MustPaginateKeyset(ctx context.Context, records interface{}, after string, limit int, queriers ...rel.Querier) rel.KeysetPage {
+	page, err := r.PaginateKeyset(ctx, records, after, limit, queriers...)
+	must(err)
+	return page
+}
+
+// ExpectPaginateKeyset apply mocks and expectations for PaginateKeyset
+func (r *Repository) ExpectPaginateKeyset(after string, limit int, queriers ...rel.Querier) *PaginateKeyset {
+	return ExpectPaginateKeyset(r, after, limit, queriers)
+}
+
+// Scan provides a mock function with given fields: result, query
+func (r *Repository) Scan(ctx context.Context, result interface{}, query rel.Query) error {
+	r.repo.Scan(ctx, result, query)
+	return r.mock.Called(fetchContext(ctx), result, query).Error(0)
+}
+
+// MustScan provides a mock function with given fields: result, query
+func (r *Repository) MustScan(ctx context.Context, result interface{}, query rel.Query) {
+	must(r.Scan(ctx, result, query))
+}
+
+// ExpectScan apply mocks and expectations for Scan
+func (r *Repository) ExpectScan(query rel.Query) *Scan {
+	return ExpectScan(r, query)
+}
+
 // Insert provides a mock function with given fields: record, mutators
 func (r *Repository) Insert(ctx context.Context, record interface{}, mutators ...rel.Mutator) error {
 	ret := r.mock.Called(fetchContext(ctx), record, mutators)
@@ -154,16 +241,16 @@ func (r *Repository) ExpectInsert(mutators ...rel.Mutator) *Mutate {
 }
 
 // InsertAll records.
-func (r *Repository) InsertAll(ctx context.Context, records interface{}) error {
-	ret := r.mock.Called(fetchContext(ctx), records)
+func (r *Repository) InsertAll(ctx context.Context, records interface{}, mutators ...rel.Mutator) error {
+	ret := r.mock.Called(fetchContext(ctx), records, mutators)
 
-	r.repo.InsertAll(ctx, records)
+	r.repo.InsertAll(ctx, records, mutators...)
 	return ret.Error(0)
 }
 
 // MustInsertAll records.
-func (r *Repository) MustInsertAll(ctx context.Context, records interface{}) {
-	must(r.InsertAll(ctx, records))
+func (r *Repository) MustInsertAll(ctx context.Context, records interface{}, mutators ...rel.Mutator) {
+	must(r.InsertAll(ctx, records, mutators...))
 }
 
 // ExpectInsertAll records.
@@ -171,6 +258,11 @@ func (r *Repository) ExpectInsertAll() *Mutate {
 	return ExpectInsertAll(r)
 }
 
+// ExpectInsertAllWith records inserted using the given mutators.
+func (r *Repository) ExpectInsertAllWith(mutators ...rel.Mutator) *Mutate {
+	return ExpectInsertAllWith(r, mutators)
+}
+
 // Update provides a mock function with given fields: record, mutators
 func (r *Repository) Update(ctx context.Context, record interface{}, mutators ...rel.Mutator) error {
 	ret := r.mock.Called(fetchContext(ctx), record, mutators)
@@ -252,8 +344,26 @@ func (r *Repository) ExpectPreload(field string, queriers ...rel.Querier) *Prelo
 	return ExpectPreload(r, field, queriers)
 }
 
+// PreloadTree loads association tree by calling Preload for every association breadth-first.
+// Use ExpectPreload with the full path of each association to mock it.
+func (r *Repository) PreloadTree(ctx context.Context, records interface{}, preloads ...rel.PreloadQuery) error {
+	for _, pq := range rel.FlattenPreloads(preloads) {
+		if err := r.Preload(ctx, records, pq.Field, pq.Queriers...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MustPreloadTree loads association tree by calling Preload for every association breadth-first.
+func (r *Repository) MustPreloadTree(ctx context.Context, records interface{}, preloads ...rel.PreloadQuery) {
+	must(r.PreloadTree(ctx, records, preloads...))
+}
+
 // Transaction provides a mock function with given fields: fn
-func (r *Repository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+// Transaction options are ignored, fn is only called once.
+func (r *Repository) Transaction(ctx context.Context, fn func(ctx context.Context) error, options ...rel.TransactionOption) error {
 	ctxData := fetchContext(ctx)
 	r.mock.Called(ctxData)
 
@@ -0,0 +1,25 @@
+package reltest
+
+import (
+	"github.com/go-rel/rel"
+	"github.com/stretchr/testify/mock"
+)
+
+// Scan asserts and simulate scan function for test.
+type Scan struct {
+	*Find
+}
+
+// ExpectScan to be called with given query.
+func ExpectScan(r *Repository, query rel.Query) *Scan {
+	return &Scan{
+		Find: &Find{
+			FindAll: &FindAll{
+				Expect: newExpect(r, "Scan",
+					[]interface{}{r.ctxData, mock.Anything, query},
+					[]interface{}{nil},
+				),
+			},
+		},
+	}
+}
@@ -0,0 +1,48 @@
+package reltest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScan(t *testing.T) {
+	var (
+		repo   = New()
+		query  = rel.Select("id").From("books").Where(where.Eq("author", "rel"))
+		result []int
+	)
+
+	repo.ExpectScan(query).Result([]int{1, 2})
+	assert.Nil(t, repo.Scan(context.TODO(), &result, query))
+	assert.Equal(t, []int{1, 2}, result)
+	repo.AssertExpectations(t)
+
+	repo.ExpectScan(query).Result([]int{3})
+	assert.NotPanics(t, func() {
+		repo.MustScan(context.TODO(), &result, query)
+	})
+	assert.Equal(t, []int{3}, result)
+	repo.AssertExpectations(t)
+}
+
+func TestScan_error(t *testing.T) {
+	var (
+		repo   = New()
+		query  = rel.From("books").Where(where.Eq("id", 1))
+		result map[string]interface{}
+	)
+
+	repo.ExpectScan(query).NotFound()
+	assert.Equal(t, rel.NotFoundError{}, repo.Scan(context.TODO(), &result, query))
+	repo.AssertExpectations(t)
+
+	repo.ExpectScan(query).ConnectionClosed()
+	assert.Panics(t, func() {
+		repo.MustScan(context.TODO(), &result, query)
+	})
+	repo.AssertExpectations(t)
+}
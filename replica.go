@@ -0,0 +1,61 @@
+package rel
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// ReplicaSelector picks a replica adapter to run a read query.
+type ReplicaSelector func(ctx context.Context, replicas []Adapter) Adapter
+
+// RoundRobin returns replica selector that picks replicas in turn.
+func RoundRobin() ReplicaSelector {
+	var (
+		next uint64
+	)
+
+	return func(ctx context.Context, replicas []Adapter) Adapter {
+		return replicas[(atomic.AddUint64(&next, 1)-1)%uint64(len(replicas))]
+	}
+}
+
+// WithPrimary returns a copy of context that forces read queries to use the primary adapter.
+// It's useful to read records right after it's written, before it's replicated.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey, true)
+}
+
+// NewWithReplicas create new repo that writes using primary adapter, and reads using one of replica adapters.
+// Find, FindAll, Count, Aggregate, Iterate, Preload and other read operations are run on a replica picked by the selector,
+// and replicas are picked in turn when selector is nil.
+// Operations inside transaction and read operations using context returned by WithPrimary are always run on primary adapter.
+func NewWithReplicas(primary Adapter, replicas []Adapter, selector ReplicaSelector) Repository {
+	if selector == nil {
+		selector = RoundRobin()
+	}
+
+	repo := &repository{
+		rootAdapter:     primary,
+		replicaAdapters: replicas,
+		replicaSelector: selector,
+		instrumenter:    DefaultLogger,
+	}
+
+	repo.Instrumentation(DefaultLogger)
+
+	return repo
+}
+
+// fetchReadContext is like fetchContext, but uses a replica adapter when it's not inside transaction and primary is not requested.
+func (r repository) fetchReadContext(ctx context.Context) contextWrapper {
+	var (
+		cw = fetchContext(ctx, r.rootAdapter)
+	)
+
+	if len(r.replicaAdapters) == 0 || ctx.Value(ctxKey) != nil || ctx.Value(primaryKey) != nil {
+		return cw
+	}
+
+	cw.adapter = r.replicaSelector(ctx, r.replicaAdapters)
+	return cw
+}
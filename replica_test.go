@@ -0,0 +1,196 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundRobin(t *testing.T) {
+	var (
+		replica1 = &testAdapter{}
+		replica2 = &testAdapter{}
+		replicas = []Adapter{replica1, replica2}
+		selector = RoundRobin()
+	)
+
+	assert.Same(t, replica1, selector(context.TODO(), replicas))
+	assert.Same(t, replica2, selector(context.TODO(), replicas))
+	assert.Same(t, replica1, selector(context.TODO(), replicas))
+}
+
+func TestNewWithReplicas(t *testing.T) {
+	var (
+		primary  = &testAdapter{}
+		replica  = &testAdapter{}
+		repo     = NewWithReplicas(primary, []Adapter{replica}, nil)
+		ctx      = context.TODO()
+		selected Adapter
+	)
+
+	assert.Equal(t, primary, repo.Adapter(ctx))
+
+	repo = NewWithReplicas(primary, []Adapter{replica}, func(ctx context.Context, replicas []Adapter) Adapter {
+		selected = replicas[0]
+		return selected
+	})
+
+	assert.Equal(t, replica, repo.(*repository).fetchReadContext(ctx).adapter)
+	assert.Equal(t, replica, selected)
+}
+
+func TestRepository_replicaRead(t *testing.T) {
+	var (
+		user     User
+		users    []User
+		primary  = &testAdapter{}
+		replica1 = &testAdapter{}
+		replica2 = &testAdapter{}
+		repo     = NewWithReplicas(primary, []Adapter{replica1, replica2}, nil)
+		ctx      = context.TODO()
+		cur1     = createCursor(1)
+		cur2     = createCursor(2)
+	)
+
+	replica1.On("Query", From("users").Limit(1)).Return(cur1, nil).Once()
+	replica2.On("Query", From("users")).Return(cur2, nil).Once()
+	replica1.On("Aggregate", From("users"), "count", "*").Return(2, nil).Once()
+	replica2.On("Aggregate", From("users"), "max", "age").Return(20, nil).Once()
+
+	assert.Nil(t, repo.Find(ctx, &user))
+	assert.Nil(t, repo.FindAll(ctx, &users))
+	assert.Equal(t, 2, repo.MustCount(ctx, "users"))
+	assert.Equal(t, 20, repo.MustAggregate(ctx, From("users"), "max", "age"))
+
+	primary.AssertExpectations(t)
+	replica1.AssertExpectations(t)
+	replica2.AssertExpectations(t)
+}
+
+func TestRepository_replicaIterateAndPreload(t *testing.T) {
+	var (
+		user    = User{ID: 10}
+		users   User
+		primary = &testAdapter{}
+		replica = &testAdapter{}
+		repo    = NewWithReplicas(primary, []Adapter{replica}, nil)
+		ctx     = context.TODO()
+		cur1    = createCursor(1)
+		cur2    = &testCursor{}
+	)
+
+	cur2.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur2.On("Next").Return(false).Once()
+	cur2.On("Close").Return(nil).Once()
+
+	replica.On("Query", From("users").SortAsc("id").Limit(1000)).Return(cur1, nil).Once()
+	replica.On("Query", From("transactions").Where(In("user_id", 10))).Return(cur2, nil).Once()
+
+	it := repo.Iterate(ctx, From("users"))
+	assert.Nil(t, it.Next(&users))
+	assert.Equal(t, io.EOF, it.Next(&users))
+	assert.Nil(t, it.Close())
+
+	assert.Nil(t, repo.Preload(ctx, &user, "transactions"))
+
+	primary.AssertExpectations(t)
+	replica.AssertExpectations(t)
+	cur1.AssertExpectations(t)
+	cur2.AssertExpectations(t)
+}
+
+func TestRepository_replicaWrite(t *testing.T) {
+	var (
+		user    = User{ID: 1, Name: "name"}
+		primary = &testAdapter{}
+		replica = &testAdapter{}
+		repo    = NewWithReplicas(primary, []Adapter{replica}, nil)
+		ctx     = context.TODO()
+	)
+
+	primary.On("Update", From("users").Where(Eq("id", 1)), map[string]Mutate{"name": Set("name", "rel")}).Return(1, nil).Once()
+	primary.On("Delete", From("users").Where(Eq("id", 1))).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Update(ctx, &user, Set("name", "rel")))
+	assert.Nil(t, repo.DeleteAll(ctx, From("users").Where(Eq("id", 1))))
+
+	primary.AssertExpectations(t)
+	replica.AssertExpectations(t)
+}
+
+func TestRepository_replicaWithPrimary(t *testing.T) {
+	var (
+		user    User
+		primary = &testAdapter{}
+		replica = &testAdapter{}
+		repo    = NewWithReplicas(primary, []Adapter{replica}, nil)
+		ctx     = WithPrimary(context.TODO())
+		cur     = createCursor(1)
+	)
+
+	primary.On("Query", From("users").Limit(1)).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Find(ctx, &user))
+
+	primary.AssertExpectations(t)
+	replica.AssertExpectations(t)
+}
+
+func TestRepository_replicaTransaction(t *testing.T) {
+	var (
+		user    User
+		primary = &testAdapter{}
+		replica = &testAdapter{}
+		repo    = NewWithReplicas(primary, []Adapter{replica}, nil)
+		cur     = createCursor(1)
+	)
+
+	primary.On("Begin").Return(nil).Once()
+	primary.On("Query", From("users").Limit(1)).Return(cur, nil).Once()
+	primary.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		return repo.Find(ctx, &user)
+	}))
+
+	primary.AssertExpectations(t)
+	replica.AssertExpectations(t)
+}
+
+func TestRepository_replicaPing(t *testing.T) {
+	var (
+		err     = errors.New("error")
+		primary = &testAdapter{}
+		replica = &testAdapter{}
+		repo    = NewWithReplicas(primary, []Adapter{replica}, nil)
+	)
+
+	primary.On("Ping").Return(nil).Twice()
+	replica.On("Ping").Return(nil).Once()
+	replica.On("Ping").Return(err).Once()
+
+	assert.Nil(t, repo.Ping(context.TODO()))
+	assert.Equal(t, err, repo.Ping(context.TODO()))
+
+	primary.AssertExpectations(t)
+	replica.AssertExpectations(t)
+}
+
+func TestRepository_replicaPing_primaryError(t *testing.T) {
+	var (
+		err     = errors.New("error")
+		primary = &testAdapter{}
+		replica = &testAdapter{}
+		repo    = NewWithReplicas(primary, []Adapter{replica}, nil)
+	)
+
+	primary.On("Ping").Return(err).Once()
+
+	assert.Equal(t, err, repo.Ping(context.TODO()))
+
+	primary.AssertExpectations(t)
+	replica.AssertExpectations(t)
+}
@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"runtime"
 	"strings"
+	"time"
 )
 
 // Repository defines sets of available database operations.
@@ -16,6 +17,10 @@ type Repository interface {
 	Iterate(ctx context.Context, query Query, option ...IteratorOption) Iterator
 	Aggregate(ctx context.Context, query Query, aggregate string, field string) (int, error)
 	MustAggregate(ctx context.Context, query Query, aggregate string, field string) int
+	AggregateInto(ctx context.Context, result interface{}, query Query, aggregate string, field string) error
+	MustAggregateInto(ctx context.Context, result interface{}, query Query, aggregate string, field string)
+	AggregateAll(ctx context.Context, records interface{}, query Query) error
+	MustAggregateAll(ctx context.Context, records interface{}, query Query)
 	Count(ctx context.Context, collection string, queriers ...Querier) (int, error)
 	MustCount(ctx context.Context, collection string, queriers ...Querier) int
 	Find(ctx context.Context, record interface{}, queriers ...Querier) error
@@ -24,10 +29,16 @@ type Repository interface {
 	MustFindAll(ctx context.Context, records interface{}, queriers ...Querier)
 	FindAndCountAll(ctx context.Context, records interface{}, queriers ...Querier) (int, error)
 	MustFindAndCountAll(ctx context.Context, records interface{}, queriers ...Querier) int
+	Paginate(ctx context.Context, records interface{}, page int, perPage int, queriers ...Querier) (Page, error)
+	MustPaginate(ctx context.Context, records interface{}, page int, perPage int, queriers ...Querier) Page
+	PaginateKeyset(ctx context.Context, records interface{}, after string, limit int, queriers ...Querier) (KeysetPage, error)
+	MustPaginateKeyset(ctx context.Context, records interface{}, after string, limit int, queriers ...Querier) KeysetPage
+	Scan(ctx context.Context, result interface{}, query Query) error
+	MustScan(ctx context.Context, result interface{}, query Query)
 	Insert(ctx context.Context, record interface{}, mutators ...Mutator) error
 	MustInsert(ctx context.Context, record interface{}, mutators ...Mutator)
-	InsertAll(ctx context.Context, records interface{}) error
-	MustInsertAll(ctx context.Context, records interface{})
+	InsertAll(ctx context.Context, records interface{}, mutators ...Mutator) error
+	MustInsertAll(ctx context.Context, records interface{}, mutators ...Mutator)
 	Update(ctx context.Context, record interface{}, mutators ...Mutator) error
 	MustUpdate(ctx context.Context, record interface{}, mutators ...Mutator)
 	UpdateAll(ctx context.Context, query Query, mutates ...Mutate) error
@@ -38,12 +49,16 @@ type Repository interface {
 	MustDeleteAll(ctx context.Context, query Query)
 	Preload(ctx context.Context, records interface{}, field string, queriers ...Querier) error
 	MustPreload(ctx context.Context, records interface{}, field string, queriers ...Querier)
-	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+	PreloadTree(ctx context.Context, records interface{}, preloads ...PreloadQuery) error
+	MustPreloadTree(ctx context.Context, records interface{}, preloads ...PreloadQuery)
+	Transaction(ctx context.Context, fn func(ctx context.Context) error, options ...TransactionOption) error
 }
 
 type repository struct {
-	rootAdapter  Adapter
-	instrumenter Instrumenter
+	rootAdapter     Adapter
+	replicaAdapters []Adapter
+	replicaSelector ReplicaSelector
+	instrumenter    Instrumenter
 }
 
 func (r repository) Adapter(ctx context.Context) Adapter {
@@ -53,19 +68,34 @@ func (r repository) Adapter(ctx context.Context) Adapter {
 func (r *repository) Instrumentation(instrumenter Instrumenter) {
 	r.instrumenter = instrumenter
 	r.rootAdapter.Instrumentation(instrumenter)
+
+	for _, replica := range r.replicaAdapters {
+		replica.Instrumentation(instrumenter)
+	}
 }
 
-// Ping database.
+// Ping database, including the replicas.
 func (r *repository) Ping(ctx context.Context) error {
-	return r.rootAdapter.Ping(ctx)
+	if err := r.rootAdapter.Ping(ctx); err != nil {
+		return err
+	}
+
+	for _, replica := range r.replicaAdapters {
+		if err := replica.Ping(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // Iterate through a collection of records from database in batches.
 // This function returns iterator that can be used to loop all records.
-// Limit, Offset and Sort query is automatically ignored.
+// Limit and Offset query is automatically ignored. Sort query is ignored too unless Keyset option is used,
+// in which case records are sorted by the sort query followed by primary fields as tie breaker.
 func (r repository) Iterate(ctx context.Context, query Query, options ...IteratorOption) Iterator {
 	var (
-		cw = fetchContext(ctx, r.rootAdapter)
+		cw = r.fetchReadContext(ctx)
 	)
 
 	return newIterator(cw.ctx, cw.adapter, query, options)
@@ -74,25 +104,36 @@ func (r repository) Iterate(ctx context.Context, query Query, options ...Iterato
 // Aggregate calculate aggregate over the given field.
 // Supported aggregate: count, sum, avg, max, min.
 // Any select, group, offset, limit and sort query will be ignored automatically.
+// Query combined using union, intersect or except is aggregated over the combined result.
 // If complex aggregation is needed, consider using All instead,
 func (r repository) Aggregate(ctx context.Context, query Query, aggregate string, field string) (int, error) {
 	finish := r.instrumenter.Observe(ctx, "rel-aggregate", "aggregating records")
 	defer finish(nil)
 
 	var (
-		cw = fetchContext(ctx, r.rootAdapter)
+		cw = r.fetchReadContext(ctx)
 	)
 
-	return r.aggregate(cw, query, aggregate, field)
+	return r.aggregate(cw, withTenant(cw.tenant, query), aggregate, field)
 }
 
 func (r repository) aggregate(cw contextWrapper, query Query, aggregate string, field string) (int, error) {
-	query.GroupQuery = GroupQuery{}
+	return cw.adapter.Aggregate(cw.ctx, aggregateQuery(query), aggregate, field)
+}
+
+func aggregateQuery(query Query) Query {
+	query.JoinQuery = withoutJoinAssoc(query.JoinQuery)
 	query.LimitQuery = 0
 	query.OffsetQuery = 0
 	query.SortQuery = nil
 
-	return cw.adapter.Aggregate(cw.ctx, query, aggregate, field)
+	if len(query.CombineQuery) > 0 {
+		// aggregate over the combined result.
+		return FromQuery(query.Table, query)
+	}
+
+	query.GroupQuery = GroupQuery{}
+	return query
 }
 
 // MustAggregate calculate aggregate over the given field.
@@ -103,16 +144,76 @@ func (r repository) MustAggregate(ctx context.Context, query Query, aggregate st
 	return result
 }
 
+// AggregateInto calculate aggregate over the given field and scan the result into a pointer.
+// Unlike Aggregate, the result can be of any type supported by the database driver, such as float, decimal or time.
+// Result is set to zero value when the aggregate returns null.
+// Any select, group, offset, limit and sort query will be ignored automatically.
+func (r repository) AggregateInto(ctx context.Context, result interface{}, query Query, aggregate string, field string) error {
+	finish := r.instrumenter.Observe(ctx, "rel-aggregate-into", "aggregating records")
+	defer finish(nil)
+
+	var (
+		cw = r.fetchReadContext(ctx)
+	)
+
+	query = aggregateQuery(withTenant(cw.tenant, query))
+	query.SelectQuery = NewSelect(aggregate + "(" + field + ") AS " + aggregate)
+
+	cur, err := cw.adapter.Query(cw.ctx, query)
+	if err != nil {
+		return err
+	}
+
+	return scanValue(cur, result)
+}
+
+// MustAggregateInto calculate aggregate over the given field and scan the result into a pointer.
+// It'll panic if any error eccured.
+func (r repository) MustAggregateInto(ctx context.Context, result interface{}, query Query, aggregate string, field string) {
+	must(r.AggregateInto(ctx, result, query, aggregate, field))
+}
+
+// AggregateAll calculate multiple aggregates for every group, and scan the result into a slice of struct or map[string]interface{}.
+// Aggregates are defined using select expression of the query, eg: Sum("total").As("total").
+// Group fields are selected when no fields is selected explicitly.
+func (r repository) AggregateAll(ctx context.Context, records interface{}, query Query) error {
+	finish := r.instrumenter.Observe(ctx, "rel-aggregate-all", "aggregating records")
+	defer finish(nil)
+
+	var (
+		cw = r.fetchReadContext(ctx)
+	)
+
+	query = withTenant(cw.tenant, query)
+	query.JoinQuery = withoutJoinAssoc(query.JoinQuery)
+	if len(query.SelectQuery.Fields) == 0 {
+		query.SelectQuery.Fields = query.GroupQuery.Fields
+	}
+
+	cur, err := cw.adapter.Query(cw.ctx, query)
+	if err != nil {
+		return err
+	}
+
+	return scanRecords(cur, records)
+}
+
+// MustAggregateAll calculate multiple aggregates for every group, and scan the result into a slice of struct or map[string]interface{}.
+// It'll panic if any error eccured.
+func (r repository) MustAggregateAll(ctx context.Context, records interface{}, query Query) {
+	must(r.AggregateAll(ctx, records, query))
+}
+
 // Count retrieves count of results that match the query.
 func (r repository) Count(ctx context.Context, collection string, queriers ...Querier) (int, error) {
 	finish := r.instrumenter.Observe(ctx, "rel-count", "aggregating records")
 	defer finish(nil)
 
 	var (
-		cw = fetchContext(ctx, r.rootAdapter)
+		cw = r.fetchReadContext(ctx)
 	)
 
-	return r.aggregate(cw, Build(collection, queriers...), "count", "*")
+	return r.aggregate(cw, withTenant(cw.tenant, applyScopes(Build(collection, queriers...))), "count", "*")
 }
 
 // MustCount retrieves count of results that match the query.
@@ -130,7 +231,7 @@ func (r repository) Find(ctx context.Context, record interface{}, queriers ...Qu
 	defer finish(nil)
 
 	var (
-		cw    = fetchContext(ctx, r.rootAdapter)
+		cw    = r.fetchReadContext(ctx)
 		doc   = NewDocument(record)
 		query = Build(doc.Table(), queriers...)
 	)
@@ -145,7 +246,11 @@ func (r repository) MustFind(ctx context.Context, record interface{}, queriers .
 }
 
 func (r repository) find(cw contextWrapper, doc *Document, query Query) error {
-	query = r.withDefaultScope(doc.data, query)
+	if err := checkTenantQuery(cw.tenant, doc.data.flag, query); err != nil {
+		return err
+	}
+
+	query = r.withJoinAssoc(doc.rt, doc.data, r.withScope(cw, doc.data, query))
 	cur, err := cw.adapter.Query(cw.ctx, query.Limit(1))
 	if err != nil {
 		return err
@@ -154,7 +259,11 @@ func (r repository) find(cw contextWrapper, doc *Document, query Query) error {
 	finish := r.instrumenter.Observe(cw.ctx, "rel-scan-one", "scanning a record")
 	defer finish(nil)
 
-	return scanOne(cur, doc)
+	if err := scanOne(cur, doc); err != nil {
+		return err
+	}
+
+	return afterFind(cw.ctx, doc)
 }
 
 // FindAll records that match the query.
@@ -163,7 +272,7 @@ func (r repository) FindAll(ctx context.Context, records interface{}, queriers .
 	defer finish(nil)
 
 	var (
-		cw    = fetchContext(ctx, r.rootAdapter)
+		cw    = r.fetchReadContext(ctx)
 		col   = NewCollection(records)
 		query = Build(col.Table(), queriers...)
 	)
@@ -180,7 +289,11 @@ func (r repository) MustFindAll(ctx context.Context, records interface{}, querie
 }
 
 func (r repository) findAll(cw contextWrapper, col *Collection, query Query) error {
-	query = r.withDefaultScope(col.data, query)
+	if err := checkTenantQuery(cw.tenant, col.data.flag, query); err != nil {
+		return err
+	}
+
+	query = r.withJoinAssoc(col.rt.Elem(), col.data, r.withScope(cw, col.data, query))
 	cur, err := cw.adapter.Query(cw.ctx, query)
 	if err != nil {
 		return err
@@ -189,7 +302,11 @@ func (r repository) findAll(cw contextWrapper, col *Collection, query Query) err
 	finish := r.instrumenter.Observe(cw.ctx, "rel-scan-all", "scanning all records")
 	defer finish(nil)
 
-	return scanAll(cur, col)
+	if err := scanAll(cur, col); err != nil {
+		return err
+	}
+
+	return afterFindAll(cw.ctx, col)
 }
 
 // FindAndCountAll is convenient method that combines FindAll and Count. It's useful when dealing with queries related to pagination.
@@ -199,7 +316,7 @@ func (r repository) FindAndCountAll(ctx context.Context, records interface{}, qu
 	defer finish(nil)
 
 	var (
-		cw    = fetchContext(ctx, r.rootAdapter)
+		cw    = r.fetchReadContext(ctx)
 		col   = NewCollection(records)
 		query = Build(col.Table(), queriers...)
 	)
@@ -210,7 +327,7 @@ func (r repository) FindAndCountAll(ctx context.Context, records interface{}, qu
 		return 0, err
 	}
 
-	return r.aggregate(cw, query, "count", "*")
+	return r.aggregate(cw, r.withJoinAssoc(col.rt.Elem(), col.data, r.withScope(cw, col.data, query)), "count", "*")
 }
 
 // MustFindAndCountAll is convenient method that combines FindAll and Count. It's useful when dealing with queries related to pagination.
@@ -223,6 +340,166 @@ func (r repository) MustFindAndCountAll(ctx context.Context, records interface{}
 	return count
 }
 
+// Paginate fetch records of the given page number, starting from 1, and returns the pagination metadata.
+// Limit and Offset property of the query is replaced by the page.
+// Total records is counted using a separate query, unless SkipCount is specified.
+func (r repository) Paginate(ctx context.Context, records interface{}, page int, perPage int, queriers ...Querier) (Page, error) {
+	finish := r.instrumenter.Observe(ctx, "rel-paginate", "paginating records")
+	defer finish(nil)
+
+	if page <= 0 || perPage <= 0 {
+		panic("rel: page and per page must be greater than zero")
+	}
+
+	var (
+		cw    = r.fetchReadContext(ctx)
+		col   = NewCollection(records)
+		query = Build(col.Table(), queriers...)
+	)
+
+	query.OffsetQuery = Offset((page - 1) * perPage)
+	query.LimitQuery = Limit(perPage)
+
+	col.Reset()
+
+	if query.SkipCountQuery {
+		// fetch one more record to check whether next page exists.
+		query.LimitQuery++
+		if err := r.findAll(cw, col, query); err != nil {
+			return Page{}, err
+		}
+
+		var (
+			hasNext = col.Len() > perPage
+		)
+
+		if hasNext {
+			col.Truncate(0, perPage)
+		}
+
+		return Page{Number: page, PerPage: perPage, HasNext: hasNext, HasPrev: page > 1}, nil
+	}
+
+	if err := r.findAll(cw, col, query); err != nil {
+		return Page{}, err
+	}
+
+	total, err := r.aggregate(cw, r.withJoinAssoc(col.rt.Elem(), col.data, r.withScope(cw, col.data, query)), "count", "*")
+	if err != nil {
+		return Page{}, err
+	}
+
+	return newPage(page, perPage, total), nil
+}
+
+// MustPaginate fetch records of the given page number, starting from 1, and returns the pagination metadata.
+// It'll panic if any error eccured.
+func (r repository) MustPaginate(ctx context.Context, records interface{}, page int, perPage int, queriers ...Querier) Page {
+	result, err := r.Paginate(ctx, records, page, perPage, queriers...)
+	must(err)
+
+	return result
+}
+
+// PaginateKeyset fetch a page of records positioned after the given cursor, ordered by sort query of the query.
+// Primary fields are appended to the sort as tie breaker, and records are sorted by primary fields when no sort is given.
+// Use empty cursor to fetch the first page, and the returned next cursor to fetch the following page.
+// Unlike offset pagination, rows inserted or deleted while paginating are not skipped or repeated.
+func (r repository) PaginateKeyset(ctx context.Context, records interface{}, after string, limit int, queriers ...Querier) (KeysetPage, error) {
+	finish := r.instrumenter.Observe(ctx, "rel-paginate-keyset", "paginating records")
+	defer finish(nil)
+
+	if limit <= 0 {
+		panic("rel: limit must be greater than zero")
+	}
+
+	var (
+		page  KeysetPage
+		cw    = r.fetchReadContext(ctx)
+		col   = NewCollection(records)
+		query = Build(col.Table(), queriers...)
+		doc   = NewDocument(reflect.New(col.rt.Elem()))
+	)
+
+	query.SortQuery = keysetSorts(query.SortQuery, col.PrimaryFields())
+	if err := keysetCheck(doc, query.SortQuery); err != nil {
+		return page, err
+	}
+
+	if after != "" {
+		values, err := decodeKeyset(after, doc, query.SortQuery)
+		if err != nil {
+			return page, err
+		}
+
+		query = query.Where(keysetFilter(query.SortQuery, values))
+	}
+
+	// fetch one more record to check whether next page exists.
+	query.OffsetQuery = 0
+	query.LimitQuery = Limit(limit + 1)
+
+	col.Reset()
+	if err := r.findAll(cw, col, query); err != nil {
+		return page, err
+	}
+
+	if col.Len() > limit {
+		col.Truncate(0, limit)
+
+		values, err := keysetValues(col.Get(limit-1), query.SortQuery)
+		if err != nil {
+			return page, err
+		}
+
+		page.HasNext = true
+		page.Next, err = encodeKeyset(values)
+		if err != nil {
+			return page, err
+		}
+	}
+
+	return page, nil
+}
+
+// MustPaginateKeyset fetch a page of records positioned after the given cursor, ordered by sort query of the query.
+// It'll panic if any error eccured.
+func (r repository) MustPaginateKeyset(ctx context.Context, records interface{}, after string, limit int, queriers ...Querier) KeysetPage {
+	page, err := r.PaginateKeyset(ctx, records, after, limit, queriers...)
+	must(err)
+	return page
+}
+
+// Scan query result into a struct, map[string]interface{} or scalar value, or a slice of them.
+// Unlike Find and FindAll, the struct doesn't need to have table or primary key, which is useful for reporting query with join and group by.
+// Scalar value is scanned from the first column, and it'll return not found error if no result found when scanning into a non slice value.
+// Query must specify the table, default scope is not applied, but it's filtered by the tenant in context unless it's a raw SQL query.
+func (r repository) Scan(ctx context.Context, result interface{}, query Query) error {
+	finish := r.instrumenter.Observe(ctx, "rel-scan", "scanning query result")
+	defer finish(nil)
+
+	var (
+		cw = r.fetchReadContext(ctx)
+	)
+
+	if query.SQLQuery.Statement == "" {
+		query = withTenant(cw.tenant, query)
+	}
+
+	cur, err := cw.adapter.Query(cw.ctx, query)
+	if err != nil {
+		return err
+	}
+
+	return scanRecords(cur, result)
+}
+
+// MustScan query result into a struct, map[string]interface{} or scalar value, or a slice of them.
+// It'll panic if any error eccured.
+func (r repository) MustScan(ctx context.Context, result interface{}, query Query) {
+	must(r.Scan(ctx, result, query))
+}
+
 // Insert an record to database.
 func (r repository) Insert(ctx context.Context, record interface{}, mutators ...Mutator) error {
 	finish := r.instrumenter.Observe(ctx, "rel-insert", "inserting a record")
@@ -238,7 +515,7 @@ func (r repository) Insert(ctx context.Context, record interface{}, mutators ...
 		mutation = Apply(doc, mutators...)
 	)
 
-	if !mutation.IsAssocEmpty() && mutation.Cascade == true {
+	if (!mutation.IsAssocEmpty() && mutation.Cascade == true) || hasMutationHook(doc.v) {
 		return r.transaction(cw, func(cw contextWrapper) error {
 			return r.insert(cw, doc, mutation)
 		})
@@ -249,40 +526,74 @@ func (r repository) Insert(ctx context.Context, record interface{}, mutators ...
 
 func (r repository) insert(cw contextWrapper, doc *Document, mutation Mutation) error {
 	var (
-		pField   string
 		pFields  = doc.PrimaryFields()
 		queriers = Build(doc.Table())
 	)
 
+	if err := beforeInsert(cw.ctx, doc, &mutation); err != nil {
+		return err
+	}
+
+	if err := validate(doc, mutation.validators); err != nil {
+		return err
+	}
+
+	if err := setTenant(cw.tenant, doc, &mutation); err != nil {
+		return err
+	}
+
 	if mutation.Cascade {
 		if err := r.saveBelongsTo(cw, doc, &mutation); err != nil {
 			return err
 		}
 	}
 
-	if len(pFields) == 1 {
-		pField = pFields[0]
+	if err := generatePrimary(doc, &mutation); err != nil {
+		return err
 	}
 
-	pValue, err := cw.adapter.Insert(cw.ctx, queriers, pField, mutation.Mutates)
-	if err != nil {
-		return mutation.ErrorFunc.transform(err)
-	}
+	if adapter, ok := returningAdapter(cw.adapter); ok {
+		cur, err := adapter.InsertReturning(cw.ctx, queriers, mutation.Mutates, mutation.OnConflict)
+		if err != nil {
+			return mutation.transformError(err)
+		}
 
-	// update primary value
-	if pField != "" {
-		doc.SetValue(pField, pValue)
-	}
+		// inserted row is returned, no reload is required.
+		if err := scanOne(cur, doc); err != nil {
+			if _, notFound := err.(NotFoundError); !notFound || mutation.OnConflict.None() {
+				return err
+			}
 
-	if mutation.Reload {
-		var (
-			filter = filterDocument(doc)
-		)
+			// nothing is returned when the record is ignored due to conflict.
+			if err := r.findConflict(cw, doc, mutation.OnConflict); err != nil {
+				return err
+			}
+		}
+	} else {
+		pValue, err := cw.adapter.Insert(cw.ctx, queriers, pFields, mutation.Mutates, mutation.OnConflict)
+		if err != nil {
+			return mutation.transformError(err)
+		}
 
-		// fetch record
-		if err := r.find(cw, doc, queriers.Where(filter)); err != nil {
+		if !mutation.OnConflict.None() && isZero(pValue) {
+			// primary value is unknown when the record is ignored or updated due to conflict.
+			if err := r.findConflict(cw, doc, mutation.OnConflict); err != nil {
+				return err
+			}
+		} else if err := setPrimaryValue(doc, pFields, pValue); err != nil {
 			return err
 		}
+
+		if mutation.Reload {
+			var (
+				filter = filterDocument(doc)
+			)
+
+			// fetch record
+			if err := r.find(cw, doc, queriers.Where(filter)); err != nil {
+				return err
+			}
+		}
 	}
 
 	if mutation.Cascade {
@@ -295,7 +606,7 @@ func (r repository) insert(cw contextWrapper, doc *Document, mutation Mutation)
 		}
 	}
 
-	return nil
+	return afterInsert(cw.ctx, doc)
 }
 
 // MustInsert an record to database.
@@ -304,7 +615,9 @@ func (r repository) MustInsert(ctx context.Context, record interface{}, mutators
 	must(r.Insert(ctx, record, mutators...))
 }
 
-func (r repository) InsertAll(ctx context.Context, records interface{}) error {
+// InsertAll records.
+// Mutators can be used to define on conflict behaviour, other than that it'll always use structset.
+func (r repository) InsertAll(ctx context.Context, records interface{}, mutators ...Mutator) error {
 	finish := r.instrumenter.Observe(ctx, "rel-insert-all", "inserting multiple records")
 	defer finish(nil)
 
@@ -319,15 +632,26 @@ func (r repository) InsertAll(ctx context.Context, records interface{}) error {
 	)
 
 	for i := range muts {
-		doc := col.Get(i)
-		muts[i] = Apply(doc, newStructset(doc, false))
+		var (
+			doc = col.Get(i)
+		)
+
+		muts[i] = Apply(doc, append([]Mutator{newStructset(doc, false)}, mutators...)...)
+	}
+
+	if col.Len() > 0 && hasMutationHook(col.Get(0).v) {
+		return r.transaction(cw, func(cw contextWrapper) error {
+			return r.insertAll(cw, col, muts)
+		})
 	}
 
 	return r.insertAll(cw, col, muts)
 }
 
-func (r repository) MustInsertAll(ctx context.Context, records interface{}) {
-	must(r.InsertAll(ctx, records))
+// MustInsertAll records.
+// It'll panic if any error occurred.
+func (r repository) MustInsertAll(ctx context.Context, records interface{}, mutators ...Mutator) {
+	must(r.InsertAll(ctx, records, mutators...))
 }
 
 // TODO: support assocs
@@ -337,8 +661,8 @@ func (r repository) insertAll(cw contextWrapper, col *Collection, mutation []Mut
 	}
 
 	var (
-		pField      string
 		pFields     = col.PrimaryFields()
+		onConflict  = mutation[0].OnConflict
 		queriers    = Build(col.Table())
 		fields      = make([]string, 0, len(mutation[0].Mutates))
 		fieldMap    = make(map[string]struct{}, len(mutation[0].Mutates))
@@ -347,6 +671,22 @@ func (r repository) insertAll(cw contextWrapper, col *Collection, mutation []Mut
 
 	// TODO: baypassable if it's predictable.
 	for i := range mutation {
+		if err := beforeInsert(cw.ctx, col.Get(i), &mutation[i]); err != nil {
+			return err
+		}
+
+		if err := validate(col.Get(i), mutation[i].validators); err != nil {
+			return err
+		}
+
+		if err := setTenant(cw.tenant, col.Get(i), &mutation[i]); err != nil {
+			return err
+		}
+
+		if err := generatePrimary(col.Get(i), &mutation[i]); err != nil {
+			return err
+		}
+
 		for field := range mutation[i].Mutates {
 			if _, exist := fieldMap[field]; !exist {
 				fieldMap[field] = struct{}{}
@@ -356,25 +696,67 @@ func (r repository) insertAll(cw contextWrapper, col *Collection, mutation []Mut
 		bulkMutates[i] = mutation[i].Mutates
 	}
 
-	if len(pFields) == 1 {
-		pField = pFields[0]
-	}
-
-	ids, err := cw.adapter.InsertAll(cw.ctx, queriers, pField, fields, bulkMutates)
+	ids, err := cw.adapter.InsertAll(cw.ctx, queriers, pFields, fields, bulkMutates, onConflict)
 	if err != nil {
-		return mutation[0].ErrorFunc.transform(err)
+		return mutation[0].transformError(err)
 	}
 
-	// apply ids
-	if pField != "" {
+	if !onConflict.None() && len(ids) != col.Len() {
+		// ids can't be mapped to each record when some records are ignored or updated due to conflict.
+		for i := 0; i < col.Len(); i++ {
+			if err := r.findConflict(cw, col.Get(i), onConflict); err != nil {
+				return err
+			}
+		}
+	} else {
+		// apply ids
 		for i, id := range ids {
-			col.Get(i).SetValue(pField, id)
+			if err := setPrimaryValue(col.Get(i), pFields, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := 0; i < col.Len(); i++ {
+		if err := afterInsert(cw.ctx, col.Get(i)); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// findConflict reloads a record using on conflict keys.
+// When keys is not defined, the record is reloaded using its primary values if it's set,
+// otherwise the record can't be identified and ErrNotInserted is returned.
+func (r repository) findConflict(cw contextWrapper, doc *Document, onConflict OnConflict) error {
+	var (
+		filter FilterQuery
+		keys   = onConflict.Keys
+	)
+
+	if len(keys) == 0 {
+		keys = doc.PrimaryFields()
+	}
+
+	for _, key := range keys {
+		value, _ := doc.Value(key)
+		if len(onConflict.Keys) == 0 && isZero(value) {
+			return ErrNotInserted
+		}
+
+		filter = filter.And(Eq(key, value))
+	}
+
+	err := r.find(cw, doc, Build(doc.Table(), filter, Unscoped(true)))
+	if _, notFound := err.(NotFoundError); notFound && len(onConflict.Keys) == 0 {
+		// conflict happens on other unique key.
+		return ErrNotInserted
+	}
+
+	return err
+}
+
 // Update an record in database.
 // It'll panic if any error occurred.
 func (r repository) Update(ctx context.Context, record interface{}, mutators ...Mutator) error {
@@ -392,7 +774,7 @@ func (r repository) Update(ctx context.Context, record interface{}, mutators ...
 		mutation = Apply(doc, mutators...)
 	)
 
-	if !mutation.IsAssocEmpty() && mutation.Cascade == true {
+	if (!mutation.IsAssocEmpty() && mutation.Cascade == true) || hasMutationHook(doc.v) {
 		return r.transaction(cw, func(cw contextWrapper) error {
 			return r.update(cw, doc, mutation, filter)
 		})
@@ -402,6 +784,18 @@ func (r repository) Update(ctx context.Context, record interface{}, mutators ...
 }
 
 func (r repository) update(cw contextWrapper, doc *Document, mutation Mutation, filter FilterQuery) error {
+	if err := beforeUpdate(cw.ctx, doc, &mutation); err != nil {
+		return err
+	}
+
+	if err := validate(doc, mutation.validators); err != nil {
+		return err
+	}
+
+	if err := setTenant(cw.tenant, doc, &mutation); err != nil {
+		return err
+	}
+
 	if mutation.Cascade {
 		if err := r.saveBelongsTo(cw, doc, &mutation); err != nil {
 			return err
@@ -410,19 +804,49 @@ func (r repository) update(cw contextWrapper, doc *Document, mutation Mutation,
 
 	if !mutation.IsMutatesEmpty() {
 		var (
-			query = r.withDefaultScope(doc.data, Build(doc.Table(), filter, mutation.Unscoped))
+			query           = withDocumentTenant(cw.tenant, doc.data.flag, r.withDefaultScope(doc.data, Build(doc.Table(), filter, mutation.Unscoped)))
+			version, locked = lockVersion(doc)
+			notFoundErr     = error(NotFoundError{})
+			updateQuery     = query
 		)
 
-		if updatedCount, err := cw.adapter.Update(cw.ctx, query, mutation.Mutates); err != nil {
-			return mutation.ErrorFunc.transform(err)
-		} else if updatedCount == 0 {
-			return NotFoundError{}
+		if locked {
+			// optimistic locking, only update when the version is not changed.
+			updateQuery = query.Where(Eq("lock_version", version))
+			notFoundErr = StaleObjectError{}
+			mutation.Add(Set("lock_version", version+1))
 		}
 
-		if mutation.Reload {
-			if err := r.find(cw, doc, query); err != nil {
+		if adapter, ok := returningAdapter(cw.adapter); ok && bool(mutation.Reload) {
+			cur, err := adapter.UpdateReturning(cw.ctx, updateQuery, mutation.Mutates)
+			if err != nil {
+				return mutation.transformError(err)
+			}
+
+			// updated row is returned, no reload is required.
+			if err := scanOne(cur, doc); err != nil {
+				if _, ok := err.(NotFoundError); ok {
+					return notFoundErr
+				}
+
 				return err
 			}
+		} else {
+			if updatedCount, err := cw.adapter.Update(cw.ctx, updateQuery, mutation.Mutates); err != nil {
+				return mutation.transformError(err)
+			} else if updatedCount == 0 {
+				return notFoundErr
+			}
+
+			if locked {
+				doc.SetValue("lock_version", version+1)
+			}
+
+			if mutation.Reload {
+				if err := r.find(cw, doc, query); err != nil {
+					return err
+				}
+			}
 		}
 	}
 
@@ -436,7 +860,7 @@ func (r repository) update(cw contextWrapper, doc *Document, mutation Mutation,
 		}
 	}
 
-	return nil
+	return afterUpdate(cw.ctx, doc)
 }
 
 // MustUpdate an record in database.
@@ -483,6 +907,11 @@ func (r repository) saveBelongsTo(cw contextWrapper, doc *Document, mutation *Mu
 
 			mutation.Add(Set(rField, fValue))
 			doc.SetValue(rField, fValue)
+
+			if typeField := assoc.PolymorphicType(); typeField != "" {
+				mutation.Add(Set(typeField, assoc.PolymorphicValue()))
+				doc.SetValue(typeField, assoc.PolymorphicValue())
+			}
 		}
 	}
 
@@ -523,6 +952,7 @@ func (r repository) saveHasOne(cw contextWrapper, doc *Document, mutation *Mutat
 
 			assocMut.Add(Set(fField, rValue))
 			assocDoc.SetValue(fField, rValue)
+			setPolymorphicType(assoc, assocDoc, &assocMut)
 
 			if err := r.insert(cw, assocDoc, assocMut); err != nil {
 				return err
@@ -545,6 +975,14 @@ func (r repository) saveHasMany(cw contextWrapper, doc *Document, mutation *Muta
 			continue
 		}
 
+		if assoc.Through() != "" {
+			if err := r.saveManyToMany(cw, assoc, assocMuts, insertion); err != nil {
+				return err
+			}
+
+			continue
+		}
+
 		var (
 			col, _     = assoc.Collection()
 			table      = col.Table()
@@ -561,7 +999,7 @@ func (r repository) saveHasMany(cw contextWrapper, doc *Document, mutation *Muta
 
 		if !insertion {
 			var (
-				filter = Eq(fField, rValue)
+				filter = filterPolymorphic(assoc, Eq(fField, rValue))
 			)
 
 			if deletedIDs == nil {
@@ -589,7 +1027,7 @@ func (r repository) saveHasMany(cw contextWrapper, doc *Document, mutation *Muta
 			if deletedIDs != nil && !isZero(assocDoc.PrimaryValue()) {
 				var (
 					fValue, _ = assocDoc.Value(fField)
-					filter    = filterDocument(assocDoc).AndEq(fField, rValue)
+					filter    = filterPolymorphic(assoc, filterDocument(assocDoc).AndEq(fField, rValue))
 				)
 
 				if rValue != fValue {
@@ -613,6 +1051,7 @@ func (r repository) saveHasMany(cw contextWrapper, doc *Document, mutation *Muta
 			} else {
 				muts[i].Add(Set(fField, rValue))
 				assocDoc.SetValue(fField, rValue)
+				setPolymorphicType(assoc, assocDoc, &muts[i])
 			}
 		}
 
@@ -637,6 +1076,135 @@ func (r repository) saveHasMany(cw contextWrapper, doc *Document, mutation *Muta
 	return nil
 }
 
+// setPolymorphicType assigns the owner type to the target of polymorphic has one or has many association.
+func setPolymorphicType(assoc Association, assocDoc *Document, assocMut *Mutation) {
+	if typeField := assoc.PolymorphicType(); typeField != "" {
+		assocMut.Add(Set(typeField, assoc.PolymorphicValue()))
+		assocDoc.SetValue(typeField, assoc.PolymorphicValue())
+	}
+}
+
+// saveManyToMany saves the targets of many to many association and syncs the join table with the current targets.
+// Persisted targets are only updated when the mutation is tracked, such as built by changeset.
+func (r repository) saveManyToMany(cw contextWrapper, assoc Association, assocMuts AssocMutation, insertion bool) error {
+	var (
+		col, _   = assoc.Collection()
+		table    = assoc.ThroughTable()
+		fField   = assoc.ForeignField()
+		rValue   = assoc.ReferenceValue()
+		rThrough = assoc.ReferenceThrough()
+		fThrough = assoc.ForeignThrough()
+		muts     = assocMuts.Mutations
+		fValues  = make([]interface{}, 0, len(muts))
+		linked   = make(map[interface{}]bool, len(muts))
+	)
+
+	// this shouldn't happen unless there's bug in the mutator.
+	if len(muts) != col.Len() {
+		panic("rel: invalid mutator")
+	}
+
+	for i := range muts {
+		var (
+			assocDoc = col.Get(i)
+		)
+
+		if isZero(assocDoc.PrimaryValue()) {
+			if err := r.insert(cw, assocDoc, muts[i]); err != nil {
+				return err
+			}
+		} else if assocMuts.Tracked && !muts[i].IsMutatesEmpty() {
+			if err := r.update(cw, assocDoc, muts[i], filterDocument(assocDoc)); err != nil {
+				return err
+			}
+		}
+
+		if fValue, _ := assocDoc.Value(fField); !linked[fValue] {
+			linked[fValue] = false
+			fValues = append(fValues, fValue)
+		}
+	}
+
+	if !insertion {
+		links, err := r.linkedValues(cw, assoc)
+		if err != nil {
+			return err
+		}
+
+		var (
+			unlinked []interface{}
+		)
+
+		for _, fValue := range links {
+			if _, ok := linked[fValue]; ok {
+				linked[fValue] = true
+			} else {
+				unlinked = append(unlinked, fValue)
+			}
+		}
+
+		// remove links to targets that no longer exists in association.
+		if len(unlinked) > 0 {
+			if _, err := cw.adapter.Delete(cw.ctx, Build(table, Eq(rThrough, rValue).AndIn(fThrough, unlinked...))); err != nil {
+				return err
+			}
+		}
+	}
+
+	var (
+		fields      = []string{rThrough, fThrough}
+		bulkMutates = make([]map[string]Mutate, 0, len(fValues))
+	)
+
+	// existing links are kept as is.
+	for _, fValue := range fValues {
+		if !linked[fValue] {
+			bulkMutates = append(bulkMutates, map[string]Mutate{
+				rThrough: Set(rThrough, rValue),
+				fThrough: Set(fThrough, fValue),
+			})
+		}
+	}
+
+	if len(bulkMutates) == 0 {
+		return nil
+	}
+
+	_, err := cw.adapter.InsertAll(cw.ctx, Build(table), nil, fields, bulkMutates, OnConflict{})
+	return err
+}
+
+// linkedValues returns the foreign values of targets linked to the owner in join table of many to many association.
+func (r repository) linkedValues(cw contextWrapper, assoc Association) ([]interface{}, error) {
+	var (
+		fThrough = assoc.ForeignThrough()
+		fType    = assoc.foreignType()
+		query    = Build(assoc.ThroughTable(), Select(fThrough), Eq(assoc.ReferenceThrough(), assoc.ReferenceValue()))
+		values   []interface{}
+	)
+
+	cur, err := cw.adapter.Query(cw.ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cur.Close()
+
+	for cur.Next() {
+		var (
+			fValue = reflect.New(fType)
+		)
+
+		if err := cur.Scan(fValue.Interface()); err != nil {
+			return nil, err
+		}
+
+		values = append(values, fValue.Elem().Interface())
+	}
+
+	return values, nil
+}
+
 func (r repository) UpdateAll(ctx context.Context, query Query, mutates ...Mutate) error {
 	finish := r.instrumenter.Observe(ctx, "rel-update-all", "updating multiple records")
 	defer finish(nil)
@@ -651,8 +1219,12 @@ func (r repository) UpdateAll(ctx context.Context, query Query, mutates ...Mutat
 		muts[mut.Field] = mut
 	}
 
+	if mut, ok := muts["tenant_id"]; ok && cw.tenant != nil && !isTenantMutate(mut, cw.tenant) {
+		return ErrCrossTenant
+	}
+
 	if len(muts) > 0 {
-		_, err = cw.adapter.Update(cw.ctx, query, muts)
+		_, err = cw.adapter.Update(cw.ctx, withTenant(cw.tenant, applyScopes(query)), muts)
 	}
 
 	return err
@@ -677,7 +1249,7 @@ func (r repository) Delete(ctx context.Context, record interface{}, options ...C
 		cascade = options[0]
 	}
 
-	if cascade {
+	if bool(cascade) || hasMutationHook(doc.v) {
 		return r.transaction(cw, func(cw contextWrapper) error {
 			return r.delete(cw, doc, filterDocument(doc), cascade)
 		})
@@ -689,9 +1261,17 @@ func (r repository) Delete(ctx context.Context, record interface{}, options ...C
 func (r repository) delete(cw contextWrapper, doc *Document, filter FilterQuery, cascade Cascade) error {
 	var (
 		table = doc.Table()
-		query = Build(table, filter)
+		query = withDocumentTenant(cw.tenant, doc.data.flag, Build(table, filter))
 	)
 
+	if value, _ := doc.Value("tenant_id"); cw.tenant != nil && doc.Flag(HasTenantID) && !isZero(value) && !sameTenant(value, cw.tenant) {
+		return ErrCrossTenant
+	}
+
+	if err := beforeDelete(cw.ctx, doc); err != nil {
+		return err
+	}
+
 	if cascade {
 		if err := r.deleteHasOne(cw, doc, cascade); err != nil {
 			return err
@@ -702,18 +1282,34 @@ func (r repository) delete(cw contextWrapper, doc *Document, filter FilterQuery,
 		}
 	}
 
+	version, locked := lockVersion(doc)
+	if locked {
+		// optimistic locking, only delete when the version is not changed.
+		query = query.Where(Eq("lock_version", version))
+	}
+
 	deletedCount, err := r.deleteAll(cw, doc.data.flag, query)
 	if err == nil && deletedCount == 0 {
-		err = NotFoundError{}
+		if locked {
+			err = StaleObjectError{}
+		} else {
+			err = NotFoundError{}
+		}
+	} else if err == nil && locked && doc.Flag(HasDeletedAt) {
+		doc.SetValue("lock_version", version+1)
+	}
+
+	if err != nil {
+		return err
 	}
 
-	if err == nil && cascade {
+	if cascade {
 		if err := r.deleteBelongsTo(cw, doc, cascade); err != nil {
 			return err
 		}
 	}
 
-	return err
+	return afterDelete(cw.ctx, doc)
 }
 
 func (r repository) deleteBelongsTo(cw contextWrapper, doc *Document, cascade Cascade) error {
@@ -776,12 +1372,25 @@ func (r repository) deleteHasMany(cw contextWrapper, doc *Document) error {
 			continue
 		}
 
+		if assoc.Through() != "" {
+			var (
+				filter = Eq(assoc.ReferenceThrough(), assoc.ReferenceValue())
+			)
+
+			// only the links are deleted, targets of many to many association are kept.
+			if _, err := cw.adapter.Delete(cw.ctx, Build(assoc.ThroughTable(), filter)); err != nil {
+				return err
+			}
+
+			continue
+		}
+
 		if col, loaded := assoc.Collection(); loaded {
 			var (
 				table  = col.Table()
 				fField = assoc.ForeignField()
 				rValue = assoc.ReferenceValue()
-				filter = Eq(fField, rValue).And(filterCollection(col))
+				filter = filterPolymorphic(assoc, Eq(fField, rValue)).And(filterCollection(col))
 			)
 
 			if _, err := r.deleteAll(cw, col.data.flag, Build(table, filter)); err != nil {
@@ -806,7 +1415,7 @@ func (r repository) DeleteAll(ctx context.Context, query Query) error {
 
 	var (
 		cw     = fetchContext(ctx, r.rootAdapter)
-		_, err = r.deleteAll(cw, Invalid, query)
+		_, err = r.deleteAll(cw, Invalid, withTenant(cw.tenant, applyScopes(query)))
 	)
 
 	return err
@@ -821,6 +1430,10 @@ func (r repository) MustDeleteAll(ctx context.Context, query Query) {
 func (r repository) deleteAll(cw contextWrapper, flag DocumentFlag, query Query) (int, error) {
 	if flag.Is(HasDeletedAt) {
 		mutates := map[string]Mutate{"deleted_at": Set("deleted_at", now())}
+		if flag.Is(HasLockVersion) {
+			mutates["lock_version"] = Inc("lock_version")
+		}
+
 		return cw.adapter.Update(cw.ctx, query, mutates)
 	}
 
@@ -836,7 +1449,7 @@ func (r repository) Preload(ctx context.Context, records interface{}, field stri
 
 	var (
 		sl   slice
-		cw   = fetchContext(ctx, r.rootAdapter)
+		cw   = r.fetchReadContext(ctx)
 		path = strings.Split(field, ".")
 		rt   = reflect.TypeOf(records)
 	)
@@ -853,17 +1466,33 @@ func (r repository) Preload(ctx context.Context, records interface{}, field stri
 	}
 
 	var (
-		targets, table, keyField, keyType, ddata, loaded = r.mapPreloadTargets(sl, path)
-		ids                                              = r.targetIDs(targets)
-		query                                            = Build(table, append(queriers, In(keyField, ids...))...)
+		targets, table, assoc, keyType, ddata, loaded = r.mapPreloadTargets(sl, path)
+		keyField                                      = assoc.ForeignField()
+		query                                         = Build(table, queriers...)
 	)
 
 	if len(targets) == 0 || loaded && !bool(query.ReloadQuery) {
 		return nil
 	}
 
+	if assoc.Through() != "" {
+		var (
+			err error
+		)
+
+		if targets, keyType, err = r.mapThroughTargets(cw, assoc, keyType, targets); err != nil {
+			return err
+		}
+
+		if len(targets) == 0 {
+			return nil
+		}
+	}
+
 	var (
-		cur, err = cw.adapter.Query(cw.ctx, r.withDefaultScope(ddata, query))
+		ids      = r.targetIDs(targets)
+		filter   = filterPolymorphic(assoc, In(keyField, ids...))
+		cur, err = cw.adapter.Query(cw.ctx, r.withScope(cw, ddata, query.Where(filter)))
 	)
 
 	if err != nil {
@@ -882,7 +1511,35 @@ func (r repository) MustPreload(ctx context.Context, records interface{}, field
 	must(r.Preload(ctx, records, field, queriers...))
 }
 
-func (r repository) mapPreloadTargets(sl slice, path []string) (map[interface{}][]slice, string, string, reflect.Type, documentData, bool) {
+// PreloadTree loads multiple levels of associations in a single call.
+// Associations are loaded breadth-first using one query for each preload, and every preload uses its own queriers.
+//
+//	repo.PreloadTree(ctx, &users,
+//		rel.NewPreload("transactions", rel.Eq("status", "paid")).With(
+//			rel.NewPreload("histories"),
+//		),
+//		rel.NewPreload("address"),
+//	)
+func (r repository) PreloadTree(ctx context.Context, records interface{}, preloads ...PreloadQuery) error {
+	finish := r.instrumenter.Observe(ctx, "rel-preload-tree", "preloading association tree")
+	defer finish(nil)
+
+	for _, pq := range FlattenPreloads(preloads) {
+		if err := r.Preload(ctx, records, pq.Field, pq.Queriers...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MustPreloadTree loads multiple levels of associations in a single call.
+// It'll panic if any error occurred.
+func (r repository) MustPreloadTree(ctx context.Context, records interface{}, preloads ...PreloadQuery) {
+	must(r.PreloadTree(ctx, records, preloads...))
+}
+
+func (r repository) mapPreloadTargets(sl slice, path []string) (map[interface{}][]slice, string, Association, reflect.Type, documentData, bool) {
 	type frame struct {
 		index int
 		doc   *Document
@@ -890,7 +1547,7 @@ func (r repository) mapPreloadTargets(sl slice, path []string) (map[interface{}]
 
 	var (
 		table     string
-		keyField  string
+		assoc     Association
 		keyType   reflect.Type
 		ddata     documentData
 		loaded    = true
@@ -919,7 +1576,7 @@ func (r repository) mapPreloadTargets(sl slice, path []string) (map[interface{}]
 				ref          = assocs.ReferenceValue()
 			)
 
-			if ref == nil {
+			if ref == nil || !assocs.polymorphicMatch() {
 				continue
 			}
 
@@ -935,7 +1592,7 @@ func (r repository) mapPreloadTargets(sl slice, path []string) (map[interface{}]
 
 			if table == "" {
 				table = target.Table()
-				keyField = assocs.ForeignField()
+				assoc = assocs
 				keyType = reflect.TypeOf(ref)
 
 				if doc, ok := target.(*Document); ok {
@@ -975,7 +1632,45 @@ func (r repository) mapPreloadTargets(sl slice, path []string) (map[interface{}]
 
 	}
 
-	return mapTarget, table, keyField, keyType, ddata, loaded
+	return mapTarget, table, assoc, keyType, ddata, loaded
+}
+
+// mapThroughTargets remaps preload targets using the links in join table of many to many association.
+func (r repository) mapThroughTargets(cw contextWrapper, assoc Association, keyType reflect.Type, targets map[interface{}][]slice) (map[interface{}][]slice, reflect.Type, error) {
+	var (
+		rThrough  = assoc.ReferenceThrough()
+		fThrough  = assoc.ForeignThrough()
+		fType     = assoc.foreignType()
+		query     = Build(assoc.ThroughTable(), Select(rThrough, fThrough), In(rThrough, r.targetIDs(targets)...))
+		mapTarget = make(map[interface{}][]slice)
+	)
+
+	cur, err := cw.adapter.Query(cw.ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer cur.Close()
+
+	var (
+		rValue = reflect.New(keyType)
+		fValue = reflect.New(fType)
+	)
+
+	for cur.Next() {
+		if err := cur.Scan(rValue.Interface(), fValue.Interface()); err != nil {
+			return nil, nil, err
+		}
+
+		var (
+			rKey = rValue.Elem().Interface()
+			fKey = fValue.Elem().Interface()
+		)
+
+		mapTarget[fKey] = append(mapTarget[fKey], targets[rKey]...)
+	}
+
+	return mapTarget, fType, nil
 }
 
 func (r repository) targetIDs(targets map[interface{}][]slice) []interface{} {
@@ -992,8 +1687,29 @@ func (r repository) targetIDs(targets map[interface{}][]slice) []interface{} {
 	return ids
 }
 
+// lockVersion returns current lock version of the document when optimistic locking is used.
+func lockVersion(doc *Document) (int, bool) {
+	if !doc.Flag(HasLockVersion) {
+		return 0, false
+	}
+
+	var (
+		version  int
+		value, _ = doc.Value("lock_version")
+	)
+
+	switch rv := reflect.ValueOf(value); rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		version = int(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		version = int(rv.Uint())
+	}
+
+	return version, true
+}
+
 func (r repository) withDefaultScope(ddata documentData, query Query) Query {
-	if query.UnscopedQuery {
+	if bool(query.UnscopedQuery) || query.UnscopeQuery.has(SoftDeleteScope) {
 		return query
 	}
 
@@ -1004,18 +1720,184 @@ func (r repository) withDefaultScope(ddata documentData, query Query) Query {
 	return query
 }
 
+// withScope applies default scopes, named scopes and tenant of the context to query of the document.
+func (r repository) withScope(cw contextWrapper, ddata documentData, query Query) Query {
+	return withDocumentTenant(cw.tenant, ddata.flag, applyScopes(r.withDefaultScope(ddata, query)))
+}
+
+// withJoinAssoc resolves join association query into join and select query.
+// Unqualified filter and sort fields are qualified using the table of the record to avoid ambiguity.
+func (r repository) withJoinAssoc(rt reflect.Type, ddata documentData, query Query) Query {
+	var (
+		resolved   = false
+		joinFields []string
+	)
+
+	for i, jq := range query.JoinQuery {
+		if jq.Assoc == "" || jq.Table != "" {
+			continue
+		}
+
+		if !isJoinable(ddata, jq.Assoc) {
+			panic("rel: join assoc (" + jq.Assoc + ") must be a belongs to or has one association")
+		}
+
+		var (
+			index     = ddata.index[jq.Assoc]
+			assocData = extractAssociationData(rt, index)
+			ft        = rt.Field(index).Type
+		)
+
+		if assocData.polymorphicType != "" {
+			panic("rel: join assoc (" + jq.Assoc + ") is not supported for polymorphic association")
+		}
+
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if !resolved {
+			resolved = true
+			query.JoinQuery = append([]JoinQuery(nil), query.JoinQuery...)
+		}
+
+		query.JoinQuery[i].Table = associationTable(ft) + " AS " + jq.Assoc
+		query.JoinQuery[i].From = jq.Assoc + "." + assocData.foreignField
+		query.JoinQuery[i].To = query.Table + "." + assocData.referenceField
+		query.JoinQuery[i].Assoc = ""
+
+		for _, field := range extractDocumentData(ft, false).fields {
+			field = jq.Assoc + "." + field
+			joinFields = append(joinFields, field+" AS "+field)
+		}
+	}
+
+	if !resolved {
+		return query
+	}
+
+	if len(query.SelectQuery.Fields) == 0 {
+		query.SelectQuery.Fields = []string{query.Table + ".*"}
+	} else {
+		query.SelectQuery.Fields = qualifyFields(query.Table, query.SelectQuery.Fields)
+	}
+
+	query.SelectQuery.Fields = append(query.SelectQuery.Fields, joinFields...)
+	query.WhereQuery = qualifyFilter(query.Table, query.WhereQuery)
+	query.SortQuery = qualifySorts(query.Table, query.SortQuery)
+
+	return query
+}
+
+func withoutJoinAssoc(joins []JoinQuery) []JoinQuery {
+	var (
+		result []JoinQuery
+	)
+
+	for _, jq := range joins {
+		if jq.Assoc == "" || jq.Table != "" {
+			result = append(result, jq)
+		}
+	}
+
+	return result
+}
+
+func qualifyField(table string, field string) string {
+	if field == "" || field == "*" || strings.ContainsAny(field, ".(^ ") {
+		return field
+	}
+
+	return table + "." + field
+}
+
+func qualifyFields(table string, fields []string) []string {
+	var (
+		result = make([]string, len(fields))
+	)
+
+	for i := range fields {
+		result[i] = qualifyField(table, fields[i])
+	}
+
+	return result
+}
+
+func qualifyFilter(table string, filter FilterQuery) FilterQuery {
+	switch filter.Type {
+	case FilterAndOp, FilterOrOp, FilterNotOp, FilterTupleGtOp, FilterTupleLtOp:
+		if len(filter.Inner) == 0 {
+			break
+		}
+
+		var (
+			inner = make([]FilterQuery, len(filter.Inner))
+		)
+
+		for i := range filter.Inner {
+			inner[i] = qualifyFilter(table, filter.Inner[i])
+		}
+
+		filter.Inner = inner
+	case FilterFragmentOp, FilterExistsOp, FilterNotExistsOp:
+	default:
+		filter.Field = qualifyField(table, filter.Field)
+	}
+
+	return filter
+}
+
+func qualifySorts(table string, sorts []SortQuery) []SortQuery {
+	if len(sorts) == 0 {
+		return sorts
+	}
+
+	var (
+		result = make([]SortQuery, len(sorts))
+	)
+
+	for i := range sorts {
+		result[i] = SortQuery{
+			Field: qualifyField(table, sorts[i].Field),
+			Sort:  sorts[i].Sort,
+		}
+	}
+
+	return result
+}
+
 // Transaction performs transaction with given function argument.
-func (r repository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+// Use Retry option to retry the transaction when it's aborted by serialization failure or deadlock.
+func (r repository) Transaction(ctx context.Context, fn func(ctx context.Context) error, options ...TransactionOption) error {
 	finish := r.instrumenter.Observe(ctx, "rel-transaction", "transaction")
 	defer finish(nil)
 
 	var (
-		cw = fetchContext(ctx, r.rootAdapter)
+		cw    = fetchContext(ctx, r.rootAdapter)
+		opts  = applyTransactionOptions(options)
+		retry = opts.retry
 	)
 
-	return r.transaction(cw, func(cw contextWrapper) error {
-		return fn(cw.ctx)
-	})
+	// conflict aborts the outermost transaction, so it can only be retried there.
+	if ctx.Value(ctxKey) != nil {
+		retry = Retry{}
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := r.transaction(cw, func(cw contextWrapper) error {
+			return fn(cw.ctx)
+		})
+
+		if err == nil || attempt >= retry.MaxAttempts || !isTransactionConflict(err) {
+			return err
+		}
+
+		r.instrumenter.Observe(ctx, "rel-transaction-retry", "retry transaction")(err)
+
+		if !sleep(ctx, retry.delay(attempt+1)) {
+			return err
+		}
+	}
 }
 
 func (r repository) transaction(cw contextWrapper, fn func(cw contextWrapper) error) error {
@@ -1053,6 +1935,34 @@ func (r repository) transaction(cw contextWrapper, fn func(cw contextWrapper) er
 	return err
 }
 
+func isTransactionConflict(err error) bool {
+	var (
+		tce TransactionConflictError
+	)
+
+	return errors.As(err, &tce)
+}
+
+// sleep for the given duration, returns false when context is done before it's elapsed.
+func sleep(ctx context.Context, duration time.Duration) bool {
+	if duration <= 0 {
+		return ctx.Err() == nil
+	}
+
+	var (
+		timer = time.NewTimer(duration)
+	)
+
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
 // New create new repo using adapter.
 func New(adapter Adapter) Repository {
 	repo := &repository{
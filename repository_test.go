@@ -118,6 +118,22 @@ func TestRepository_Aggregate(t *testing.T) {
 	adapter.AssertExpectations(t)
 }
 
+func TestRepository_Aggregate_combined(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Union(From("admins")).SortAsc("id").Limit(10)
+	)
+
+	adapter.On("Aggregate", FromQuery("users", From("users").Union(From("admins"))), "count", "*").Return(2, nil).Once()
+
+	count, err := repo.Aggregate(context.TODO(), query, "count", "*")
+	assert.Equal(t, 2, count)
+	assert.Nil(t, err)
+
+	adapter.AssertExpectations(t)
+}
+
 func TestRepository_MustAggregate(t *testing.T) {
 	var (
 		adapter   = &testAdapter{}
@@ -137,6 +153,186 @@ func TestRepository_MustAggregate(t *testing.T) {
 	adapter.AssertExpectations(t)
 }
 
+func TestRepository_AggregateInto(t *testing.T) {
+	var (
+		avg     float64
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Where(Eq("active", true)).Group("gender").SortAsc("id").Limit(10)
+		cur     = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(20.5).Once()
+	adapter.On("Query", From("users").Where(Eq("active", true)).Select("avg(age) AS avg")).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.AggregateInto(context.TODO(), &avg, query, "avg", "age"))
+	assert.Equal(t, 20.5, avg)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_AggregateInto_null(t *testing.T) {
+	var (
+		sum     = 10.0
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users")
+		cur     = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(nil).Once()
+	adapter.On("Query", query.Select("sum(balance) AS sum")).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.AggregateInto(context.TODO(), &sum, query, "sum", "balance"))
+	assert.Equal(t, 0.0, sum)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_AggregateInto_error(t *testing.T) {
+	var (
+		avg     float64
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users")
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", query.Select("avg(age) AS avg")).Return(&testCursor{}, err).Once()
+
+	assert.Equal(t, err, repo.AggregateInto(context.TODO(), &avg, query, "avg", "age"))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_MustAggregateInto(t *testing.T) {
+	var (
+		max     int
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users")
+		cur     = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Next").Return(false).Once()
+	adapter.On("Query", query.Select("max(age) AS max")).Return(cur, nil).Once()
+
+	assert.Panics(t, func() {
+		repo.MustAggregateInto(context.TODO(), &max, query, "max", "age")
+	})
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_AggregateAll(t *testing.T) {
+	type genderSummary struct {
+		Gender string
+		Count  int
+		Avg    float64
+	}
+
+	var (
+		result  []genderSummary
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Group("gender").SelectExpr(Count("id").As("count"), Avg("age").As("avg"))
+		cur     = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"gender", "count", "avg"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan("female", 2, 20.5).Once()
+	cur.MockScan("male", 3, 30.0).Once()
+	cur.On("Next").Return(false).Once()
+	adapter.On("Query", query.Select("gender")).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.AggregateAll(context.TODO(), &result, query))
+	assert.Equal(t, []genderSummary{
+		{Gender: "female", Count: 2, Avg: 20.5},
+		{Gender: "male", Count: 3, Avg: 30},
+	}, result)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_AggregateAll_map(t *testing.T) {
+	var (
+		result  []map[string]interface{}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Select("gender").Group("gender").SelectExpr(Sum("balance").As("sum"))
+		cur     = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"gender", "sum"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan("female", 100.5).Once()
+	cur.MockScan("male", nil).Once()
+	cur.On("Next").Return(false).Once()
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.AggregateAll(context.TODO(), &result, query))
+	assert.Equal(t, []map[string]interface{}{
+		{"gender": "female", "sum": 100.5},
+		{"gender": "male", "sum": nil},
+	}, result)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_AggregateAll_error(t *testing.T) {
+	var (
+		result  []map[string]interface{}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Group("gender").SelectExpr(Count("id").As("count"))
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", query.Select("gender")).Return(&testCursor{}, err).Once()
+
+	assert.Equal(t, err, repo.AggregateAll(context.TODO(), &result, query))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_MustAggregateAll(t *testing.T) {
+	var (
+		result  []map[string]interface{}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Group("gender").SelectExpr(Count("id").As("count"))
+		cur     = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"gender", "count"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan("male", 1).Once()
+	cur.On("Next").Return(false).Once()
+	adapter.On("Query", query.Select("gender")).Return(cur, nil).Once()
+
+	assert.NotPanics(t, func() {
+		repo.MustAggregateAll(context.TODO(), &result, query)
+	})
+	assert.Equal(t, []map[string]interface{}{{"gender": "male", "count": 1}}, result)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
 func TestRepository_Count(t *testing.T) {
 	var (
 		adapter = &testAdapter{}
@@ -344,6 +540,154 @@ func TestRepository_FindAll_softDeleteUnscoped(t *testing.T) {
 	cur.AssertExpectations(t)
 }
 
+func TestRepository_Find_joinAssoc(t *testing.T) {
+	var (
+		trx     Transaction
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("transactions").
+			Select("transactions.*", "buyer.id AS buyer.id", "buyer.name AS buyer.name", "buyer.age AS buyer.age", "buyer.created_at AS buyer.created_at", "buyer.updated_at AS buyer.updated_at").
+			JoinWith("LEFT JOIN", "users AS buyer", "buyer.id", "transactions.user_id").
+			Where(Eq("transactions.id", 1), Eq("buyer.name", "buyer")).
+			SortAsc("transactions.item").
+			Limit(1)
+		cur = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id", "buyer.id", "buyer.name"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(1, 10, 10, "buyer").Once()
+
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Find(context.TODO(), &trx, JoinAssoc("buyer"), Eq("id", 1), Eq("buyer.name", "buyer"), NewSortAsc("item")))
+	assert.Equal(t, Transaction{ID: 1, BuyerID: 10, Buyer: User{ID: 10, Name: "buyer"}}, trx)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Find_joinAssocSelect(t *testing.T) {
+	var (
+		trx     Transaction
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("transactions").
+			Select("transactions.id", "buyer.name", "buyer.id AS buyer.id", "buyer.name AS buyer.name", "buyer.age AS buyer.age", "buyer.created_at AS buyer.created_at", "buyer.updated_at AS buyer.updated_at").
+			JoinWith("LEFT JOIN", "users AS buyer", "buyer.id", "transactions.user_id").
+			Limit(1)
+		cur = createCursor(1)
+	)
+
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Find(context.TODO(), &trx, Select("id", "buyer.name").JoinAssoc("buyer")))
+	assert.Equal(t, 10, trx.ID)
+	assert.False(t, cur.Next())
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Find_joinAssocNotJoinable(t *testing.T) {
+	var (
+		trx     Transaction
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	assert.PanicsWithValue(t, "rel: join assoc (histories) must be a belongs to or has one association", func() {
+		_ = repo.Find(context.TODO(), &trx, JoinAssoc("histories"))
+	})
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Find_joinAssocPolymorphic(t *testing.T) {
+	var (
+		comment Comment
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	assert.PanicsWithValue(t, "rel: join assoc (video) is not supported for polymorphic association", func() {
+		_ = repo.Find(context.TODO(), &comment, JoinAssoc("video"))
+	})
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_FindAll_joinAssoc(t *testing.T) {
+	var (
+		profiles []Profile
+		adapter  = &testAdapter{}
+		repo     = New(adapter)
+		query    = From("profiles").
+				Select("profiles.*", "user.id AS user.id", "user.name AS user.name", "user.age AS user.age", "user.created_at AS user.created_at", "user.updated_at AS user.updated_at").
+				JoinWith("LEFT JOIN", "users AS user", "user.id", "profiles.user_id")
+		cur = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user.id", "user.name"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(1, 10, "user").Once()
+	cur.MockScan(2, nil, nil).Once()
+	cur.On("Next").Return(false).Once()
+
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.FindAll(context.TODO(), &profiles, JoinAssoc("user")))
+	assert.Equal(t, []Profile{
+		{ID: 1, User: &User{ID: 10, Name: "user"}},
+		{ID: 2},
+	}, profiles)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_FindAndCountAll_joinAssoc(t *testing.T) {
+	var (
+		profiles []Profile
+		adapter  = &testAdapter{}
+		repo     = New(adapter)
+		query    = From("profiles").
+				Select("profiles.*", "user.id AS user.id", "user.name AS user.name", "user.age AS user.age", "user.created_at AS user.created_at", "user.updated_at AS user.updated_at").
+				JoinWith("LEFT JOIN", "users AS user", "user.id", "profiles.user_id").
+				Where(Eq("user.name", "user")).
+				Limit(10)
+		cur = createCursor(1)
+	)
+
+	adapter.On("Query", query).Return(cur, nil).Once()
+	adapter.On("Aggregate", query.Limit(0), "count", "*").Return(1, nil).Once()
+
+	count, err := repo.FindAndCountAll(context.TODO(), &profiles, JoinAssoc("user"), Eq("user.name", "user"), Limit(10))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	assert.Len(t, profiles, 1)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Count_joinAssoc(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Aggregate", From("profiles"), "count", "*").Return(2, nil).Once()
+
+	count, err := repo.Count(context.TODO(), "profiles", JoinAssoc("user"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	adapter.AssertExpectations(t)
+}
+
 func TestRepository_FindAll_error(t *testing.T) {
 	var (
 		users   []User
@@ -449,777 +793,2178 @@ func TestRepository_MustFindAndCountAll(t *testing.T) {
 	cur.AssertExpectations(t)
 }
 
-func TestRepository_Insert(t *testing.T) {
+func TestRepository_Paginate(t *testing.T) {
 	var (
+		users   []User
 		adapter = &testAdapter{}
 		repo    = New(adapter)
-		user    = User{
-			Name: "name",
-		}
-		mutates = map[string]Mutate{
-			"name":       Set("name", "name"),
-			"age":        Set("age", 0),
-			"created_at": Set("created_at", now()),
-			"updated_at": Set("updated_at", now()),
-		}
+		query   = From("users").Where(Eq("age", 20)).Offset(5).Limit(5)
+		cur     = createCursor(2)
 	)
 
-	adapter.On("Insert", From("users"), mutates).Return(1, nil).Once()
+	adapter.On("Query", query.Offset(10).Limit(10)).Return(cur, nil).Once()
+	adapter.On("Aggregate", query.Offset(0).Limit(0), "count", "*").Return(25, nil).Once()
 
-	assert.Nil(t, repo.Insert(context.TODO(), &user))
-	assert.Equal(t, User{
-		ID:        1,
-		Name:      "name",
-		CreatedAt: now(),
-		UpdatedAt: now(),
-	}, user)
+	page, err := repo.Paginate(context.TODO(), &users, 2, 10, query)
+	assert.Nil(t, err)
+	assert.Equal(t, Page{Number: 2, PerPage: 10, Total: 25, TotalPages: 3, HasNext: true, HasPrev: true}, page)
+	assert.Len(t, users, 2)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Insert_compositePrimaryFields(t *testing.T) {
+func TestRepository_Paginate_skipCount(t *testing.T) {
 	var (
-		adapter  = &testAdapter{}
-		repo     = New(adapter)
-		userRole = UserRole{
-			UserID: 1,
-			RoleID: 2,
-		}
-		mutates = map[string]Mutate{
-			"user_id": Set("user_id", 1),
-			"role_id": Set("role_id", 2),
-		}
+		users   []User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").SkipCount()
+		cur     = createCursor(3)
 	)
 
-	adapter.On("Insert", From("user_roles"), mutates).Return(0, nil).Once()
+	adapter.On("Query", query.Limit(3)).Return(cur, nil).Once()
 
-	assert.Nil(t, repo.Insert(context.TODO(), &userRole))
-	assert.Equal(t, UserRole{
-		UserID: 1,
-		RoleID: 2,
-	}, userRole)
+	page, err := repo.Paginate(context.TODO(), &users, 1, 2, query)
+	assert.Nil(t, err)
+	assert.Equal(t, Page{Number: 1, PerPage: 2, HasNext: true}, page)
+	assert.Len(t, users, 2)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Insert_sets(t *testing.T) {
+func TestRepository_Paginate_skipCountLastPage(t *testing.T) {
 	var (
-		user     User
-		adapter  = &testAdapter{}
-		repo     = New(adapter)
-		mutators = []Mutator{
-			Set("name", "name"),
-			Set("created_at", now()),
-			Set("updated_at", now()),
-		}
-		mutates = map[string]Mutate{
-			"name":       Set("name", "name"),
-			"created_at": Set("created_at", now()),
-			"updated_at": Set("updated_at", now()),
-		}
+		users   []User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		cur     = createCursor(1)
 	)
 
-	adapter.On("Insert", From("users"), mutates).Return(1, nil).Once()
+	adapter.On("Query", From("users").Offset(2).Limit(3).SkipCount()).Return(cur, nil).Once()
 
-	assert.Nil(t, repo.Insert(context.TODO(), &user, mutators...))
-	assert.Equal(t, User{
-		ID:        1,
-		Name:      "name",
-		CreatedAt: now(),
-		UpdatedAt: now(),
-	}, user)
+	page, err := repo.Paginate(context.TODO(), &users, 2, 2, SkipCount(true))
+	assert.Nil(t, err)
+	assert.Equal(t, Page{Number: 2, PerPage: 2, HasPrev: true}, page)
+	assert.Len(t, users, 1)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Insert_reload(t *testing.T) {
+func TestRepository_Paginate_invalidPage(t *testing.T) {
 	var (
-		user     User
-		adapter  = &testAdapter{}
-		repo     = New(adapter)
-		mutators = []Mutator{
-			Reload(true),
-			Set("name", "name"),
-			Set("created_at", now()),
-			Set("updated_at", now()),
-		}
-		mutates = map[string]Mutate{
-			"name":       Set("name", "name"),
-			"created_at": Set("created_at", now()),
-			"updated_at": Set("updated_at", now()),
-		}
-		cur = createCursor(1)
+		users   []User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
 	)
 
-	adapter.On("Insert", From("users"), mutates).Return(10, nil).Once()
-	adapter.On("Query", From("users").Where(Eq("id", 10)).Limit(1)).Return(cur, nil).Once()
-
-	assert.Nil(t, repo.Insert(context.TODO(), &user, mutators...))
-	assert.Equal(t, User{
-		ID:        10,
-		Name:      "name",
-		CreatedAt: now(),
-		UpdatedAt: now(),
-	}, user)
-	assert.False(t, cur.Next())
+	assert.Panics(t, func() {
+		repo.Paginate(context.TODO(), &users, 0, 10)
+	})
 
-	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
+	assert.Panics(t, func() {
+		repo.Paginate(context.TODO(), &users, 1, 0)
+	})
 }
 
-func TestRepository_Insert_reloadError(t *testing.T) {
+func TestRepository_Paginate_queryError(t *testing.T) {
 	var (
-		user     User
-		adapter  = &testAdapter{}
-		repo     = New(adapter)
-		mutators = []Mutator{
-			Reload(true),
-			Set("name", "name"),
-			Set("created_at", now()),
-			Set("updated_at", now()),
-		}
-		mutates = map[string]Mutate{
-			"name":       Set("name", "name"),
-			"created_at": Set("created_at", now()),
-			"updated_at": Set("updated_at", now()),
-		}
-		cur = &testCursor{}
-		err = errors.New("error")
+		users   []User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		err     = errors.New("error")
 	)
 
-	adapter.On("Insert", From("users"), mutates).Return(10, nil).Once()
-	adapter.On("Query", From("users").Where(Eq("id", 10)).Limit(1)).Return(cur, err).Once()
+	adapter.On("Query", From("users").Limit(10)).Return(&testCursor{}, err).Once()
 
-	assert.Equal(t, err, repo.Insert(context.TODO(), &user, mutators...))
+	page, perr := repo.Paginate(context.TODO(), &users, 1, 10)
+	assert.Equal(t, err, perr)
+	assert.Equal(t, Page{}, page)
 
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Insert_saveBelongsTo(t *testing.T) {
+func TestRepository_Paginate_skipCountQueryError(t *testing.T) {
 	var (
-		userID    = 1
-		profileID = 2
-		profile   = Profile{
-			Name: "profile name",
-			User: &User{Name: "name"},
-		}
+		users   []User
 		adapter = &testAdapter{}
 		repo    = New(adapter)
+		err     = errors.New("error")
 	)
 
-	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Insert", From("users"), mock.Anything).Return(userID, nil).Once()
-	adapter.On("Insert", From("profiles"), mock.Anything).Return(profileID, nil).Once()
-	adapter.On("Commit").Return(nil).Once()
+	adapter.On("Query", From("users").Limit(11).SkipCount()).Return(&testCursor{}, err).Once()
 
-	assert.Nil(t, repo.Insert(context.TODO(), &profile))
-	assert.Equal(t, Profile{
-		ID:     profileID,
-		Name:   "profile name",
-		UserID: &userID,
-		User: &User{
-			ID:        userID,
-			Name:      "name",
-			CreatedAt: now(),
-			UpdatedAt: now(),
-		},
-	}, profile)
+	page, perr := repo.Paginate(context.TODO(), &users, 1, 10, SkipCount(true))
+	assert.Equal(t, err, perr)
+	assert.Equal(t, Page{}, page)
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Insert_saveBelongsToCascadeDisabled(t *testing.T) {
+func TestRepository_Paginate_countError(t *testing.T) {
 	var (
-		profile = Profile{
-			Name: "profile name",
-			User: &User{Name: "name"},
-		}
-		adapter   = &testAdapter{}
-		repo      = New(adapter)
-		addressID = 2
+		users   []User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		cur     = createCursor(2)
+		err     = errors.New("error")
 	)
 
-	adapter.On("Insert", From("profiles"), mock.Anything).Return(addressID, nil).Once()
+	adapter.On("Query", From("users").Limit(10)).Return(cur, nil).Once()
+	adapter.On("Aggregate", From("users"), "count", "*").Return(0, err).Once()
 
-	assert.Nil(t, repo.Insert(context.TODO(), &profile, Cascade(false)))
-	assert.Equal(t, Profile{
-		ID:   addressID,
-		Name: "profile name",
-		User: &User{Name: "name"},
-	}, profile)
+	page, perr := repo.Paginate(context.TODO(), &users, 1, 10)
+	assert.Equal(t, err, perr)
+	assert.Equal(t, Page{}, page)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Insert_saveBelongsToError(t *testing.T) {
+func TestRepository_MustPaginate(t *testing.T) {
 	var (
-		profile = Profile{
-			Name: "profile name",
-			User: &User{Name: "name"},
-		}
+		users   []User
 		adapter = &testAdapter{}
 		repo    = New(adapter)
-		err     = errors.New("error")
+		cur     = createCursor(2)
 	)
 
-	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Insert", From("users"), mock.Anything).Return(0, err).Once()
-	adapter.On("Rollback").Return(nil).Once()
+	adapter.On("Query", From("users").Limit(10)).Return(cur, nil).Once()
+	adapter.On("Aggregate", From("users"), "count", "*").Return(2, nil).Once()
 
-	assert.Equal(t, err, repo.Insert(context.TODO(), &profile))
+	assert.NotPanics(t, func() {
+		page := repo.MustPaginate(context.TODO(), &users, 1, 10)
+		assert.Equal(t, Page{Number: 1, PerPage: 10, Total: 2, TotalPages: 1}, page)
+	})
+	assert.Len(t, users, 2)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Insert_saveHasOne(t *testing.T) {
+func TestRepository_PaginateKeyset(t *testing.T) {
 	var (
-		userID    = 1
-		addressID = 2
-		user      = User{
-			Name: "name",
-			Address: Address{
-				Street: "street",
-			},
-		}
+		users   []User
 		adapter = &testAdapter{}
 		repo    = New(adapter)
+		cur     = createCursor(3)
 	)
 
-	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Insert", From("users"), mock.Anything).Return(userID, nil).Once()
-	adapter.On("Insert", From("addresses"), mock.Anything).Return(addressID, nil).Once()
-	adapter.On("Commit").Return(nil).Once()
+	adapter.On("Query", From("users").SortAsc("id").Limit(3)).Return(cur, nil).Once()
 
-	assert.Nil(t, repo.Insert(context.TODO(), &user))
-	assert.Equal(t, User{
-		ID:        userID,
-		Name:      "name",
-		CreatedAt: now(),
-		UpdatedAt: now(),
-		Address: Address{
-			ID:     addressID,
-			UserID: &userID,
-			Street: "street",
-		},
-	}, user)
+	page, err := repo.PaginateKeyset(context.TODO(), &users, "", 2, Offset(10))
+	assert.Nil(t, err)
+	assert.Equal(t, KeysetPage{Next: "WzEwXQ", HasNext: true}, page)
+	assert.Len(t, users, 2)
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Insert_saveHasOneCascadeDisabled(t *testing.T) {
+func TestRepository_PaginateKeyset_after(t *testing.T) {
 	var (
-		userID = 1
-		user   = User{
-			Name: "name",
-			Address: Address{
-				Street: "street",
-			},
-		}
+		users   []User
 		adapter = &testAdapter{}
 		repo    = New(adapter)
+		query   = From("users").Where(Eq("age", 20)).SortDesc("name")
+		cur     = createCursor(1)
 	)
 
-	adapter.On("Insert", From("users"), mock.Anything).Return(userID, nil).Once()
+	adapter.On("Query", query.SortDesc("id").Where(TupleLt([]string{"name", "id"}, []interface{}{"rel", 10})).Limit(3)).Return(cur, nil).Once()
 
-	assert.Nil(t, repo.Insert(context.TODO(), &user, Cascade(false)))
-	assert.Equal(t, User{
-		ID:        userID,
-		Name:      "name",
-		CreatedAt: now(),
-		UpdatedAt: now(),
-		Address: Address{
-			Street: "street",
-		},
-	}, user)
+	page, err := repo.PaginateKeyset(context.TODO(), &users, "WyJyZWwiLDEwXQ", 2, query)
+	assert.Nil(t, err)
+	assert.Equal(t, KeysetPage{}, page)
+	assert.Len(t, users, 1)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Insert_saveHasOneError(t *testing.T) {
+func TestRepository_PaginateKeyset_invalidCursor(t *testing.T) {
 	var (
-		userID = 1
-		user   = User{
-			Name: "name",
-			Address: Address{
-				Street: "street",
-			},
-		}
+		users   []User
 		adapter = &testAdapter{}
 		repo    = New(adapter)
-		err     = errors.New("error")
 	)
 
-	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Insert", From("users"), mock.Anything).Return(userID, nil).Once()
-	adapter.On("Insert", From("addresses"), mock.Anything).Return(0, err).Once()
-	adapter.On("Rollback").Return(nil).Once()
-
-	assert.Equal(t, err, repo.Insert(context.TODO(), &user))
+	page, err := repo.PaginateKeyset(context.TODO(), &users, "invalid", 2)
+	assert.Equal(t, ErrInvalidKeyset, err)
+	assert.Equal(t, KeysetPage{}, page)
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Insert_saveHasMany(t *testing.T) {
+func TestRepository_PaginateKeyset_nullableSort(t *testing.T) {
 	var (
-		user = User{
-			Name: "name",
-			UserRoles: []UserRole{
-				{RoleID: 2},
-			},
-		}
-		adapter = &testAdapter{}
-		repo    = New(adapter)
+		addresses []Address
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
 	)
 
-	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Insert", From("users"), mock.Anything).Return(1, nil).Once()
-	adapter.On("InsertAll", From("user_roles"), mock.Anything, mock.Anything).Return([]interface{}(nil), nil).Once()
-	adapter.On("Commit").Return(nil).Once()
-
-	assert.Nil(t, repo.Insert(context.TODO(), &user))
-	assert.Equal(t, User{
-		ID:        1,
-		Name:      "name",
-		CreatedAt: now(),
-		UpdatedAt: now(),
-		UserRoles: []UserRole{
-			{UserID: 1, RoleID: 2},
-		},
-	}, user)
+	page, err := repo.PaginateKeyset(context.TODO(), &addresses, "", 2, NewSortAsc("user_id"))
+	assert.EqualError(t, err, "rel: keyset field user_id must not be nullable")
+	assert.Equal(t, KeysetPage{}, page)
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Insert_saveHasManyCascadeDisabled(t *testing.T) {
+func TestRepository_PaginateKeyset_invalidLimit(t *testing.T) {
 	var (
-		user = User{
-			Name: "name",
-			UserRoles: []UserRole{
-				{RoleID: 2},
-			},
-		}
+		users   []User
 		adapter = &testAdapter{}
 		repo    = New(adapter)
 	)
 
-	adapter.On("Insert", From("users"), mock.Anything).Return(1, nil).Once()
+	assert.Panics(t, func() {
+		repo.PaginateKeyset(context.TODO(), &users, "", 0)
+	})
+}
+
+func TestRepository_PaginateKeyset_queryError(t *testing.T) {
+	var (
+		users   []User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", From("users").SortAsc("id").Limit(3)).Return(&testCursor{}, err).Once()
+
+	page, perr := repo.PaginateKeyset(context.TODO(), &users, "", 2)
+	assert.Equal(t, err, perr)
+	assert.Equal(t, KeysetPage{}, page)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_MustPaginateKeyset(t *testing.T) {
+	var (
+		users   []User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		cur     = createCursor(2)
+	)
+
+	adapter.On("Query", From("users").SortAsc("id").Limit(3)).Return(cur, nil).Once()
+
+	assert.NotPanics(t, func() {
+		page := repo.MustPaginateKeyset(context.TODO(), &users, "", 2)
+		assert.False(t, page.HasNext)
+	})
+	assert.Len(t, users, 2)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Scan(t *testing.T) {
+	type report struct {
+		Gender string
+		Total  int
+	}
+
+	var (
+		result  []report
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Select("gender").Group("gender").SelectExpr(Count("id").As("total"))
+		cur     = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"gender", "total"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan("female", 2).Once()
+	cur.MockScan("male", 3).Once()
+	cur.On("Next").Return(false).Once()
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Scan(context.TODO(), &result, query))
+	assert.Equal(t, []report{{Gender: "female", Total: 2}, {Gender: "male", Total: 3}}, result)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Scan_struct(t *testing.T) {
+	type report struct {
+		Gender string
+		Total  int
+	}
+
+	var (
+		result  report
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Where(Eq("gender", "male"))
+		cur     = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"gender", "total"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan("male", 3).Once()
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Scan(context.TODO(), &result, query))
+	assert.Equal(t, report{Gender: "male", Total: 3}, result)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Scan_scalars(t *testing.T) {
+	var (
+		result  = []int{100}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Select("id")
+		cur     = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(1).Once()
+	cur.MockScan(2).Once()
+	cur.On("Next").Return(false).Once()
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Scan(context.TODO(), &result, query))
+	assert.Equal(t, []int{1, 2}, result)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Scan_scalar(t *testing.T) {
+	var (
+		result  time.Time
+		now     = time.Now()
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Select("max(created_at)")
+		cur     = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(now).Once()
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Scan(context.TODO(), &result, query))
+	assert.Equal(t, now, result)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Scan_map(t *testing.T) {
+	var (
+		result  map[string]interface{}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Select("id", "name").Where(Eq("id", 1))
+		cur     = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(1, "rel").Once()
+	cur.On("Next").Return(false).Once()
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Scan(context.TODO(), &result, query))
+	assert.Equal(t, map[string]interface{}{"id": 1, "name": "rel"}, result)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Scan_mapNotFound(t *testing.T) {
+	var (
+		result  map[string]interface{}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Where(Eq("id", 1))
+		cur     = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Next").Return(false).Once()
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.Equal(t, NotFoundError{}, repo.Scan(context.TODO(), &result, query))
+	assert.Nil(t, result)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Scan_error(t *testing.T) {
+	var (
+		result  []int
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Select("id")
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", query).Return(&testCursor{}, err).Once()
+
+	assert.Equal(t, err, repo.Scan(context.TODO(), &result, query))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Scan_notPointer(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Select("id")
+		cur     = &testCursor{}
+	)
+
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.PanicsWithValue(t, "rel: result must be a pointer", func() {
+		repo.Scan(context.TODO(), []int{}, query)
+	})
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_MustScan(t *testing.T) {
+	var (
+		result  int
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Select("id")
+		cur     = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Next").Return(false).Once()
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.Panics(t, func() {
+		repo.MustScan(context.TODO(), &result, query)
+	})
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Insert(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		user    = User{
+			Name: "name",
+		}
+		mutates = map[string]Mutate{
+			"name":       Set("name", "name"),
+			"age":        Set("age", 0),
+			"created_at": Set("created_at", now()),
+			"updated_at": Set("updated_at", now()),
+		}
+	)
+
+	adapter.On("Insert", From("users"), mutates, OnConflict{}).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &user))
+	assert.Equal(t, User{
+		ID:        1,
+		Name:      "name",
+		CreatedAt: now(),
+		UpdatedAt: now(),
+	}, user)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_onConflictReplace(t *testing.T) {
+	var (
+		user    = User{Name: "name"}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		mutates = map[string]Mutate{
+			"name":       Set("name", "name"),
+			"age":        Set("age", 0),
+			"created_at": Set("created_at", now()),
+			"updated_at": Set("updated_at", now()),
+		}
+		onConflict = OnConflict{Keys: []string{"id"}, Replace: true}
+	)
+
+	adapter.On("Insert", From("users"), mutates, onConflict).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &user, OnConflictReplace()))
+	assert.Equal(t, 1, user.ID)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_onConflictIgnoreReload(t *testing.T) {
+	var (
+		user    = User{Name: "name"}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		mutates = map[string]Mutate{
+			"name":       Set("name", "name"),
+			"age":        Set("age", 0),
+			"created_at": Set("created_at", now()),
+			"updated_at": Set("updated_at", now()),
+		}
+		onConflict = OnConflict{Keys: []string{"name"}, Ignore: true}
+		cur        = createCursor(1)
+	)
+
+	adapter.On("Insert", From("users"), mutates, onConflict).Return(nil, nil).Once()
+	adapter.On("Query", From("users").Where(Eq("name", "name")).Unscoped().Limit(1)).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &user, OnConflictKeyIgnore("name")))
+	assert.Equal(t, 10, user.ID)
+	assert.False(t, cur.Next())
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Insert_onConflictIgnoreReloadError(t *testing.T) {
+	var (
+		user    = User{Name: "name"}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		mutates = map[string]Mutate{
+			"name":       Set("name", "name"),
+			"age":        Set("age", 0),
+			"created_at": Set("created_at", now()),
+			"updated_at": Set("updated_at", now()),
+		}
+		onConflict = OnConflict{Keys: []string{"name"}, Ignore: true}
+		cur        = &testCursor{}
+		err        = errors.New("error")
+	)
+
+	adapter.On("Insert", From("users"), mutates, onConflict).Return(nil, nil).Once()
+	adapter.On("Query", From("users").Where(Eq("name", "name")).Unscoped().Limit(1)).Return(cur, err).Once()
+
+	assert.Equal(t, err, repo.Insert(context.TODO(), &user, OnConflictKeyIgnore("name")))
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Insert_onConflictIgnoreNotInserted(t *testing.T) {
+	var (
+		user    = User{Name: "name"}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Insert", From("users"), mock.Anything, OnConflict{Ignore: true}).Return(nil, nil).Once()
+
+	assert.Equal(t, ErrNotInserted, repo.Insert(context.TODO(), &user, OnConflictIgnore()))
+	assert.Equal(t, 0, user.ID)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_onConflictIgnorePrimaryReload(t *testing.T) {
+	var (
+		user    = User{ID: 10, Name: "name"}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		cur     = createCursor(1)
+	)
+
+	adapter.On("Insert", From("users"), mock.Anything, OnConflict{Ignore: true}).Return(nil, nil).Once()
+	adapter.On("Query", From("users").Where(Eq("id", 10)).Unscoped().Limit(1)).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &user, OnConflictIgnore()))
+	assert.Equal(t, 10, user.ID)
+	assert.False(t, cur.Next())
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Insert_onConflictIgnorePrimaryNotFound(t *testing.T) {
+	var (
+		user    = User{ID: 10, Name: "name"}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		cur     = createCursor(0)
+	)
+
+	adapter.On("Insert", From("users"), mock.Anything, OnConflict{Ignore: true}).Return(nil, nil).Once()
+	adapter.On("Query", From("users").Where(Eq("id", 10)).Unscoped().Limit(1)).Return(cur, nil).Once()
+
+	assert.Equal(t, ErrNotInserted, repo.Insert(context.TODO(), &user, OnConflictIgnore()))
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Insert_compositePrimaryFields(t *testing.T) {
+	var (
+		adapter  = &testAdapter{}
+		repo     = New(adapter)
+		userRole = UserRole{
+			UserID: 1,
+			RoleID: 2,
+		}
+		mutates = map[string]Mutate{
+			"user_id": Set("user_id", 1),
+			"role_id": Set("role_id", 2),
+		}
+	)
+
+	adapter.On("Insert", From("user_roles"), mutates, OnConflict{}).Return(0, nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &userRole))
+	assert.Equal(t, UserRole{
+		UserID: 1,
+		RoleID: 2,
+	}, userRole)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_compositePrimaryFieldsReturned(t *testing.T) {
+	var (
+		adapter  = &testAdapter{}
+		repo     = New(adapter)
+		userRole = UserRole{
+			UserID: 1,
+		}
+		mutates = map[string]Mutate{
+			"user_id": Set("user_id", 1),
+			"role_id": Set("role_id", 0),
+		}
+	)
+
+	adapter.On("Insert", From("user_roles"), mutates, OnConflict{}).Return([]interface{}{int64(1), int64(2)}, nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &userRole))
+	assert.Equal(t, UserRole{
+		UserID: 1,
+		RoleID: 2,
+	}, userRole)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_scannerPrimary(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		session = Session{UserID: 1}
+		mutates = map[string]Mutate{
+			"user_id": Set("user_id", 1),
+		}
+	)
+
+	adapter.On("Insert", From("sessions"), mutates, OnConflict{}).Return([]byte{1, 2, 3, 4}, nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &session))
+	assert.Equal(t, Session{ID: Token{1, 2, 3, 4}, UserID: 1}, session)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_scannerPrimaryError(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		session = Session{UserID: 1}
+		mutates = map[string]Mutate{
+			"user_id": Set("user_id", 1),
+		}
+	)
+
+	adapter.On("Insert", From("sessions"), mutates, OnConflict{}).Return("token", nil).Once()
+
+	assert.Equal(t, errors.New("invalid token"), repo.Insert(context.TODO(), &session))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_primaryGenerator(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		session = Session{UserID: 1}
+		mutates = map[string]Mutate{
+			"id":      Set("id", Token{1, 2, 3, 4}),
+			"user_id": Set("user_id", 1),
+		}
+	)
+
+	RegisterPrimaryGenerator(Token{}, func() interface{} {
+		return Token{1, 2, 3, 4}
+	})
+	defer RegisterPrimaryGenerator(Token{}, nil)
+
+	adapter.On("Insert", From("sessions"), mutates, OnConflict{}).Return(int64(0), nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &session))
+	assert.Equal(t, Session{ID: Token{1, 2, 3, 4}, UserID: 1}, session)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_sets(t *testing.T) {
+	var (
+		user     User
+		adapter  = &testAdapter{}
+		repo     = New(adapter)
+		mutators = []Mutator{
+			Set("name", "name"),
+			Set("created_at", now()),
+			Set("updated_at", now()),
+		}
+		mutates = map[string]Mutate{
+			"name":       Set("name", "name"),
+			"created_at": Set("created_at", now()),
+			"updated_at": Set("updated_at", now()),
+		}
+	)
+
+	adapter.On("Insert", From("users"), mutates, OnConflict{}).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &user, mutators...))
+	assert.Equal(t, User{
+		ID:        1,
+		Name:      "name",
+		CreatedAt: now(),
+		UpdatedAt: now(),
+	}, user)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_reload(t *testing.T) {
+	var (
+		user     User
+		adapter  = &testAdapter{}
+		repo     = New(adapter)
+		mutators = []Mutator{
+			Reload(true),
+			Set("name", "name"),
+			Set("created_at", now()),
+			Set("updated_at", now()),
+		}
+		mutates = map[string]Mutate{
+			"name":       Set("name", "name"),
+			"created_at": Set("created_at", now()),
+			"updated_at": Set("updated_at", now()),
+		}
+		cur = createCursor(1)
+	)
+
+	adapter.On("Insert", From("users"), mutates, OnConflict{}).Return(10, nil).Once()
+	adapter.On("Query", From("users").Where(Eq("id", 10)).Limit(1)).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &user, mutators...))
+	assert.Equal(t, User{
+		ID:        10,
+		Name:      "name",
+		CreatedAt: now(),
+		UpdatedAt: now(),
+	}, user)
+	assert.False(t, cur.Next())
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Insert_reloadError(t *testing.T) {
+	var (
+		user     User
+		adapter  = &testAdapter{}
+		repo     = New(adapter)
+		mutators = []Mutator{
+			Reload(true),
+			Set("name", "name"),
+			Set("created_at", now()),
+			Set("updated_at", now()),
+		}
+		mutates = map[string]Mutate{
+			"name":       Set("name", "name"),
+			"created_at": Set("created_at", now()),
+			"updated_at": Set("updated_at", now()),
+		}
+		cur = &testCursor{}
+		err = errors.New("error")
+	)
+
+	adapter.On("Insert", From("users"), mutates, OnConflict{}).Return(10, nil).Once()
+	adapter.On("Query", From("users").Where(Eq("id", 10)).Limit(1)).Return(cur, err).Once()
+
+	assert.Equal(t, err, repo.Insert(context.TODO(), &user, mutators...))
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Insert_returning(t *testing.T) {
+	var (
+		user    = User{Name: "name"}
+		adapter = &testAdapter{returning: true}
+		repo    = New(adapter)
+		mutates = map[string]Mutate{
+			"name":       Set("name", "name"),
+			"age":        Set("age", 0),
+			"created_at": Set("created_at", now()),
+			"updated_at": Set("updated_at", now()),
+		}
+		cur = createCursor(1)
+	)
+
+	adapter.On("InsertReturning", From("users"), mutates, OnConflict{}).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &user))
+	assert.Equal(t, User{
+		ID:        10,
+		Name:      "name",
+		CreatedAt: now(),
+		UpdatedAt: now(),
+	}, user)
+	assert.False(t, cur.Next())
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Insert_returningError(t *testing.T) {
+	var (
+		user    = User{Name: "name"}
+		adapter = &testAdapter{returning: true}
+		repo    = New(adapter)
+		cur     = &testCursor{}
+		err     = errors.New("error")
+	)
+
+	adapter.On("InsertReturning", From("users"), mock.Anything, OnConflict{}).Return(cur, err).Once()
+
+	assert.Equal(t, err, repo.Insert(context.TODO(), &user))
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Insert_returningNotFound(t *testing.T) {
+	var (
+		user    = User{Name: "name"}
+		adapter = &testAdapter{returning: true}
+		repo    = New(adapter)
+		cur     = createCursor(0)
+	)
+
+	adapter.On("InsertReturning", From("users"), mock.Anything, OnConflict{}).Return(cur, nil).Once()
+
+	assert.Equal(t, NotFoundError{}, repo.Insert(context.TODO(), &user))
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Insert_returningOnConflictIgnore(t *testing.T) {
+	var (
+		user       = User{Name: "name"}
+		adapter    = &testAdapter{returning: true}
+		repo       = New(adapter)
+		onConflict = OnConflict{Keys: []string{"name"}, Ignore: true}
+		cur1       = createCursor(0)
+		cur2       = createCursor(1)
+	)
+
+	adapter.On("InsertReturning", From("users"), mock.Anything, onConflict).Return(cur1, nil).Once()
+	adapter.On("Query", From("users").Where(Eq("name", "name")).Unscoped().Limit(1)).Return(cur2, nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &user, OnConflictKeyIgnore("name")))
+	assert.Equal(t, 10, user.ID)
+	assert.False(t, cur2.Next())
+
+	adapter.AssertExpectations(t)
+	cur1.AssertExpectations(t)
+	cur2.AssertExpectations(t)
+}
+
+func TestRepository_Insert_returningOnConflictIgnoreError(t *testing.T) {
+	var (
+		user       = User{Name: "name"}
+		adapter    = &testAdapter{returning: true}
+		repo       = New(adapter)
+		onConflict = OnConflict{Keys: []string{"name"}, Ignore: true}
+		cur1       = createCursor(0)
+		cur2       = &testCursor{}
+		err        = errors.New("error")
+	)
+
+	adapter.On("InsertReturning", From("users"), mock.Anything, onConflict).Return(cur1, nil).Once()
+	adapter.On("Query", From("users").Where(Eq("name", "name")).Unscoped().Limit(1)).Return(cur2, err).Once()
+
+	assert.Equal(t, err, repo.Insert(context.TODO(), &user, OnConflictKeyIgnore("name")))
+
+	adapter.AssertExpectations(t)
+	cur1.AssertExpectations(t)
+	cur2.AssertExpectations(t)
+}
+
+func TestRepository_Insert_saveBelongsTo(t *testing.T) {
+	var (
+		userID    = 1
+		profileID = 2
+		profile   = Profile{
+			Name: "profile name",
+			User: &User{Name: "name"},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("users"), mock.Anything, OnConflict{}).Return(userID, nil).Once()
+	adapter.On("Insert", From("profiles"), mock.Anything, OnConflict{}).Return(profileID, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &profile))
+	assert.Equal(t, Profile{
+		ID:     profileID,
+		Name:   "profile name",
+		UserID: &userID,
+		User: &User{
+			ID:        userID,
+			Name:      "name",
+			CreatedAt: now(),
+			UpdatedAt: now(),
+		},
+	}, profile)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_saveBelongsToCascadeDisabled(t *testing.T) {
+	var (
+		profile = Profile{
+			Name: "profile name",
+			User: &User{Name: "name"},
+		}
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		addressID = 2
+	)
+
+	adapter.On("Insert", From("profiles"), mock.Anything, OnConflict{}).Return(addressID, nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &profile, Cascade(false)))
+	assert.Equal(t, Profile{
+		ID:   addressID,
+		Name: "profile name",
+		User: &User{Name: "name"},
+	}, profile)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_saveBelongsToError(t *testing.T) {
+	var (
+		profile = Profile{
+			Name: "profile name",
+			User: &User{Name: "name"},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		err     = errors.New("error")
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("users"), mock.Anything, OnConflict{}).Return(0, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Insert(context.TODO(), &profile))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_saveHasOne(t *testing.T) {
+	var (
+		userID    = 1
+		addressID = 2
+		user      = User{
+			Name: "name",
+			Address: Address{
+				Street: "street",
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("users"), mock.Anything, OnConflict{}).Return(userID, nil).Once()
+	adapter.On("Insert", From("addresses"), mock.Anything, OnConflict{}).Return(addressID, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &user))
+	assert.Equal(t, User{
+		ID:        userID,
+		Name:      "name",
+		CreatedAt: now(),
+		UpdatedAt: now(),
+		Address: Address{
+			ID:     addressID,
+			UserID: &userID,
+			Street: "street",
+		},
+	}, user)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_saveHasOneCascadeDisabled(t *testing.T) {
+	var (
+		userID = 1
+		user   = User{
+			Name: "name",
+			Address: Address{
+				Street: "street",
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Insert", From("users"), mock.Anything, OnConflict{}).Return(userID, nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &user, Cascade(false)))
+	assert.Equal(t, User{
+		ID:        userID,
+		Name:      "name",
+		CreatedAt: now(),
+		UpdatedAt: now(),
+		Address: Address{
+			Street: "street",
+		},
+	}, user)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_saveHasOneError(t *testing.T) {
+	var (
+		userID = 1
+		user   = User{
+			Name: "name",
+			Address: Address{
+				Street: "street",
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		err     = errors.New("error")
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("users"), mock.Anything, OnConflict{}).Return(userID, nil).Once()
+	adapter.On("Insert", From("addresses"), mock.Anything, OnConflict{}).Return(0, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Insert(context.TODO(), &user))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_saveHasMany(t *testing.T) {
+	var (
+		user = User{
+			Name: "name",
+			UserRoles: []UserRole{
+				{RoleID: 2},
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("users"), mock.Anything, OnConflict{}).Return(1, nil).Once()
+	adapter.On("InsertAll", From("user_roles"), mock.Anything, mock.Anything, OnConflict{}).Return([]interface{}(nil), nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &user))
+	assert.Equal(t, User{
+		ID:        1,
+		Name:      "name",
+		CreatedAt: now(),
+		UpdatedAt: now(),
+		UserRoles: []UserRole{
+			{UserID: 1, RoleID: 2},
+		},
+	}, user)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_saveHasManyCascadeDisabled(t *testing.T) {
+	var (
+		user = User{
+			Name: "name",
+			UserRoles: []UserRole{
+				{RoleID: 2},
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Insert", From("users"), mock.Anything, OnConflict{}).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &user, Cascade(false)))
+	assert.Equal(t, User{
+		ID:        1,
+		Name:      "name",
+		CreatedAt: now(),
+		UpdatedAt: now(),
+		UserRoles: []UserRole{
+			{RoleID: 2},
+		},
+	}, user)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_saveHasManyError(t *testing.T) {
+	var (
+		user = User{
+			Name: "name",
+			UserRoles: []UserRole{
+				{RoleID: 2},
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		err     = errors.New("error")
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("users"), mock.Anything, OnConflict{}).Return(1, nil).Once()
+	adapter.On("InsertAll", From("user_roles"), mock.Anything, mock.Anything, OnConflict{}).Return([]interface{}{}, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Insert(context.TODO(), &user))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_savePolymorphicBelongsTo(t *testing.T) {
+	var (
+		comment = Comment{
+			Body:  "comment",
+			Video: &Video{Title: "video"},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		mutates = map[string]Mutate{
+			"body":       Set("body", "comment"),
+			"owner_id":   Set("owner_id", 1),
+			"owner_type": Set("owner_type", "videos"),
+		}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("videos"), mock.Anything, OnConflict{}).Return(1, nil).Once()
+	adapter.On("Insert", From("comments"), mutates, OnConflict{}).Return(2, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &comment))
+	assert.Equal(t, Comment{
+		ID:        2,
+		Body:      "comment",
+		OwnerType: "videos",
+		OwnerID:   1,
+		Video:     &Video{ID: 1, Title: "video"},
+	}, comment)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_savePolymorphicHasOne(t *testing.T) {
+	var (
+		video = Video{
+			Title:     "video",
+			Thumbnail: Attachment{URL: "thumbnail.png"},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		mutates = map[string]Mutate{
+			"url":             Set("url", "thumbnail.png"),
+			"attachable_id":   Set("attachable_id", 1),
+			"attachable_type": Set("attachable_type", "videos"),
+		}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("videos"), mock.Anything, OnConflict{}).Return(1, nil).Once()
+	adapter.On("Insert", From("attachments"), mutates, OnConflict{}).Return(2, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &video))
+	assert.Equal(t, Attachment{
+		ID:             2,
+		URL:            "thumbnail.png",
+		AttachableType: "videos",
+		AttachableID:   1,
+	}, video.Thumbnail)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_savePolymorphicHasMany(t *testing.T) {
+	var (
+		video = Video{
+			Title: "video",
+			Comments: []Comment{
+				{Body: "comment"},
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		mutates = []map[string]Mutate{
+			{
+				"body":       Set("body", "comment"),
+				"owner_id":   Set("owner_id", 1),
+				"owner_type": Set("owner_type", "videos"),
+			},
+		}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("videos"), mock.Anything, OnConflict{}).Return(1, nil).Once()
+	adapter.On("InsertAll", From("comments"), mock.Anything, mutates, OnConflict{}).Return([]interface{}{2}, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &video))
+	assert.Equal(t, []Comment{
+		{ID: 2, Body: "comment", OwnerType: "videos", OwnerID: 1},
+	}, video.Comments)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_saveManyToMany(t *testing.T) {
+	var (
+		todo = Todo{
+			Title: "todo",
+			Tags: []Tag{
+				{Name: "work"},
+				{ID: 2, Name: "home"},
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		mutates = []map[string]Mutate{
+			{"todo_id": Set("todo_id", 1), "tag_id": Set("tag_id", 3)},
+			{"todo_id": Set("todo_id", 1), "tag_id": Set("tag_id", 2)},
+		}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("todos"), mock.Anything, OnConflict{}).Return(1, nil).Once()
+	adapter.On("Insert", From("tags"), map[string]Mutate{"name": Set("name", "work")}, OnConflict{}).Return(3, nil).Once()
+	adapter.On("InsertAll", From("todo_tags"), []string{"todo_id", "tag_id"}, mutates, OnConflict{}).Return([]interface{}(nil), nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &todo))
+	assert.Equal(t, Todo{
+		ID:    1,
+		Title: "todo",
+		Tags: []Tag{
+			{ID: 3, Name: "work"},
+			{ID: 2, Name: "home"},
+		},
+	}, todo)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_saveManyToManyError(t *testing.T) {
+	var (
+		todo = Todo{
+			Title: "todo",
+			Tags: []Tag{
+				{Name: "work"},
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		err     = errors.New("error")
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("todos"), mock.Anything, OnConflict{}).Return(1, nil).Once()
+	adapter.On("Insert", From("tags"), mock.Anything, OnConflict{}).Return(0, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Insert(context.TODO(), &todo))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_error(t *testing.T) {
+	var (
+		user     User
+		adapter  = &testAdapter{}
+		repo     = New(adapter)
+		mutators = []Mutator{
+			Set("name", "name"),
+			Set("created_at", now()),
+			Set("updated_at", now()),
+		}
+		mutates = map[string]Mutate{
+			"name":       Set("name", "name"),
+			"created_at": Set("created_at", now()),
+			"updated_at": Set("updated_at", now()),
+		}
+	)
+
+	adapter.On("Insert", From("users"), mutates, OnConflict{}).Return(0, errors.New("error")).Once()
+
+	assert.NotNil(t, repo.Insert(context.TODO(), &user, mutators...))
+	assert.Panics(t, func() { repo.MustInsert(context.TODO(), &user, mutators...) })
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_customError(t *testing.T) {
+	var (
+		user     User
+		adapter  = &testAdapter{}
+		repo     = New(adapter)
+		mutators = []Mutator{
+			Set("name", "name"),
+			ErrorFunc(func(err error) error { return errors.New("custom error") }),
+		}
+		mutates = map[string]Mutate{
+			"name": Set("name", "name"),
+		}
+	)
+
+	adapter.On("Insert", From("users"), mutates, OnConflict{}).Return(0, errors.New("error")).Once()
+
+	assert.Equal(t, errors.New("custom error"), repo.Insert(context.TODO(), &user, mutators...))
+	assert.Panics(t, func() { repo.MustInsert(context.TODO(), &user, mutators...) })
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_validationError(t *testing.T) {
+	var (
+		user      User
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		changeset = NewChangeset(&user).Validate(ValidateRequired("name"))
+		err       = ValidationError{Errors: []FieldError{{Field: "name", Message: "can't be blank"}}}
+	)
+
+	assert.Equal(t, err, repo.Insert(context.TODO(), &user, changeset))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_assocValidationError(t *testing.T) {
+	var (
+		user = User{
+			Name:         "name",
+			Address:      Address{Notes: "notes"},
+			Transactions: []Transaction{{Item: "soap"}, {}},
+		}
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		changeset = NewChangeset(&user).Validate(
+			ValidateAssoc("address", ValidateRequired("street")),
+			ValidateAssoc("transactions", ValidateRequired("item")),
+		)
+		err = ValidationError{Errors: []FieldError{
+			{Field: "address.street", Message: "can't be blank"},
+			{Field: "transactions[1].item", Message: "can't be blank"},
+		}}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Insert(context.TODO(), &user, changeset))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_InsertAll_validationError(t *testing.T) {
+	var (
+		users     = []User{{Name: "name"}, {}}
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		changeset = NewChangeset(&users[1]).Validate(ValidateRequired("name"))
+		err       = ValidationError{Errors: []FieldError{{Field: "name", Message: "can't be blank"}}}
+	)
+
+	assert.Equal(t, err, repo.InsertAll(context.TODO(), &users, changeset))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_constraintErrorFunc(t *testing.T) {
+	var (
+		user      = User{Name: "name"}
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		changeset = NewChangeset(&user).UniqueConstraint("name")
+		errorFunc = ErrorFunc(func(err error) error { return errors.New("custom error: " + err.Error()) })
+	)
+
+	adapter.On("Insert", From("users"), mock.Anything, OnConflict{}).Return(0, ConstraintError{Key: "users_name_key", Type: UniqueConstraint}).Twice()
+
+	// constraint is translated before error func, regardless of the order.
+	assert.Equal(t, errors.New("custom error: "+ValidationError{
+		Errors: []FieldError{{Field: "name", Message: "has already been taken"}},
+	}.Error()), repo.Insert(context.TODO(), &user, changeset, errorFunc))
+	assert.Equal(t, errors.New("custom error: "+ValidationError{
+		Errors: []FieldError{{Field: "name", Message: "has already been taken"}},
+	}.Error()), repo.Insert(context.TODO(), &user, errorFunc, changeset))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_constraintValidationError(t *testing.T) {
+	var (
+		user      User
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		changeset = NewChangeset(&user).UniqueConstraint("name")
+	)
+
+	user.Name = "name"
+
+	adapter.On("Insert", From("users"), mock.Anything, OnConflict{}).Return(0, ConstraintError{Key: "users.name", Type: UniqueConstraint}).Once()
+
+	assert.Equal(t, ValidationError{
+		Errors: []FieldError{{Field: "name", Message: "has already been taken"}},
+	}, repo.Insert(context.TODO(), &user, changeset))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_customErrorNested(t *testing.T) {
+	var (
+		profile = Profile{
+			Name: "profile name",
+			User: &User{
+				Name: "name",
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("users"), mock.Anything, OnConflict{}).Return(1, errors.New("error")).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, errors.New("error"), repo.Insert(context.TODO(), &profile,
+		NewStructset(&profile, false),
+		ErrorFunc(func(err error) error { return errors.New("custom error") }), // should not transform any errors of its children.
+	))
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_nothing(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	assert.Nil(t, repo.Insert(context.TODO(), nil))
+	assert.NotPanics(t, func() { repo.MustInsert(context.TODO(), nil) })
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_InsertAll(t *testing.T) {
+	var (
+		users = []User{
+			{Name: "name1"},
+			{Name: "name2", Age: 12},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		mutates = []map[string]Mutate{
+			{
+				"name":       Set("name", "name1"),
+				"age":        Set("age", 0),
+				"created_at": Set("created_at", now()),
+				"updated_at": Set("updated_at", now()),
+			},
+			{
+				"name":       Set("name", "name2"),
+				"age":        Set("age", 12),
+				"created_at": Set("created_at", now()),
+				"updated_at": Set("updated_at", now()),
+			},
+		}
+	)
+
+	adapter.On("InsertAll", From("users"), mock.Anything, mutates, OnConflict{}).Return([]interface{}{1, 2}, nil).Once()
+
+	assert.Nil(t, repo.InsertAll(context.TODO(), &users))
+	assert.Equal(t, []User{
+		{ID: 1, Name: "name1", Age: 0, CreatedAt: now(), UpdatedAt: now()},
+		{ID: 2, Name: "name2", Age: 12, CreatedAt: now(), UpdatedAt: now()},
+	}, users)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_InsertAll_onConflictReplace(t *testing.T) {
+	var (
+		users = []User{
+			{Name: "name1"},
+			{Name: "name2", Age: 12},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		mutates = []map[string]Mutate{
+			{
+				"name":       Set("name", "name1"),
+				"age":        Set("age", 0),
+				"created_at": Set("created_at", now()),
+				"updated_at": Set("updated_at", now()),
+			},
+			{
+				"name":       Set("name", "name2"),
+				"age":        Set("age", 12),
+				"created_at": Set("created_at", now()),
+				"updated_at": Set("updated_at", now()),
+			},
+		}
+		onConflict = OnConflict{Keys: []string{"name"}, Replace: true}
+	)
+
+	adapter.On("InsertAll", From("users"), mock.Anything, mutates, onConflict).Return([]interface{}{1, 2}, nil).Once()
+
+	assert.Nil(t, repo.InsertAll(context.TODO(), &users, OnConflictKeyReplace("name")))
+	assert.Equal(t, []User{
+		{ID: 1, Name: "name1", Age: 0, CreatedAt: now(), UpdatedAt: now()},
+		{ID: 2, Name: "name2", Age: 12, CreatedAt: now(), UpdatedAt: now()},
+	}, users)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_InsertAll_onConflictIgnoreReload(t *testing.T) {
+	var (
+		users = []User{
+			{Name: "name1"},
+			{Name: "name2", Age: 12},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		mutates = []map[string]Mutate{
+			{
+				"name":       Set("name", "name1"),
+				"age":        Set("age", 0),
+				"created_at": Set("created_at", now()),
+				"updated_at": Set("updated_at", now()),
+			},
+			{
+				"name":       Set("name", "name2"),
+				"age":        Set("age", 12),
+				"created_at": Set("created_at", now()),
+				"updated_at": Set("updated_at", now()),
+			},
+		}
+		onConflict = OnConflict{Keys: []string{"name"}, Ignore: true}
+		cur1       = createCursor(1)
+		cur2       = createCursor(1)
+	)
+
+	adapter.On("InsertAll", From("users"), mock.Anything, mutates, onConflict).Return([]interface{}{2}, nil).Once()
+	adapter.On("Query", From("users").Where(Eq("name", "name1")).Unscoped().Limit(1)).Return(cur1, nil).Once()
+	adapter.On("Query", From("users").Where(Eq("name", "name2")).Unscoped().Limit(1)).Return(cur2, nil).Once()
+
+	assert.Nil(t, repo.InsertAll(context.TODO(), &users, OnConflictKeyIgnore("name")))
+	assert.Equal(t, 10, users[0].ID)
+	assert.Equal(t, 10, users[1].ID)
+	assert.False(t, cur1.Next())
+	assert.False(t, cur2.Next())
+
+	adapter.AssertExpectations(t)
+	cur1.AssertExpectations(t)
+	cur2.AssertExpectations(t)
+}
+
+func TestRepository_InsertAll_onConflictIgnoreReloadError(t *testing.T) {
+	var (
+		users = []User{
+			{Name: "name1"},
+		}
+		adapter    = &testAdapter{}
+		repo       = New(adapter)
+		onConflict = OnConflict{Keys: []string{"name"}, Ignore: true}
+		cur        = &testCursor{}
+		err        = errors.New("error")
+	)
+
+	adapter.On("InsertAll", From("users"), mock.Anything, mock.Anything, onConflict).Return([]interface{}(nil), nil).Once()
+	adapter.On("Query", From("users").Where(Eq("name", "name1")).Unscoped().Limit(1)).Return(cur, err).Once()
+
+	assert.Equal(t, err, repo.InsertAll(context.TODO(), &users, OnConflictKeyIgnore("name")))
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_InsertAll_onConflictIgnoreNotInserted(t *testing.T) {
+	var (
+		users = []User{
+			{Name: "name1"},
+			{Name: "name2"},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("InsertAll", From("users"), mock.Anything, mock.Anything, OnConflict{Ignore: true}).Return([]interface{}(nil), nil).Once()
+
+	assert.Equal(t, ErrNotInserted, repo.InsertAll(context.TODO(), &users, OnConflictIgnore()))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_InsertAll_compositePrimaryFields(t *testing.T) {
+	var (
+		userRoles = []UserRole{
+			{UserID: 1, RoleID: 2},
+			{UserID: 1, RoleID: 3},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		mutates = []map[string]Mutate{
+			{
+				"user_id": Set("user_id", 1),
+				"role_id": Set("role_id", 2),
+			},
+			{
+				"user_id": Set("user_id", 1),
+				"role_id": Set("role_id", 3),
+			},
+		}
+	)
+
+	adapter.On("InsertAll", From("user_roles"), mock.Anything, mutates, OnConflict{}).Return([]interface{}{0, 0}, nil).Once()
+
+	assert.Nil(t, repo.InsertAll(context.TODO(), &userRoles))
+	assert.Equal(t, []UserRole{
+		{UserID: 1, RoleID: 2},
+		{UserID: 1, RoleID: 3},
+	}, userRoles)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_InsertAll_primaryGenerator(t *testing.T) {
+	var (
+		sessions = []Session{
+			{UserID: 1},
+			{ID: Token{4, 3, 2, 1}, UserID: 2},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		mutates = []map[string]Mutate{
+			{
+				"id":      Set("id", Token{1, 2, 3, 4}),
+				"user_id": Set("user_id", 1),
+			},
+			{
+				"id":      Set("id", Token{4, 3, 2, 1}),
+				"user_id": Set("user_id", 2),
+			},
+		}
+	)
+
+	RegisterPrimaryGenerator(Token{}, func() interface{} {
+		return Token{1, 2, 3, 4}
+	})
+	defer RegisterPrimaryGenerator(Token{}, nil)
+
+	adapter.On("InsertAll", From("sessions"), mock.Anything, mutates, OnConflict{}).Return([]interface{}{[]byte{1, 2, 3, 4}, []byte{4, 3, 2, 1}}, nil).Once()
+
+	assert.Nil(t, repo.InsertAll(context.TODO(), &sessions))
+	assert.Equal(t, []Session{
+		{ID: Token{1, 2, 3, 4}, UserID: 1},
+		{ID: Token{4, 3, 2, 1}, UserID: 2},
+	}, sessions)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_InsertAll_scannerPrimaryError(t *testing.T) {
+	var (
+		sessions = []Session{
+			{UserID: 1},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("InsertAll", From("sessions"), mock.Anything, mock.Anything, OnConflict{}).Return([]interface{}{"token"}, nil).Once()
+
+	assert.Equal(t, errors.New("invalid token"), repo.InsertAll(context.TODO(), &sessions))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_InsertAll_empty(t *testing.T) {
+	var (
+		users   []User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	assert.Nil(t, repo.InsertAll(context.TODO(), &users))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_InsertAll_nothing(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	assert.Nil(t, repo.InsertAll(context.TODO(), nil))
+	assert.NotPanics(t, func() { repo.MustInsertAll(context.TODO(), nil) })
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		user    = User{
+			ID:        1,
+			Name:      "name",
+			CreatedAt: now(),
+			UpdatedAt: now(),
+		}
+		mutates = map[string]Mutate{
+			"id":         Set("id", 1),
+			"name":       Set("name", "name"),
+			"age":        Set("age", 0),
+			"created_at": Set("created_at", now()),
+			"updated_at": Set("updated_at", now()),
+		}
+		queries = From("users").Where(Eq("id", user.ID))
+	)
+
+	adapter.On("Update", queries, mutates).Return(1, nil).Once()
 
-	assert.Nil(t, repo.Insert(context.TODO(), &user, Cascade(false)))
+	assert.Nil(t, repo.Update(context.TODO(), &user))
 	assert.Equal(t, User{
 		ID:        1,
 		Name:      "name",
 		CreatedAt: now(),
 		UpdatedAt: now(),
-		UserRoles: []UserRole{
-			{RoleID: 2},
-		},
 	}, user)
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Insert_saveHasManyError(t *testing.T) {
+func TestRepository_Update_validationError(t *testing.T) {
 	var (
-		user = User{
-			Name: "name",
-			UserRoles: []UserRole{
-				{RoleID: 2},
-			},
-		}
-		adapter = &testAdapter{}
-		repo    = New(adapter)
-		err     = errors.New("error")
+		user      = User{ID: 1, Name: "name"}
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		changeset = NewChangeset(&user).Validate(ValidateLength("name", 5, 0))
+		err       = ValidationError{Errors: []FieldError{{Field: "name", Message: "length must be at least 5"}}}
 	)
 
-	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Insert", From("users"), mock.Anything).Return(1, nil).Once()
-	adapter.On("InsertAll", From("user_roles"), mock.Anything, mock.Anything).Return([]interface{}{}, err).Once()
-	adapter.On("Rollback").Return(nil).Once()
+	user.Name = "rel"
 
-	assert.Equal(t, err, repo.Insert(context.TODO(), &user))
+	assert.Equal(t, err, repo.Update(context.TODO(), &user, changeset))
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Insert_error(t *testing.T) {
+func TestRepository_Update_compositePrimaryKeys(t *testing.T) {
 	var (
-		user     User
 		adapter  = &testAdapter{}
 		repo     = New(adapter)
-		mutators = []Mutator{
-			Set("name", "name"),
-			Set("created_at", now()),
-			Set("updated_at", now()),
+		userRole = UserRole{
+			UserID: 1,
+			RoleID: 2,
 		}
 		mutates = map[string]Mutate{
-			"name":       Set("name", "name"),
-			"created_at": Set("created_at", now()),
-			"updated_at": Set("updated_at", now()),
+			"user_id": Set("user_id", 1),
+			"role_id": Set("role_id", 2),
 		}
+		queries = From("user_roles").Where(Eq("user_id", userRole.UserID).AndEq("role_id", userRole.RoleID))
 	)
 
-	adapter.On("Insert", From("users"), mutates).Return(0, errors.New("error")).Once()
+	adapter.On("Update", queries, mutates).Return(1, nil).Once()
 
-	assert.NotNil(t, repo.Insert(context.TODO(), &user, mutators...))
-	assert.Panics(t, func() { repo.MustInsert(context.TODO(), &user, mutators...) })
+	assert.Nil(t, repo.Update(context.TODO(), &userRole))
+	assert.Equal(t, UserRole{
+		UserID: 1,
+		RoleID: 2,
+	}, userRole)
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Insert_customError(t *testing.T) {
+func TestRepository_Update_sets(t *testing.T) {
 	var (
-		user     User
+		user     = User{ID: 1}
 		adapter  = &testAdapter{}
 		repo     = New(adapter)
 		mutators = []Mutator{
 			Set("name", "name"),
-			ErrorFunc(func(err error) error { return errors.New("custom error") }),
+			Set("updated_at", now()),
 		}
 		mutates = map[string]Mutate{
-			"name": Set("name", "name"),
+			"name":       Set("name", "name"),
+			"updated_at": Set("updated_at", now()),
 		}
+		queries = From("users").Where(Eq("id", user.ID))
 	)
 
-	adapter.On("Insert", From("users"), mutates).Return(0, errors.New("error")).Once()
+	adapter.On("Update", queries, mutates).Return(1, nil).Once()
 
-	assert.Equal(t, errors.New("custom error"), repo.Insert(context.TODO(), &user, mutators...))
-	assert.Panics(t, func() { repo.MustInsert(context.TODO(), &user, mutators...) })
+	assert.Nil(t, repo.Update(context.TODO(), &user, mutators...))
+	assert.Equal(t, User{
+		ID:        1,
+		Name:      "name",
+		UpdatedAt: now(),
+	}, user)
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Insert_customErrorNested(t *testing.T) {
+func TestRepository_Update_softDelete(t *testing.T) {
 	var (
-		profile = Profile{
-			Name: "profile name",
-			User: &User{
-				Name: "name",
-			},
+		address  = Address{ID: 1}
+		adapter  = &testAdapter{}
+		repo     = New(adapter)
+		mutators = []Mutator{
+			Set("street", "street"),
 		}
-		adapter = &testAdapter{}
-		repo    = New(adapter)
+		mutates = map[string]Mutate{
+			"street": Set("street", "street"),
+		}
+		queries = From("addresses").Where(Eq("id", address.ID))
 	)
 
-	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Insert", From("users"), mock.Anything).Return(1, errors.New("error")).Once()
-	adapter.On("Rollback").Return(nil).Once()
-
-	assert.Equal(t, errors.New("error"), repo.Insert(context.TODO(), &profile,
-		NewStructset(&profile, false),
-		ErrorFunc(func(err error) error { return errors.New("custom error") }), // should not transform any errors of its children.
-	))
-	adapter.AssertExpectations(t)
-}
-
-func TestRepository_Insert_nothing(t *testing.T) {
-	var (
-		adapter = &testAdapter{}
-		repo    = New(adapter)
-	)
+	adapter.On("Update", queries.Where(Nil("deleted_at")), mutates).Return(1, nil).Once()
 
-	assert.Nil(t, repo.Insert(context.TODO(), nil))
-	assert.NotPanics(t, func() { repo.MustInsert(context.TODO(), nil) })
+	assert.Nil(t, repo.Update(context.TODO(), &address, mutators...))
+	assert.Equal(t, Address{
+		ID:     1,
+		Street: "street",
+	}, address)
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_InsertAll(t *testing.T) {
+func TestRepository_Update_softDeleteUnscoped(t *testing.T) {
 	var (
-		users = []User{
-			{Name: "name1"},
-			{Name: "name2", Age: 12},
+		address  = Address{ID: 1}
+		adapter  = &testAdapter{}
+		repo     = New(adapter)
+		mutators = []Mutator{
+			Unscoped(true),
+			Set("street", "street"),
 		}
-		adapter = &testAdapter{}
-		repo    = New(adapter)
-		mutates = []map[string]Mutate{
-			{
-				"name":       Set("name", "name1"),
-				"age":        Set("age", 0),
-				"created_at": Set("created_at", now()),
-				"updated_at": Set("updated_at", now()),
-			},
-			{
-				"name":       Set("name", "name2"),
-				"age":        Set("age", 12),
-				"created_at": Set("created_at", now()),
-				"updated_at": Set("updated_at", now()),
-			},
+		mutates = map[string]Mutate{
+			"street": Set("street", "street"),
 		}
+		queries = From("addresses").Where(Eq("id", address.ID)).Unscoped()
 	)
 
-	adapter.On("InsertAll", From("users"), mock.Anything, mutates).Return([]interface{}{1, 2}, nil).Once()
+	adapter.On("Update", queries, mutates).Return(1, nil).Once()
 
-	assert.Nil(t, repo.InsertAll(context.TODO(), &users))
-	assert.Equal(t, []User{
-		{ID: 1, Name: "name1", Age: 0, CreatedAt: now(), UpdatedAt: now()},
-		{ID: 2, Name: "name2", Age: 12, CreatedAt: now(), UpdatedAt: now()},
-	}, users)
+	assert.Nil(t, repo.Update(context.TODO(), &address, mutators...))
+	assert.Equal(t, Address{
+		ID:     1,
+		Street: "street",
+	}, address)
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_InsertAll_compositePrimaryFields(t *testing.T) {
+func TestRepository_Update_notFound(t *testing.T) {
 	var (
-		userRoles = []UserRole{
-			{UserID: 1, RoleID: 2},
-			{UserID: 1, RoleID: 3},
+		user     = User{ID: 1}
+		adapter  = &testAdapter{}
+		repo     = New(adapter)
+		mutators = []Mutator{
+			Set("name", "name"),
+			Set("updated_at", now()),
 		}
-		adapter = &testAdapter{}
-		repo    = New(adapter)
-		mutates = []map[string]Mutate{
-			{
-				"user_id": Set("user_id", 1),
-				"role_id": Set("role_id", 2),
-			},
-			{
-				"user_id": Set("user_id", 1),
-				"role_id": Set("role_id", 3),
-			},
+		mutates = map[string]Mutate{
+			"name":       Set("name", "name"),
+			"updated_at": Set("updated_at", now()),
 		}
+		queries = From("users").Where(Eq("id", user.ID))
 	)
 
-	adapter.On("InsertAll", From("user_roles"), mock.Anything, mutates).Return([]interface{}{0, 0}, nil).Once()
+	adapter.On("Update", queries, mutates).Return(0, nil).Once()
 
-	assert.Nil(t, repo.InsertAll(context.TODO(), &userRoles))
-	assert.Equal(t, []UserRole{
-		{UserID: 1, RoleID: 2},
-		{UserID: 1, RoleID: 3},
-	}, userRoles)
+	assert.Equal(t, NotFoundError{}, repo.Update(context.TODO(), &user, mutators...))
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_InsertAll_empty(t *testing.T) {
+func TestRepository_Update_reload(t *testing.T) {
 	var (
-		users   []User
-		adapter = &testAdapter{}
-		repo    = New(adapter)
+		user     = User{ID: 1}
+		adapter  = &testAdapter{}
+		repo     = New(adapter)
+		mutators = []Mutator{
+			SetFragment("name=?", "name"),
+		}
+		mutates = map[string]Mutate{
+			"name=?": SetFragment("name=?", "name"),
+		}
+		queries = From("users").Where(Eq("id", user.ID))
+		cur     = createCursor(1)
 	)
 
-	assert.Nil(t, repo.InsertAll(context.TODO(), &users))
+	adapter.On("Update", queries, mutates).Return(1, nil).Once()
+	adapter.On("Query", queries.Limit(1)).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Update(context.TODO(), &user, mutators...))
+	assert.False(t, cur.Next())
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_InsertAll_nothing(t *testing.T) {
+func TestRepository_Update_reloadError(t *testing.T) {
 	var (
-		adapter = &testAdapter{}
-		repo    = New(adapter)
+		user     = User{ID: 1}
+		adapter  = &testAdapter{}
+		repo     = New(adapter)
+		mutators = []Mutator{
+			SetFragment("name=?", "name"),
+		}
+		mutates = map[string]Mutate{
+			"name=?": SetFragment("name=?", "name"),
+		}
+		queries = From("users").Where(Eq("id", user.ID))
+		cur     = &testCursor{}
+		err     = errors.New("error")
 	)
 
-	assert.Nil(t, repo.InsertAll(context.TODO(), nil))
-	assert.NotPanics(t, func() { repo.MustInsertAll(context.TODO(), nil) })
+	adapter.On("Update", queries, mutates).Return(1, nil).Once()
+	adapter.On("Query", queries.Limit(1)).Return(cur, err).Once()
+
+	assert.Equal(t, err, repo.Update(context.TODO(), &user, mutators...))
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Update(t *testing.T) {
+func TestRepository_Update_returning(t *testing.T) {
 	var (
-		adapter = &testAdapter{}
-		repo    = New(adapter)
-		user    = User{
-			ID:        1,
-			Name:      "name",
-			CreatedAt: now(),
-			UpdatedAt: now(),
+		user     = User{ID: 1}
+		adapter  = &testAdapter{returning: true}
+		repo     = New(adapter)
+		mutators = []Mutator{
+			SetFragment("name=?", "name"),
 		}
 		mutates = map[string]Mutate{
-			"id":         Set("id", 1),
-			"name":       Set("name", "name"),
-			"age":        Set("age", 0),
-			"created_at": Set("created_at", now()),
-			"updated_at": Set("updated_at", now()),
+			"name=?": SetFragment("name=?", "name"),
 		}
 		queries = From("users").Where(Eq("id", user.ID))
+		cur     = createCursor(1)
 	)
 
-	adapter.On("Update", queries, mutates).Return(1, nil).Once()
+	adapter.On("UpdateReturning", queries, mutates).Return(cur, nil).Once()
 
-	assert.Nil(t, repo.Update(context.TODO(), &user))
-	assert.Equal(t, User{
-		ID:        1,
-		Name:      "name",
-		CreatedAt: now(),
-		UpdatedAt: now(),
-	}, user)
+	assert.Nil(t, repo.Update(context.TODO(), &user, mutators...))
+	assert.Equal(t, 10, user.ID)
+	assert.False(t, cur.Next())
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Update_compositePrimaryKeys(t *testing.T) {
+func TestRepository_Update_returningNotFound(t *testing.T) {
 	var (
-		adapter  = &testAdapter{}
+		user     = User{ID: 1}
+		adapter  = &testAdapter{returning: true}
 		repo     = New(adapter)
-		userRole = UserRole{
-			UserID: 1,
-			RoleID: 2,
+		mutators = []Mutator{
+			Inc("age"),
 		}
 		mutates = map[string]Mutate{
-			"user_id": Set("user_id", 1),
-			"role_id": Set("role_id", 2),
+			"age": Inc("age"),
 		}
-		queries = From("user_roles").Where(Eq("user_id", userRole.UserID).AndEq("role_id", userRole.RoleID))
+		queries = From("users").Where(Eq("id", user.ID))
+		cur     = createCursor(0)
 	)
 
-	adapter.On("Update", queries, mutates).Return(1, nil).Once()
+	adapter.On("UpdateReturning", queries, mutates).Return(cur, nil).Once()
 
-	assert.Nil(t, repo.Update(context.TODO(), &userRole))
-	assert.Equal(t, UserRole{
-		UserID: 1,
-		RoleID: 2,
-	}, userRole)
+	assert.Equal(t, NotFoundError{}, repo.Update(context.TODO(), &user, mutators...))
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Update_sets(t *testing.T) {
+func TestRepository_Update_returningError(t *testing.T) {
 	var (
 		user     = User{ID: 1}
-		adapter  = &testAdapter{}
+		adapter  = &testAdapter{returning: true}
 		repo     = New(adapter)
 		mutators = []Mutator{
-			Set("name", "name"),
-			Set("updated_at", now()),
+			Inc("age"),
 		}
 		mutates = map[string]Mutate{
-			"name":       Set("name", "name"),
-			"updated_at": Set("updated_at", now()),
+			"age": Inc("age"),
 		}
 		queries = From("users").Where(Eq("id", user.ID))
+		cur     = &testCursor{}
+		err     = errors.New("error")
 	)
 
-	adapter.On("Update", queries, mutates).Return(1, nil).Once()
+	adapter.On("UpdateReturning", queries, mutates).Return(cur, err).Once()
 
-	assert.Nil(t, repo.Update(context.TODO(), &user, mutators...))
-	assert.Equal(t, User{
-		ID:        1,
-		Name:      "name",
-		UpdatedAt: now(),
-	}, user)
+	assert.Equal(t, err, repo.Update(context.TODO(), &user, mutators...))
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Update_softDelete(t *testing.T) {
+func TestRepository_Update_returningWithoutReload(t *testing.T) {
 	var (
-		address  = Address{ID: 1}
-		adapter  = &testAdapter{}
+		user     = User{ID: 1}
+		adapter  = &testAdapter{returning: true}
 		repo     = New(adapter)
 		mutators = []Mutator{
-			Set("street", "street"),
+			Set("name", "name"),
 		}
 		mutates = map[string]Mutate{
-			"street": Set("street", "street"),
+			"name": Set("name", "name"),
 		}
-		queries = From("addresses").Where(Eq("id", address.ID))
+		queries = From("users").Where(Eq("id", user.ID))
 	)
 
-	adapter.On("Update", queries.Where(Nil("deleted_at")), mutates).Return(1, nil).Once()
+	adapter.On("Update", queries, mutates).Return(1, nil).Once()
 
-	assert.Nil(t, repo.Update(context.TODO(), &address, mutators...))
-	assert.Equal(t, Address{
-		ID:     1,
-		Street: "street",
-	}, address)
+	assert.Nil(t, repo.Update(context.TODO(), &user, mutators...))
+	assert.Equal(t, "name", user.Name)
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Update_softDeleteUnscoped(t *testing.T) {
+func TestRepository_Update_lockVersion(t *testing.T) {
 	var (
-		address  = Address{ID: 1}
+		ticket   = Ticket{ID: 1, LockVersion: 2}
 		adapter  = &testAdapter{}
 		repo     = New(adapter)
 		mutators = []Mutator{
-			Unscoped(true),
-			Set("street", "street"),
+			Set("name", "name"),
 		}
 		mutates = map[string]Mutate{
-			"street": Set("street", "street"),
+			"name":         Set("name", "name"),
+			"lock_version": Set("lock_version", 3),
 		}
-		queries = From("addresses").Where(Eq("id", address.ID)).Unscoped()
+		queries = From("tickets").Where(Eq("id", ticket.ID)).Where(Eq("lock_version", 2))
 	)
 
 	adapter.On("Update", queries, mutates).Return(1, nil).Once()
 
-	assert.Nil(t, repo.Update(context.TODO(), &address, mutators...))
-	assert.Equal(t, Address{
-		ID:     1,
-		Street: "street",
-	}, address)
+	assert.Nil(t, repo.Update(context.TODO(), &ticket, mutators...))
+	assert.Equal(t, Ticket{ID: 1, Name: "name", LockVersion: 3}, ticket)
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Update_notFound(t *testing.T) {
+func TestRepository_Update_lockVersionStale(t *testing.T) {
 	var (
-		user     = User{ID: 1}
-		adapter  = &testAdapter{}
-		repo     = New(adapter)
-		mutators = []Mutator{
-			Set("name", "name"),
-			Set("updated_at", now()),
-		}
+		ticket  = Ticket{ID: 1, Name: "name", LockVersion: 2}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
 		mutates = map[string]Mutate{
-			"name":       Set("name", "name"),
-			"updated_at": Set("updated_at", now()),
+			"id":           Set("id", 1),
+			"name":         Set("name", "name"),
+			"lock_version": Set("lock_version", 3),
 		}
-		queries = From("users").Where(Eq("id", user.ID))
+		queries = From("tickets").Where(Eq("id", ticket.ID)).Where(Eq("lock_version", 2))
 	)
 
 	adapter.On("Update", queries, mutates).Return(0, nil).Once()
 
-	assert.Equal(t, NotFoundError{}, repo.Update(context.TODO(), &user, mutators...))
+	assert.Equal(t, StaleObjectError{}, repo.Update(context.TODO(), &ticket))
+	assert.Equal(t, 2, ticket.LockVersion)
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Update_reload(t *testing.T) {
+func TestRepository_Update_lockVersionReload(t *testing.T) {
 	var (
-		user     = User{ID: 1}
+		ticket   = Ticket{ID: 1, LockVersion: 2}
 		adapter  = &testAdapter{}
 		repo     = New(adapter)
 		mutators = []Mutator{
 			SetFragment("name=?", "name"),
 		}
 		mutates = map[string]Mutate{
-			"name=?": SetFragment("name=?", "name"),
+			"name=?":       SetFragment("name=?", "name"),
+			"lock_version": Set("lock_version", 3),
 		}
-		queries = From("users").Where(Eq("id", user.ID))
+		queries = From("tickets").Where(Eq("id", ticket.ID))
 		cur     = createCursor(1)
 	)
 
-	adapter.On("Update", queries, mutates).Return(1, nil).Once()
+	adapter.On("Update", queries.Where(Eq("lock_version", 2)), mutates).Return(1, nil).Once()
 	adapter.On("Query", queries.Limit(1)).Return(cur, nil).Once()
 
-	assert.Nil(t, repo.Update(context.TODO(), &user, mutators...))
+	assert.Nil(t, repo.Update(context.TODO(), &ticket, mutators...))
+	assert.Equal(t, 3, ticket.LockVersion)
 	assert.False(t, cur.Next())
 
 	adapter.AssertExpectations(t)
 	cur.AssertExpectations(t)
 }
 
-func TestRepository_Update_reloadError(t *testing.T) {
+func TestRepository_Update_lockVersionReturningStale(t *testing.T) {
 	var (
-		user     = User{ID: 1}
-		adapter  = &testAdapter{}
+		ticket   = Ticket{ID: 1, LockVersion: 2}
+		adapter  = &testAdapter{returning: true}
 		repo     = New(adapter)
 		mutators = []Mutator{
 			SetFragment("name=?", "name"),
 		}
 		mutates = map[string]Mutate{
-			"name=?": SetFragment("name=?", "name"),
+			"name=?":       SetFragment("name=?", "name"),
+			"lock_version": Set("lock_version", 3),
 		}
-		queries = From("users").Where(Eq("id", user.ID))
-		cur     = &testCursor{}
-		err     = errors.New("error")
+		queries = From("tickets").Where(Eq("id", ticket.ID)).Where(Eq("lock_version", 2))
+		cur     = createCursor(0)
 	)
 
-	adapter.On("Update", queries, mutates).Return(1, nil).Once()
-	adapter.On("Query", queries.Limit(1)).Return(cur, err).Once()
+	adapter.On("UpdateReturning", queries, mutates).Return(cur, nil).Once()
 
-	assert.Equal(t, err, repo.Update(context.TODO(), &user, mutators...))
+	assert.Equal(t, StaleObjectError{}, repo.Update(context.TODO(), &ticket, mutators...))
+	assert.Equal(t, 2, ticket.LockVersion)
 
 	adapter.AssertExpectations(t)
 	cur.AssertExpectations(t)
@@ -1383,20 +3128,229 @@ func TestRepository_Update_saveHasOneCascadeDisabled(t *testing.T) {
 			Street: "street",
 			UserID: &userID,
 		},
-	}, user)
+	}, user)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_saveHasOneError(t *testing.T) {
+	var (
+		userID = 10
+		user   = User{
+			ID: userID,
+			Address: Address{
+				ID:     1,
+				Street: "street",
+				UserID: &userID,
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		err     = errors.New("error")
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("users").Where(Eq("id", 10)), mock.Anything).Return(1, nil).Once()
+	adapter.On("Update", From("addresses").Where(Eq("id", 1).AndEq("user_id", 10).AndNil("deleted_at")), mock.Anything).Return(1, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Update(context.TODO(), &user))
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_saveHasMany(t *testing.T) {
+	var (
+		user = User{
+			ID: 10,
+			UserRoles: []UserRole{
+				{RoleID: 2},
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("users").Where(Eq("id", 10)), mock.Anything).Return(1, nil).Once()
+	adapter.On("Delete", From("user_roles").Where(Eq("user_id", 10))).Return(1, nil).Once()
+	adapter.On("InsertAll", From("user_roles"), mock.Anything, mock.Anything, OnConflict{}).Return([]interface{}(nil), nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Update(context.TODO(), &user))
+	assert.Equal(t, User{
+		ID:        10,
+		CreatedAt: now(),
+		UpdatedAt: now(),
+		UserRoles: []UserRole{
+			{UserID: 10, RoleID: 2},
+		},
+	}, user)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_saveHasManyCascadeDisabled(t *testing.T) {
+	var (
+		user = User{
+			ID: 10,
+			UserRoles: []UserRole{
+				{RoleID: 2},
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Update", From("users").Where(Eq("id", 10)), mock.Anything).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Update(context.TODO(), &user, Cascade(false)))
+	assert.Equal(t, User{
+		ID:        10,
+		CreatedAt: now(),
+		UpdatedAt: now(),
+		UserRoles: []UserRole{
+			{RoleID: 2},
+		},
+	}, user)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_saveHasManyError(t *testing.T) {
+	var (
+		user = User{
+			ID: 10,
+			UserRoles: []UserRole{
+				{RoleID: 2},
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		err     = errors.New("error")
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("users").Where(Eq("id", 10)), mock.Anything).Return(1, nil).Once()
+	adapter.On("Delete", From("user_roles").Where(Eq("user_id", 10))).Return(0, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Update(context.TODO(), &user))
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_savePolymorphicBelongsToInconsistentType(t *testing.T) {
+	var (
+		comment = Comment{
+			ID:        2,
+			OwnerType: "photos",
+			OwnerID:   1,
+			Video:     &Video{ID: 1},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, ConstraintError{
+		Key:  "owner_type",
+		Type: ForeignKeyConstraint,
+		Err:  errors.New("rel: inconsistent polymorphic type"),
+	}, repo.Update(context.TODO(), &comment))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_savePolymorphicHasMany(t *testing.T) {
+	var (
+		video = Video{
+			ID: 1,
+			Comments: []Comment{
+				{Body: "comment"},
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("videos").Where(Eq("id", 1)), mock.Anything).Return(1, nil).Once()
+	adapter.On("Delete", From("comments").Where(Eq("owner_id", 1).AndEq("owner_type", "videos"))).Return(1, nil).Once()
+	adapter.On("InsertAll", From("comments"), mock.Anything, mock.Anything, OnConflict{}).Return([]interface{}{2}, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Update(context.TODO(), &video))
+	assert.Equal(t, []Comment{
+		{ID: 2, Body: "comment", OwnerType: "videos", OwnerID: 1},
+	}, video.Comments)
+
+	adapter.AssertExpectations(t)
+}
+
+func mockTodoTags(adapter *testAdapter, tagIDs ...int) *testCursor {
+	var (
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("todo_tags").Select("tag_id").Where(Eq("todo_id", 1))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	for _, id := range tagIDs {
+		cur.On("Next").Return(true).Once()
+		cur.MockScan(id).Once()
+	}
+	cur.On("Next").Return(false).Once()
+
+	return cur
+}
+
+func TestRepository_Update_saveManyToMany(t *testing.T) {
+	var (
+		todo = Todo{
+			ID:    1,
+			Title: "todo",
+			Tags: []Tag{
+				{ID: 2, Name: "home"},
+				{Name: "work"},
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		mutates = []map[string]Mutate{
+			{"todo_id": Set("todo_id", 1), "tag_id": Set("tag_id", 3)},
+		}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("todos").Where(Eq("id", 1)), mock.Anything).Return(1, nil).Once()
+	adapter.On("Insert", From("tags"), map[string]Mutate{"name": Set("name", "work")}, OnConflict{}).Return(3, nil).Once()
+	cur := mockTodoTags(adapter, 2, 4)
+	adapter.On("Delete", From("todo_tags").Where(Eq("todo_id", 1).AndIn("tag_id", 4))).Return(1, nil).Once()
+	adapter.On("InsertAll", From("todo_tags"), []string{"todo_id", "tag_id"}, mutates, OnConflict{}).Return([]interface{}(nil), nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Update(context.TODO(), &todo))
+	assert.Equal(t, Todo{
+		ID:    1,
+		Title: "todo",
+		Tags: []Tag{
+			{ID: 2, Name: "home"},
+			{ID: 3, Name: "work"},
+		},
+	}, todo)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Update_saveHasOneError(t *testing.T) {
+func TestRepository_Update_saveManyToManyError(t *testing.T) {
 	var (
-		userID = 10
-		user   = User{
-			ID: userID,
-			Address: Address{
-				ID:     1,
-				Street: "street",
-				UserID: &userID,
+		todo = Todo{
+			ID:    1,
+			Title: "todo",
+			Tags: []Tag{
+				{ID: 2, Name: "home"},
 			},
 		}
 		adapter = &testAdapter{}
@@ -1405,92 +3359,89 @@ func TestRepository_Update_saveHasOneError(t *testing.T) {
 	)
 
 	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Update", From("users").Where(Eq("id", 10)), mock.Anything).Return(1, nil).Once()
-	adapter.On("Update", From("addresses").Where(Eq("id", 1).AndEq("user_id", 10).AndNil("deleted_at")), mock.Anything).Return(1, err).Once()
+	adapter.On("Update", From("todos").Where(Eq("id", 1)), mock.Anything).Return(1, nil).Once()
+	mockTodoTags(adapter, 2, 3)
+	adapter.On("Delete", From("todo_tags").Where(Eq("todo_id", 1).AndIn("tag_id", 3))).Return(0, err).Once()
 	adapter.On("Rollback").Return(nil).Once()
 
-	assert.Equal(t, err, repo.Update(context.TODO(), &user))
+	assert.Equal(t, err, repo.Update(context.TODO(), &todo))
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Update_saveHasMany(t *testing.T) {
+func TestRepository_Update_saveManyToManyQueryError(t *testing.T) {
 	var (
-		user = User{
-			ID: 10,
-			UserRoles: []UserRole{
-				{RoleID: 2},
+		todo = Todo{
+			ID:    1,
+			Title: "todo",
+			Tags: []Tag{
+				{ID: 2, Name: "home"},
 			},
 		}
 		adapter = &testAdapter{}
 		repo    = New(adapter)
+		err     = errors.New("error")
 	)
 
 	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Update", From("users").Where(Eq("id", 10)), mock.Anything).Return(1, nil).Once()
-	adapter.On("Delete", From("user_roles").Where(Eq("user_id", 10))).Return(1, nil).Once()
-	adapter.On("InsertAll", From("user_roles"), mock.Anything, mock.Anything).Return([]interface{}(nil), nil).Once()
-	adapter.On("Commit").Return(nil).Once()
-
-	assert.Nil(t, repo.Update(context.TODO(), &user))
-	assert.Equal(t, User{
-		ID:        10,
-		CreatedAt: now(),
-		UpdatedAt: now(),
-		UserRoles: []UserRole{
-			{UserID: 10, RoleID: 2},
-		},
-	}, user)
+	adapter.On("Update", From("todos").Where(Eq("id", 1)), mock.Anything).Return(1, nil).Once()
+	adapter.On("Query", From("todo_tags").Select("tag_id").Where(Eq("todo_id", 1))).Return(&testCursor{}, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
 
+	assert.Equal(t, err, repo.Update(context.TODO(), &todo))
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Update_saveHasManyCascadeDisabled(t *testing.T) {
+func TestRepository_Update_saveManyToManyScanError(t *testing.T) {
 	var (
-		user = User{
-			ID: 10,
-			UserRoles: []UserRole{
-				{RoleID: 2},
+		todo = Todo{
+			ID:    1,
+			Title: "todo",
+			Tags: []Tag{
+				{ID: 2, Name: "home"},
 			},
 		}
 		adapter = &testAdapter{}
 		repo    = New(adapter)
+		cur     = &testCursor{}
+		err     = errors.New("error")
 	)
 
-	adapter.On("Update", From("users").Where(Eq("id", 10)), mock.Anything).Return(1, nil).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("todos").Where(Eq("id", 1)), mock.Anything).Return(1, nil).Once()
+	adapter.On("Query", From("todo_tags").Select("tag_id").Where(Eq("todo_id", 1))).Return(cur, nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
 
-	assert.Nil(t, repo.Update(context.TODO(), &user, Cascade(false)))
-	assert.Equal(t, User{
-		ID:        10,
-		CreatedAt: now(),
-		UpdatedAt: now(),
-		UserRoles: []UserRole{
-			{RoleID: 2},
-		},
-	}, user)
+	cur.On("Close").Return(nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.On("Scan", mock.Anything).Return(err).Once()
 
+	assert.Equal(t, err, repo.Update(context.TODO(), &todo))
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Update_saveHasManyError(t *testing.T) {
+func TestRepository_Update_saveManyToManyUnchanged(t *testing.T) {
 	var (
-		user = User{
-			ID: 10,
-			UserRoles: []UserRole{
-				{RoleID: 2},
+		todo = Todo{
+			ID:    1,
+			Title: "todo",
+			Tags: []Tag{
+				{ID: 2, Name: "home"},
+				{ID: 2, Name: "home"},
 			},
 		}
 		adapter = &testAdapter{}
 		repo    = New(adapter)
-		err     = errors.New("error")
 	)
 
 	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Update", From("users").Where(Eq("id", 10)), mock.Anything).Return(1, nil).Once()
-	adapter.On("Delete", From("user_roles").Where(Eq("user_id", 10))).Return(0, err).Once()
-	adapter.On("Rollback").Return(nil).Once()
+	adapter.On("Update", From("todos").Where(Eq("id", 1)), mock.Anything).Return(1, nil).Once()
+	cur := mockTodoTags(adapter, 2)
+	adapter.On("Commit").Return(nil).Once()
 
-	assert.Equal(t, err, repo.Update(context.TODO(), &user))
+	assert.Nil(t, repo.Update(context.TODO(), &todo))
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
 func TestRepository_Update_nothing(t *testing.T) {
@@ -1652,7 +3603,7 @@ func TestRepository_saveBelongsTo_insertNew(t *testing.T) {
 		q = Build("users")
 	)
 
-	adapter.On("Insert", q, mutates).Return(1, nil).Once()
+	adapter.On("Insert", q, mutates, OnConflict{}).Return(1, nil).Once()
 
 	assert.Nil(t, repo.(*repository).saveBelongsTo(cw, doc, &mutation))
 	assert.Equal(t, Set("user_id", 1), mutation.Mutates["user_id"])
@@ -1694,7 +3645,7 @@ func TestRepository_saveBelongsTo_insertNewError(t *testing.T) {
 		q = Build("users")
 	)
 
-	adapter.On("Insert", q, mutates).Return(0, errors.New("insert error")).Once()
+	adapter.On("Insert", q, mutates, OnConflict{}).Return(0, errors.New("insert error")).Once()
 
 	assert.Equal(t, errors.New("insert error"), repo.(*repository).saveBelongsTo(cw, doc, &mutation))
 	assert.Zero(t, mutation.Mutates["user_id"])
@@ -1820,7 +3771,7 @@ func TestRepository_saveHasOne_insertNew(t *testing.T) {
 		q = Build("addresses")
 	)
 
-	adapter.On("Insert", q, mutates).Return(2, nil).Once()
+	adapter.On("Insert", q, mutates, OnConflict{}).Return(2, nil).Once()
 
 	assert.Nil(t, repo.(*repository).saveHasOne(cw, doc, &mutation))
 	assert.Equal(t, User{
@@ -1856,7 +3807,7 @@ func TestRepository_saveHasOne_insertNewError(t *testing.T) {
 		q = Build("addresses")
 	)
 
-	adapter.On("Insert", q, mutates).Return(nil, errors.New("insert error")).Once()
+	adapter.On("Insert", q, mutates, OnConflict{}).Return(nil, errors.New("insert error")).Once()
 
 	assert.Equal(t, errors.New("insert error"), repo.(*repository).saveHasOne(cw, doc, &mutation))
 
@@ -1885,8 +3836,8 @@ func TestRepository_saveHasMany_insert(t *testing.T) {
 		q = Build("emails")
 	)
 
-	adapter.On("InsertAll", q, []string{"email", "user_id"}, mutates).Return([]interface{}{2, 3}, nil).Maybe()
-	adapter.On("InsertAll", q, []string{"user_id", "email"}, mutates).Return([]interface{}{2, 3}, nil).Maybe()
+	adapter.On("InsertAll", q, []string{"email", "user_id"}, mutates, OnConflict{}).Return([]interface{}{2, 3}, nil).Maybe()
+	adapter.On("InsertAll", q, []string{"user_id", "email"}, mutates, OnConflict{}).Return([]interface{}{2, 3}, nil).Maybe()
 
 	assert.Nil(t, repo.(*repository).saveHasMany(cw, doc, &mutation, true))
 	assert.Equal(t, User{
@@ -1923,8 +3874,8 @@ func TestRepository_saveHasMany_insertError(t *testing.T) {
 		err = errors.New("insert all error")
 	)
 
-	adapter.On("InsertAll", q, []string{"email", "user_id"}, mutates).Return([]interface{}{}, err).Maybe()
-	adapter.On("InsertAll", q, []string{"user_id", "email"}, mutates).Return([]interface{}{}, err).Maybe()
+	adapter.On("InsertAll", q, []string{"email", "user_id"}, mutates, OnConflict{}).Return([]interface{}{}, err).Maybe()
+	adapter.On("InsertAll", q, []string{"user_id", "email"}, mutates, OnConflict{}).Return([]interface{}{}, err).Maybe()
 
 	assert.Equal(t, err, repo.(*repository).saveHasMany(cw, doc, &mutation, true))
 
@@ -2073,8 +4024,8 @@ func TestRepository_saveHasMany_updateWithInsert(t *testing.T) {
 	)
 
 	adapter.On("Update", q.Where(Eq("id", 1).AndEq("user_id", 1)), mutates[0]).Return(1, nil).Once()
-	adapter.On("InsertAll", q, []string{"email", "user_id"}, mutates[1:]).Return([]interface{}{2}, nil).Maybe()
-	adapter.On("InsertAll", q, []string{"user_id", "email"}, mutates[1:]).Return([]interface{}{2}, nil).Maybe()
+	adapter.On("InsertAll", q, []string{"email", "user_id"}, mutates[1:], OnConflict{}).Return([]interface{}{2}, nil).Maybe()
+	adapter.On("InsertAll", q, []string{"user_id", "email"}, mutates[1:], OnConflict{}).Return([]interface{}{2}, nil).Maybe()
 
 	assert.Nil(t, repo.(*repository).saveHasMany(cw, doc, &mutation, false))
 	assert.Equal(t, User{
@@ -2117,8 +4068,8 @@ func TestRepository_saveHasMany_updateWithReorderInsert(t *testing.T) {
 	mutation.SetDeletedIDs("emails", []interface{}{})
 
 	adapter.On("Update", q.Where(Eq("id", 1).AndEq("user_id", 1)), mutates[0]).Return(1, nil).Once()
-	adapter.On("InsertAll", q, []string{"email", "user_id"}, mutates[1:]).Return([]interface{}{2}, nil).Maybe()
-	adapter.On("InsertAll", q, []string{"user_id", "email"}, mutates[1:]).Return([]interface{}{2}, nil).Maybe()
+	adapter.On("InsertAll", q, []string{"email", "user_id"}, mutates[1:], OnConflict{}).Return([]interface{}{2}, nil).Maybe()
+	adapter.On("InsertAll", q, []string{"user_id", "email"}, mutates[1:], OnConflict{}).Return([]interface{}{2}, nil).Maybe()
 
 	assert.Nil(t, repo.(*repository).saveHasMany(cw, doc, &mutation, false))
 	assert.Equal(t, User{
@@ -2163,8 +4114,8 @@ func TestRepository_saveHasMany_deleteWithInsert(t *testing.T) {
 	)
 
 	adapter.On("Delete", q.Where(Eq("user_id", 1).AndIn("id", 1, 2))).Return(1, nil).Once()
-	adapter.On("InsertAll", q, []string{"email", "user_id"}, mutates).Return([]interface{}{3, 4, 5}, nil).Maybe()
-	adapter.On("InsertAll", q, []string{"user_id", "email"}, mutates).Return([]interface{}{3, 4, 5}, nil).Maybe()
+	adapter.On("InsertAll", q, []string{"email", "user_id"}, mutates, OnConflict{}).Return([]interface{}{3, 4, 5}, nil).Maybe()
+	adapter.On("InsertAll", q, []string{"user_id", "email"}, mutates, OnConflict{}).Return([]interface{}{3, 4, 5}, nil).Maybe()
 
 	assert.Nil(t, repo.(*repository).saveHasMany(cw, doc, &mutation, false))
 	assert.Equal(t, User{
@@ -2236,7 +4187,7 @@ func TestRepository_saveHasMany_replace(t *testing.T) {
 	)
 
 	adapter.On("Delete", q.Where(Eq("user_id", 1))).Return(1, nil).Once()
-	adapter.On("InsertAll", q, mock.Anything, mutates).Return([]interface{}{3, 4, 5}, nil).Once()
+	adapter.On("InsertAll", q, mock.Anything, mutates, OnConflict{}).Return([]interface{}{3, 4, 5}, nil).Once()
 
 	assert.Nil(t, repo.(*repository).saveHasMany(cw, doc, &mutation, false))
 	assert.Equal(t, User{
@@ -2301,6 +4252,130 @@ func TestRepository_saveHasMany_invalidMutator(t *testing.T) {
 	adapter.AssertExpectations(t)
 }
 
+func TestRepository_saveManyToMany_update(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		cw      = fetchContext(context.TODO(), adapter)
+		repo    = New(adapter)
+		todo    = Todo{
+			ID: 1,
+			Tags: []Tag{
+				{ID: 2, Name: "home"},
+			},
+		}
+		doc      = NewDocument(&todo)
+		mutation = Mutation{}
+		mutates  = []map[string]Mutate{
+			{"todo_id": Set("todo_id", 1), "tag_id": Set("tag_id", 2)},
+		}
+	)
+
+	mutation.SetAssoc("tags", Apply(NewDocument(&todo.Tags[0]), Set("name", "house")))
+	mutation.SetDeletedIDs("tags", []interface{}{3})
+	mutation.SetTracked("tags")
+
+	adapter.On("Update", From("tags").Where(Eq("id", 2)), map[string]Mutate{"name": Set("name", "house")}).Return(1, nil).Once()
+	mockTodoTags(adapter, 3)
+	adapter.On("Delete", From("todo_tags").Where(Eq("todo_id", 1).AndIn("tag_id", 3))).Return(1, nil).Once()
+	adapter.On("InsertAll", From("todo_tags"), []string{"todo_id", "tag_id"}, mutates, OnConflict{}).Return([]interface{}(nil), nil).Once()
+
+	assert.Nil(t, repo.(*repository).saveHasMany(cw, doc, &mutation, false))
+	assert.Equal(t, []Tag{{ID: 2, Name: "house"}}, todo.Tags)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveManyToMany_updateError(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		cw      = fetchContext(context.TODO(), adapter)
+		repo    = New(adapter)
+		todo    = Todo{
+			ID: 1,
+			Tags: []Tag{
+				{ID: 2, Name: "home"},
+			},
+		}
+		doc      = NewDocument(&todo)
+		mutation = Mutation{}
+		err      = errors.New("error")
+	)
+
+	mutation.SetAssoc("tags", Apply(NewDocument(&todo.Tags[0]), Set("name", "house")))
+	mutation.SetDeletedIDs("tags", []interface{}{})
+	mutation.SetTracked("tags")
+
+	adapter.On("Update", From("tags").Where(Eq("id", 2)), mock.Anything).Return(0, err).Once()
+
+	assert.Equal(t, err, repo.(*repository).saveHasMany(cw, doc, &mutation, false))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveManyToMany_clear(t *testing.T) {
+	var (
+		adapter  = &testAdapter{}
+		cw       = fetchContext(context.TODO(), adapter)
+		repo     = New(adapter)
+		todo     = Todo{ID: 1, Tags: []Tag{}}
+		doc      = NewDocument(&todo)
+		mutation = Mutation{}
+	)
+
+	mutation.SetAssoc("tags")
+	mutation.SetDeletedIDs("tags", []interface{}{2})
+	mutation.SetTracked("tags")
+
+	mockTodoTags(adapter, 2)
+	adapter.On("Delete", From("todo_tags").Where(Eq("todo_id", 1).AndIn("tag_id", 2))).Return(1, nil).Once()
+
+	assert.Nil(t, repo.(*repository).saveHasMany(cw, doc, &mutation, false))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveManyToMany_insertAllError(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		cw      = fetchContext(context.TODO(), adapter)
+		repo    = New(adapter)
+		todo    = Todo{
+			ID: 1,
+			Tags: []Tag{
+				{ID: 2, Name: "home"},
+			},
+		}
+		doc      = NewDocument(&todo)
+		mutation = Apply(doc, NewStructset(doc, false))
+		err      = errors.New("error")
+	)
+
+	adapter.On("InsertAll", From("todo_tags"), []string{"todo_id", "tag_id"}, mock.Anything, OnConflict{}).Return([]interface{}(nil), err).Once()
+
+	assert.Equal(t, err, repo.(*repository).saveHasMany(cw, doc, &mutation, true))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveManyToMany_invalidMutator(t *testing.T) {
+	var (
+		adapter  = &testAdapter{}
+		cw       = fetchContext(context.TODO(), adapter)
+		repo     = New(adapter)
+		todo     = Todo{ID: 1, Tags: []Tag{{ID: 2}}}
+		doc      = NewDocument(&todo)
+		mutation = Mutation{}
+	)
+
+	mutation.SetAssoc("tags")
+
+	assert.PanicsWithValue(t, "rel: invalid mutator", func() {
+		repo.(*repository).saveHasMany(cw, doc, &mutation, false)
+	})
+
+	adapter.AssertExpectations(t)
+}
+
 func TestRepository_UpdateAll(t *testing.T) {
 	var (
 		adapter = &testAdapter{}
@@ -2397,6 +4472,55 @@ func TestRepository_Delete_softDelete(t *testing.T) {
 	adapter.AssertExpectations(t)
 }
 
+func TestRepository_Delete_lockVersion(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ticket  = Ticket{ID: 1, LockVersion: 2}
+	)
+
+	adapter.On("Delete", From("tickets").Where(Eq("id", ticket.ID)).Where(Eq("lock_version", 2))).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Delete(context.TODO(), &ticket))
+	assert.Equal(t, 2, ticket.LockVersion)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Delete_lockVersionStale(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ticket  = Ticket{ID: 1, LockVersion: 2}
+	)
+
+	adapter.On("Delete", From("tickets").Where(Eq("id", ticket.ID)).Where(Eq("lock_version", 2))).Return(0, nil).Once()
+
+	assert.Equal(t, StaleObjectError{}, repo.Delete(context.TODO(), &ticket))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Delete_softDeleteLockVersion(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		post    = Post{ID: 1, LockVersion: 2}
+		query   = From("posts").Where(Eq("id", post.ID)).Where(Eq("lock_version", 2))
+		mutates = map[string]Mutate{
+			"deleted_at":   Set("deleted_at", now()),
+			"lock_version": Inc("lock_version"),
+		}
+	)
+
+	adapter.On("Update", query, mutates).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Delete(context.TODO(), &post))
+	assert.Equal(t, uint(3), post.LockVersion)
+
+	adapter.AssertExpectations(t)
+}
+
 func TestRepository_Delete_belongsTo(t *testing.T) {
 	var (
 		userID  = 1
@@ -2531,40 +4655,106 @@ func TestRepository_Delete_hasOneInconsistentAssoc(t *testing.T) {
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Delete_hasOneError(t *testing.T) {
+func TestRepository_Delete_hasOneError(t *testing.T) {
+	var (
+		userID = 10
+		user   = User{
+			ID: userID,
+			Address: Address{
+				ID:     1,
+				Street: "street",
+				UserID: &userID,
+			},
+		}
+		addressMut = map[string]Mutate{
+			"deleted_at": Set("deleted_at", now()),
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		err     = errors.New("error")
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("addresses").Where(Eq("id", 1).AndEq("user_id", 10)), addressMut).Return(1, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Delete(context.TODO(), &user, Cascade(true)))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Delete_hasMany(t *testing.T) {
+	var (
+		user = User{
+			ID: 10,
+			UserRoles: []UserRole{
+				{UserID: 10, RoleID: 1},
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Delete", From("user_roles").Where(Eq("user_id", 10).And(Or(Eq("user_id", 10).AndEq("role_id", 1))))).Return(1, nil).Once()
+	adapter.On("Delete", From("users").Where(Eq("id", 10))).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Delete(context.TODO(), &user, Cascade(true)))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Delete_hasManyError(t *testing.T) {
+	var (
+		user = User{
+			ID: 10,
+			UserRoles: []UserRole{
+				{UserID: 10, RoleID: 1},
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		err     = errors.New("err")
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Delete", From("user_roles").Where(Eq("user_id", 10).And(Or(Eq("user_id", 10).AndEq("role_id", 1))))).Return(1, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Delete(context.TODO(), &user, Cascade(true)))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Delete_polymorphicHasMany(t *testing.T) {
 	var (
-		userID = 10
-		user   = User{
-			ID: userID,
-			Address: Address{
-				ID:     1,
-				Street: "street",
-				UserID: &userID,
+		video = Video{
+			ID: 1,
+			Comments: []Comment{
+				{ID: 2, OwnerType: "videos", OwnerID: 1},
 			},
 		}
-		addressMut = map[string]Mutate{
-			"deleted_at": Set("deleted_at", now()),
-		}
 		adapter = &testAdapter{}
 		repo    = New(adapter)
-		err     = errors.New("error")
 	)
 
 	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Update", From("addresses").Where(Eq("id", 1).AndEq("user_id", 10)), addressMut).Return(1, err).Once()
-	adapter.On("Rollback").Return(nil).Once()
+	adapter.On("Delete", From("comments").Where(Eq("owner_id", 1).AndEq("owner_type", "videos").And(In("id", 2)))).Return(1, nil).Once()
+	adapter.On("Delete", From("videos").Where(Eq("id", 1))).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
 
-	assert.Equal(t, err, repo.Delete(context.TODO(), &user, Cascade(true)))
+	assert.Nil(t, repo.Delete(context.TODO(), &video, Cascade(true)))
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Delete_hasMany(t *testing.T) {
+func TestRepository_Delete_manyToMany(t *testing.T) {
 	var (
-		user = User{
-			ID: 10,
-			UserRoles: []UserRole{
-				{UserID: 10, RoleID: 1},
+		todo = Todo{
+			ID: 1,
+			Tags: []Tag{
+				{ID: 2, Name: "home"},
 			},
 		}
 		adapter = &testAdapter{}
@@ -2572,33 +4762,28 @@ func TestRepository_Delete_hasMany(t *testing.T) {
 	)
 
 	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Delete", From("user_roles").Where(Eq("user_id", 10).And(Or(Eq("user_id", 10).AndEq("role_id", 1))))).Return(1, nil).Once()
-	adapter.On("Delete", From("users").Where(Eq("id", 10))).Return(1, nil).Once()
+	adapter.On("Delete", From("todo_tags").Where(Eq("todo_id", 1))).Return(1, nil).Once()
+	adapter.On("Delete", From("todos").Where(Eq("id", 1))).Return(1, nil).Once()
 	adapter.On("Commit").Return(nil).Once()
 
-	assert.Nil(t, repo.Delete(context.TODO(), &user, Cascade(true)))
+	assert.Nil(t, repo.Delete(context.TODO(), &todo, Cascade(true)))
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Delete_hasManyError(t *testing.T) {
+func TestRepository_Delete_manyToManyError(t *testing.T) {
 	var (
-		user = User{
-			ID: 10,
-			UserRoles: []UserRole{
-				{UserID: 10, RoleID: 1},
-			},
-		}
+		todo    = Todo{ID: 1}
 		adapter = &testAdapter{}
 		repo    = New(adapter)
 		err     = errors.New("err")
 	)
 
 	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Delete", From("user_roles").Where(Eq("user_id", 10).And(Or(Eq("user_id", 10).AndEq("role_id", 1))))).Return(1, err).Once()
+	adapter.On("Delete", From("todo_tags").Where(Eq("todo_id", 1))).Return(0, err).Once()
 	adapter.On("Rollback").Return(nil).Once()
 
-	assert.Equal(t, err, repo.Delete(context.TODO(), &user, Cascade(true)))
+	assert.Equal(t, err, repo.Delete(context.TODO(), &todo, Cascade(true)))
 
 	adapter.AssertExpectations(t)
 }
@@ -3101,6 +5286,153 @@ func TestRepository_Preload_sliceNestedBelongsTo(t *testing.T) {
 	cur.AssertExpectations(t)
 }
 
+func TestRepository_Preload_polymorphicHasMany(t *testing.T) {
+	var (
+		adapter  = &testAdapter{}
+		repo     = New(adapter)
+		video    = Video{ID: 1}
+		comments = []Comment{
+			{ID: 2, Body: "comment", OwnerType: "videos", OwnerID: 1},
+		}
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("comments").Where(In("owner_id", 1).AndEq("owner_type", "videos"))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "body", "owner_type", "owner_id"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(2, "comment", "videos", 1).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &video, "comments"))
+	assert.Equal(t, comments, video.Comments)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_polymorphicBelongsTo(t *testing.T) {
+	var (
+		adapter  = &testAdapter{}
+		repo     = New(adapter)
+		comments = []Comment{
+			{ID: 1, OwnerType: "videos", OwnerID: 1},
+			{ID: 2, OwnerType: "photos", OwnerID: 2},
+		}
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("videos").Where(In("id", 1))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "title"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(1, "video").Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &comments, "video"))
+	assert.Equal(t, &Video{ID: 1, Title: "video"}, comments[0].Video)
+	assert.Nil(t, comments[1].Video)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_manyToMany(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		todos   = []Todo{{ID: 1}, {ID: 2}}
+		tags    = []Tag{
+			{ID: 3, Name: "work"},
+			{ID: 4, Name: "home"},
+		}
+		joinCur = &testCursor{}
+		cur     = &testCursor{}
+	)
+
+	adapter.On("Query", From("todo_tags").Select("todo_id", "tag_id").Where(In("todo_id", 1, 2))).Return(joinCur, nil).Maybe()
+	adapter.On("Query", From("todo_tags").Select("todo_id", "tag_id").Where(In("todo_id", 2, 1))).Return(joinCur, nil).Maybe()
+
+	joinCur.On("Close").Return(nil).Once()
+	joinCur.On("Next").Return(true).Times(3)
+	joinCur.MockScan(1, 3).Once()
+	joinCur.MockScan(1, 4).Once()
+	joinCur.MockScan(2, 3).Once()
+	joinCur.On("Next").Return(false).Once()
+
+	adapter.On("Query", From("tags").Where(In("id", 3, 4))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("tags").Where(In("id", 4, 3))).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(tags[0].ID, tags[0].Name).Times(3)
+	cur.MockScan(tags[1].ID, tags[1].Name).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &todos, "tags"))
+	assert.Equal(t, tags, todos[0].Tags)
+	assert.Equal(t, tags[:1], todos[1].Tags)
+
+	adapter.AssertExpectations(t)
+	joinCur.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_manyToManyEmpty(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		todo    = Todo{ID: 1}
+		joinCur = createCursor(0)
+	)
+
+	adapter.On("Query", From("todo_tags").Select("todo_id", "tag_id").Where(In("todo_id", 1))).Return(joinCur, nil).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &todo, "tags"))
+	assert.Equal(t, []Tag{}, todo.Tags)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Preload_manyToManyQueryError(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		todo    = Todo{ID: 1}
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", From("todo_tags").Select("todo_id", "tag_id").Where(In("todo_id", 1))).Return(&testCursor{}, err).Once()
+
+	assert.Equal(t, err, repo.Preload(context.TODO(), &todo, "tags"))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Preload_manyToManyScanError(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		todo    = Todo{ID: 1}
+		joinCur = &testCursor{}
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", From("todo_tags").Select("todo_id", "tag_id").Where(In("todo_id", 1))).Return(joinCur, nil).Once()
+
+	joinCur.On("Close").Return(nil).Once()
+	joinCur.On("Next").Return(true).Once()
+	joinCur.On("Scan", mock.Anything, mock.Anything).Return(err).Once()
+
+	assert.Equal(t, err, repo.Preload(context.TODO(), &todo, "tags"))
+
+	adapter.AssertExpectations(t)
+	joinCur.AssertExpectations(t)
+}
+
 func TestRepository_Preload_alreadyLoaded(t *testing.T) {
 	var (
 		adapter = &testAdapter{}
@@ -3195,6 +5527,101 @@ func TestRepository_MustPreload(t *testing.T) {
 	cur.AssertExpectations(t)
 }
 
+func TestRepository_PreloadTree(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		userID  = 10
+		user    = User{ID: 10}
+		trxCur  = &testCursor{}
+		addrCur = &testCursor{}
+		histCur = &testCursor{}
+	)
+
+	adapter.On("Query", From("transactions").Where(Eq("status", "paid"), In("user_id", 10))).Return(trxCur, nil).Once()
+	adapter.On("Query", From("addresses").Where(In("user_id", 10), Nil("deleted_at"))).Return(addrCur, nil).Once()
+	adapter.On("Query", From("histories").Where(In("transaction_id", 5))).Return(histCur, nil).Once()
+
+	trxCur.On("Close").Return(nil).Once()
+	trxCur.On("Fields").Return([]string{"id", "user_id", "status"}, nil).Once()
+	trxCur.On("Next").Return(true).Once()
+	trxCur.MockScan(5, 10, "paid").Twice()
+	trxCur.On("Next").Return(false).Once()
+
+	addrCur.On("Close").Return(nil).Once()
+	addrCur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	addrCur.On("Next").Return(true).Once()
+	addrCur.MockScan(1, 10).Twice()
+	addrCur.On("Next").Return(false).Once()
+
+	histCur.On("Close").Return(nil).Once()
+	histCur.On("Fields").Return([]string{"id", "transaction_id"}, nil).Once()
+	histCur.On("Next").Return(true).Once()
+	histCur.MockScan(1, 5).Twice()
+	histCur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.PreloadTree(context.TODO(), &user,
+		NewPreload("transactions", Eq("status", "paid")).With(
+			NewPreload("histories"),
+		),
+		NewPreload("address"),
+	))
+	assert.Equal(t, []Transaction{
+		{ID: 5, BuyerID: 10, Status: "paid", Histories: &[]History{{ID: 1, TransactionID: 5}}},
+	}, user.Transactions)
+	assert.Equal(t, Address{ID: 1, UserID: &userID}, user.Address)
+
+	adapter.AssertExpectations(t)
+	trxCur.AssertExpectations(t)
+	addrCur.AssertExpectations(t)
+	histCur.AssertExpectations(t)
+}
+
+func TestRepository_PreloadTree_error(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		user    = User{ID: 10}
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10))).Return(&testCursor{}, err).Once()
+
+	assert.Equal(t, err, repo.PreloadTree(context.TODO(), &user,
+		NewPreload("transactions").With(
+			NewPreload("histories"),
+		),
+	))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_MustPreloadTree(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		user    = User{ID: 10}
+		cur     = &testCursor{}
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(false).Once()
+
+	assert.NotPanics(t, func() {
+		repo.MustPreloadTree(context.TODO(), &user,
+			NewPreload("transactions").With(
+				NewPreload("histories"),
+			),
+		)
+	})
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
 func TestRepository_Transaction(t *testing.T) {
 	adapter := &testAdapter{}
 	adapter.On("Begin").Return(nil).On("Commit").Return(nil).Once()
@@ -3295,3 +5722,149 @@ func TestRepository_Transaction_runtimeError(t *testing.T) {
 
 	adapter.AssertExpectations(t)
 }
+
+func TestRepository_Transaction_retry(t *testing.T) {
+	var (
+		adapter  = &testAdapter{}
+		conflict = TransactionConflictError{Type: SerializationFailure, Err: errors.New("could not serialize access")}
+		attempts = 0
+	)
+
+	adapter.On("Begin").Return(nil).Times(3)
+	adapter.On("Rollback").Return(nil).Once()
+	adapter.On("Commit").Return(TransactionConflictError{Type: Deadlock}).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	err := New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
+		attempts++
+		if attempts == 1 {
+			return conflict
+		}
+
+		return nil
+	}, Retry{MaxAttempts: 3, Backoff: ConstantBackoff(time.Millisecond)})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_retryPanic(t *testing.T) {
+	var (
+		adapter  = &testAdapter{}
+		attempts = 0
+	)
+
+	adapter.On("Begin").Return(nil).Twice()
+	adapter.On("Rollback").Return(nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	err := New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
+		attempts++
+		if attempts == 1 {
+			panic(TransactionConflictError{Type: Deadlock})
+		}
+
+		return nil
+	}, Retry{MaxAttempts: 2})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, attempts)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_retryMaxAttempts(t *testing.T) {
+	var (
+		adapter  = &testAdapter{}
+		conflict = TransactionConflictError{Type: Deadlock}
+		attempts = 0
+	)
+
+	adapter.On("Begin").Return(nil).Times(3)
+	adapter.On("Rollback").Return(nil).Times(3)
+
+	err := New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
+		attempts++
+		return conflict
+	}, Retry{MaxAttempts: 3})
+
+	assert.Equal(t, conflict, err)
+	assert.Equal(t, 3, attempts)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_retryOtherError(t *testing.T) {
+	var (
+		adapter  = &testAdapter{}
+		attempts = 0
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	err := New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
+		attempts++
+		return errors.New("error")
+	}, Retry{MaxAttempts: 3})
+
+	assert.Equal(t, errors.New("error"), err)
+	assert.Equal(t, 1, attempts)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_retryNested(t *testing.T) {
+	var (
+		adapter  = &testAdapter{}
+		repo     = New(adapter)
+		attempts = 0
+	)
+
+	adapter.On("Begin").Return(nil).Times(4)
+	adapter.On("Rollback").Return(nil).Twice()
+	adapter.On("Commit").Return(nil).Twice()
+
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		// conflict is returned to the outermost transaction.
+		return repo.Transaction(ctx, func(ctx context.Context) error {
+			attempts++
+			if attempts == 1 {
+				return TransactionConflictError{Type: SerializationFailure}
+			}
+
+			return nil
+		}, Retry{MaxAttempts: 3})
+	}, Retry{MaxAttempts: 2})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, attempts)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_retryContextDone(t *testing.T) {
+	var (
+		adapter     = &testAdapter{}
+		conflict    = TransactionConflictError{Type: Deadlock}
+		ctx, cancel = context.WithCancel(context.TODO())
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	err := New(adapter).Transaction(ctx, func(ctx context.Context) error {
+		cancel()
+		return conflict
+	}, Retry{MaxAttempts: 3, Backoff: ConstantBackoff(time.Hour)})
+
+	assert.Equal(t, conflict, err)
+	adapter.AssertExpectations(t)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	err = New(adapter).Transaction(ctx, func(ctx context.Context) error {
+		return conflict
+	}, Retry{MaxAttempts: 3})
+
+	assert.Equal(t, conflict, err)
+	adapter.AssertExpectations(t)
+}
@@ -0,0 +1,150 @@
+package rel
+
+import (
+	"sync"
+)
+
+// SoftDeleteScope is the name of built-in default scope that filters soft deleted records.
+// Use Unscope(SoftDeleteScope) to include soft deleted records while keeping the other default scopes.
+const SoftDeleteScope = "soft_delete"
+
+var (
+	scopesMutex sync.RWMutex
+	scopes      = map[string][]scope{}
+)
+
+// ScopeFunc modifies query of a model, eg: to only find published records.
+type ScopeFunc func(query Query) Query
+
+type scope struct {
+	name      string
+	fn        ScopeFunc
+	isDefault bool
+}
+
+// RegisterScope registers named scope for model that has the same table as sample.
+// Named scope is only applied when it's requested using Scope querier.
+//
+//	rel.RegisterScope(&Book{}, "published", func(query rel.Query) rel.Query {
+//		return query.Where(where.Eq("published", true))
+//	})
+func RegisterScope(sample interface{}, name string, fn ScopeFunc) {
+	registerScope(NewDocument(sample).Table(), scope{name: name, fn: fn})
+}
+
+// RegisterDefaultScope registers named scope that's applied to every query of model that has the same table as sample.
+// Default scope can be disabled by name using Unscope querier, or disabled entirely using Unscoped querier.
+func RegisterDefaultScope(sample interface{}, name string, fn ScopeFunc) {
+	registerScope(NewDocument(sample).Table(), scope{name: name, fn: fn, isDefault: true})
+}
+
+func registerScope(table string, s scope) {
+	scopesMutex.Lock()
+	defer scopesMutex.Unlock()
+
+	var (
+		registered []scope
+	)
+
+	// copy to avoid modifying scopes that are being applied.
+	for _, rs := range scopes[table] {
+		if rs.name != s.name {
+			registered = append(registered, rs)
+		}
+	}
+
+	if s.fn != nil {
+		registered = append(registered, s)
+	}
+
+	scopes[table] = registered
+}
+
+func tableScopes(table string) []scope {
+	scopesMutex.RLock()
+	defer scopesMutex.RUnlock()
+
+	return scopes[table]
+}
+
+// applyScopes applies default scopes and named scopes requested by the query.
+func applyScopes(query Query) Query {
+	var (
+		registered = tableScopes(query.Table)
+		names      = query.ScopeQuery
+	)
+
+	if len(registered) == 0 && len(names) == 0 {
+		return query
+	}
+
+	query.ScopeQuery = nil
+
+	if !query.UnscopedQuery {
+		for _, s := range registered {
+			if s.isDefault && !query.UnscopeQuery.has(s.name) {
+				query = s.fn(query)
+			}
+		}
+	}
+
+	for _, name := range names {
+		var (
+			found bool
+		)
+
+		for _, s := range registered {
+			if s.name == name {
+				found = true
+				// default scope is already applied unless it's disabled.
+				if !s.isDefault || bool(query.UnscopedQuery) || query.UnscopeQuery.has(name) {
+					query = s.fn(query)
+				}
+
+				break
+			}
+		}
+
+		if !found {
+			panic("rel: scope (" + name + ") is not registered for " + query.Table)
+		}
+	}
+
+	return query
+}
+
+// ScopeQuery applies named scopes registered for the model.
+type ScopeQuery []string
+
+// Build query.
+func (sq ScopeQuery) Build(query *Query) {
+	query.ScopeQuery = append(query.ScopeQuery, sq...)
+}
+
+// Scope applies named scopes registered for the model.
+func Scope(names ...string) ScopeQuery {
+	return ScopeQuery(names)
+}
+
+// UnscopeQuery disables default scopes by name.
+type UnscopeQuery []string
+
+// Build query.
+func (uq UnscopeQuery) Build(query *Query) {
+	query.UnscopeQuery = append(query.UnscopeQuery, uq...)
+}
+
+func (uq UnscopeQuery) has(name string) bool {
+	for i := range uq {
+		if uq[i] == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Unscope disables default scopes by name, use Unscoped to disable all default scopes.
+func Unscope(names ...string) UnscopeQuery {
+	return UnscopeQuery(names)
+}
@@ -0,0 +1,236 @@
+package rel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func registerPostScopes() func() {
+	RegisterDefaultScope(&Post{}, "visible", func(query Query) Query {
+		return query.Where(Eq("visible", true))
+	})
+
+	RegisterScope(&Post{}, "titled", func(query Query) Query {
+		return query.Where(Ne("title", ""))
+	})
+
+	return func() {
+		RegisterDefaultScope(&Post{}, "visible", nil)
+		RegisterScope(&Post{}, "titled", nil)
+	}
+}
+
+func TestApplyScopes(t *testing.T) {
+	defer registerPostScopes()()
+
+	tests := []struct {
+		name   string
+		query  Query
+		result Query
+	}{
+		{
+			name:   "default",
+			query:  From("posts"),
+			result: From("posts").Where(Eq("visible", true)),
+		},
+		{
+			name:   "named",
+			query:  From("posts").Scope("titled"),
+			result: From("posts").Where(Eq("visible", true), Ne("title", "")),
+		},
+		{
+			name:   "named default",
+			query:  From("posts").Scope("visible"),
+			result: From("posts").Where(Eq("visible", true)),
+		},
+		{
+			name:   "unscope",
+			query:  From("posts").Unscope("visible"),
+			result: From("posts").Unscope("visible"),
+		},
+		{
+			name:   "unscope and named default",
+			query:  From("posts").Unscope("visible").Scope("visible"),
+			result: From("posts").Unscope("visible").Where(Eq("visible", true)),
+		},
+		{
+			name:   "unscoped",
+			query:  From("posts").Unscoped().Scope("titled"),
+			result: From("posts").Unscoped().Where(Ne("title", "")),
+		},
+		{
+			name:   "no scopes",
+			query:  From("users"),
+			result: From("users"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.result, applyScopes(test.query))
+		})
+	}
+}
+
+func TestApplyScopes_notRegistered(t *testing.T) {
+	assert.PanicsWithValue(t, "rel: scope (titled) is not registered for users", func() {
+		applyScopes(From("users").Scope("titled"))
+	})
+}
+
+func TestRegisterScope_replace(t *testing.T) {
+	defer registerPostScopes()()
+
+	RegisterDefaultScope(&Post{}, "visible", func(query Query) Query {
+		return query.Where(Eq("visible", false))
+	})
+
+	assert.Equal(t, From("posts").Where(Eq("visible", false)), applyScopes(From("posts")))
+}
+
+func TestScope(t *testing.T) {
+	assert.Equal(t, Query{
+		Table:        "posts",
+		ScopeQuery:   ScopeQuery{"published", "titled"},
+		UnscopeQuery: UnscopeQuery{"visible"},
+	}, Build("posts", Scope("published"), Unscope("visible"), Scope("titled")))
+}
+
+func TestRepository_Find_scope(t *testing.T) {
+	defer registerPostScopes()()
+
+	var (
+		post    Post
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		cur     = createCursor(1)
+	)
+
+	adapter.On("Query", From("posts").Where(Nil("deleted_at"), Eq("visible", true), Ne("title", "")).Limit(1)).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Find(context.TODO(), &post, Scope("titled")))
+	assert.Equal(t, 10, post.ID)
+	assert.False(t, cur.Next())
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_FindAll_unscopeSoftDelete(t *testing.T) {
+	defer registerPostScopes()()
+
+	var (
+		posts   []Post
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		cur     = createCursor(2)
+	)
+
+	adapter.On("Query", From("posts").Unscope(SoftDeleteScope).Where(Eq("visible", true))).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.FindAll(context.TODO(), &posts, Unscope(SoftDeleteScope)))
+	assert.Len(t, posts, 2)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_FindAndCountAll_scope(t *testing.T) {
+	defer registerPostScopes()()
+
+	var (
+		posts   []Post
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("posts").Where(Nil("deleted_at"), Eq("visible", true))
+		cur     = createCursor(2)
+	)
+
+	adapter.On("Query", query.Limit(10)).Return(cur, nil).Once()
+	adapter.On("Aggregate", query, "count", "*").Return(2, nil).Once()
+
+	count, err := repo.FindAndCountAll(context.TODO(), &posts, Limit(10))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Count_scope(t *testing.T) {
+	defer registerPostScopes()()
+
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Aggregate", From("posts").Where(Eq("visible", true), Ne("title", "")), "count", "*").Return(3, nil).Once()
+
+	count, err := repo.Count(context.TODO(), "posts", Scope("titled"))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_UpdateAll_scope(t *testing.T) {
+	defer registerPostScopes()()
+
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		mutates = map[string]Mutate{"title": Set("title", "rel")}
+	)
+
+	adapter.On("Update", From("posts").Where(Eq("id", 1), Eq("visible", true)), mutates).Return(1, nil).Once()
+
+	assert.Nil(t, repo.UpdateAll(context.TODO(), From("posts").Where(Eq("id", 1)), Set("title", "rel")))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_DeleteAll_scope(t *testing.T) {
+	defer registerPostScopes()()
+
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Delete", From("posts").Unscoped()).Return(1, nil).Once()
+
+	assert.Nil(t, repo.DeleteAll(context.TODO(), From("posts").Unscoped()))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Preload_scope(t *testing.T) {
+	RegisterDefaultScope(&Transaction{}, "paid", func(query Query) Query {
+		return query.Where(Eq("status", "paid"))
+	})
+	defer RegisterDefaultScope(&Transaction{}, "paid", nil)
+
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		user    = User{ID: 10}
+		cur     = &testCursor{}
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10), Eq("status", "paid"))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(5, 10).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &user, "transactions"))
+	assert.Equal(t, []Transaction{{ID: 5, BuyerID: 10}}, user.Transactions)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
@@ -0,0 +1,70 @@
+package rel
+
+// SelectExpr defines a computed expression in select clause, such as aggregate or window function.
+type SelectExpr struct {
+	Func   string
+	Fields []string
+	Window *WindowQuery
+	Alias  string
+}
+
+// As sets alias of the expression, the alias is used as column name when scanning the result.
+func (se SelectExpr) As(alias string) SelectExpr {
+	se.Alias = alias
+	return se
+}
+
+// Over turns the expression into window function evaluated over the given window.
+func (se SelectExpr) Over(window WindowQuery) SelectExpr {
+	se.Window = &window
+	return se
+}
+
+// NewSelectExpr defines select expression calling function with given fields as arguments.
+// Fields are escaped, prefix it with ^ to pass a raw argument.
+func NewSelectExpr(fn string, fields ...string) SelectExpr {
+	return SelectExpr{
+		Func:   fn,
+		Fields: fields,
+	}
+}
+
+// RowNumber returns sequential number of the row within its window partition.
+func RowNumber() SelectExpr {
+	return NewSelectExpr("ROW_NUMBER")
+}
+
+// Rank returns rank of the row within its window partition, with gaps.
+func Rank() SelectExpr {
+	return NewSelectExpr("RANK")
+}
+
+// DenseRank returns rank of the row within its window partition, without gaps.
+func DenseRank() SelectExpr {
+	return NewSelectExpr("DENSE_RANK")
+}
+
+// Count returns number of rows where the field is not null.
+func Count(field string) SelectExpr {
+	return NewSelectExpr("COUNT", field)
+}
+
+// Sum returns sum of the field.
+func Sum(field string) SelectExpr {
+	return NewSelectExpr("SUM", field)
+}
+
+// Avg returns average of the field.
+func Avg(field string) SelectExpr {
+	return NewSelectExpr("AVG", field)
+}
+
+// Min returns minimum value of the field.
+func Min(field string) SelectExpr {
+	return NewSelectExpr("MIN", field)
+}
+
+// Max returns maximum value of the field.
+func Max(field string) SelectExpr {
+	return NewSelectExpr("MAX", field)
+}
@@ -0,0 +1,57 @@
+package rel_test
+
+import (
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSelectExpr(t *testing.T) {
+	assert.Equal(t, rel.SelectExpr{
+		Func:   "LAG",
+		Fields: []string{"score", "^1"},
+	}, rel.NewSelectExpr("LAG", "score", "^1"))
+}
+
+func TestSelectExpr_As(t *testing.T) {
+	assert.Equal(t, rel.SelectExpr{
+		Func:  "ROW_NUMBER",
+		Alias: "position",
+	}, rel.RowNumber().As("position"))
+}
+
+func TestSelectExpr_Over(t *testing.T) {
+	var (
+		window = rel.Window("game_id").SortDesc("score")
+	)
+
+	assert.Equal(t, rel.SelectExpr{
+		Func:   "RANK",
+		Window: &window,
+		Alias:  "rank",
+	}, rel.Rank().Over(window).As("rank"))
+}
+
+func TestSelectExpr_functions(t *testing.T) {
+	tests := []struct {
+		expr rel.SelectExpr
+		fn   string
+		args []string
+	}{
+		{rel.RowNumber(), "ROW_NUMBER", nil},
+		{rel.Rank(), "RANK", nil},
+		{rel.DenseRank(), "DENSE_RANK", nil},
+		{rel.Count("*"), "COUNT", []string{"*"}},
+		{rel.Sum("score"), "SUM", []string{"score"}},
+		{rel.Avg("score"), "AVG", []string{"score"}},
+		{rel.Min("score"), "MIN", []string{"score"}},
+		{rel.Max("score"), "MAX", []string{"score"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.fn, func(t *testing.T) {
+			assert.Equal(t, rel.NewSelectExpr(test.fn, test.args...), test.expr)
+		})
+	}
+}
@@ -4,6 +4,7 @@ package rel
 type SelectQuery struct {
 	OnlyDistinct bool
 	Fields       []string
+	Exprs        []SelectExpr
 }
 
 // Distinct select query.
@@ -12,6 +13,12 @@ func (sq SelectQuery) Distinct() SelectQuery {
 	return sq
 }
 
+// Expr appends computed expressions to be selected after the fields.
+func (sq SelectQuery) Expr(exprs ...SelectExpr) SelectQuery {
+	sq.Exprs = append(sq.Exprs, exprs...)
+	return sq
+}
+
 // NewSelect query.
 func NewSelect(fields ...string) SelectQuery {
 	return SelectQuery{
@@ -20,3 +20,10 @@ func TestSelect_Distinct(t *testing.T) {
 		Fields:       []string{"id", "name"},
 	}, rel.NewSelect("id", "name").Distinct())
 }
+
+func TestSelect_Expr(t *testing.T) {
+	assert.Equal(t, rel.SelectQuery{
+		Fields: []string{"id", "name"},
+		Exprs:  []rel.SelectExpr{rel.RowNumber().As("position"), rel.Count("*").As("total")},
+	}, rel.NewSelect("id", "name").Expr(rel.RowNumber().As("position")).Expr(rel.Count("*").As("total")))
+}
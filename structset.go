@@ -42,7 +42,9 @@ func (s Structset) Apply(doc *Document, mut *Mutation) {
 
 		if len(pFields) == 1 && pFields[0] == field {
 			// allow setting primary key as long as it's not zero.
-			s.applyValue(doc, mut, field, true)
+			if value, ok := s.doc.Value(field); ok && !isPrimaryZero(value) {
+				s.set(doc, mut, field, value, false)
+			}
 		} else {
 			s.applyValue(doc, mut, field, s.skipZero)
 		}
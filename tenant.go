@@ -0,0 +1,245 @@
+package rel
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	tenantTablesMutex sync.RWMutex
+	tenantTables      = map[string]struct{}{}
+	rtValuer          = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	rtStringer        = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// RegisterTenantTable registers tables that have tenant_id field.
+// Query that only specifies a table, such as Count, Aggregate, Scan, UpdateAll and DeleteAll, is only filtered by the tenant
+// when its table is registered, the same applies to subqueries, table of the query and common table expressions.
+//
+//	rel.RegisterTenantTable("invoices", "invoice_items")
+func RegisterTenantTable(tables ...string) {
+	tenantTablesMutex.Lock()
+	defer tenantTablesMutex.Unlock()
+
+	for _, table := range tables {
+		tenantTables[table] = struct{}{}
+	}
+}
+
+func isTenantTable(table string) bool {
+	tenantTablesMutex.RLock()
+	defer tenantTablesMutex.RUnlock()
+
+	_, ok := tenantTables[table]
+	return ok
+}
+
+// WithTenant returns a copy of context that carries the tenant id.
+// Every query of model that has tenant_id field is filtered by the tenant id, and the tenant id is assigned on insert.
+// Query that only specifies a table and nested queries are filtered when the table is registered using RegisterTenantTable,
+// use Unscoped query or WithoutTenant to skip the tenant filter.
+// Finding record that has tenant_id field using raw SQL query returns ErrTenantRawQuery, because it can't be filtered.
+func WithTenant(ctx context.Context, tenantID interface{}) context.Context {
+	return context.WithValue(ctx, tenantKey, tenantID)
+}
+
+// WithoutTenant returns a copy of context without tenant id, queries using this context are not filtered by any tenant.
+func WithoutTenant(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tenantKey, nil)
+}
+
+// TenantFromContext returns the tenant id carried by the context.
+func TenantFromContext(ctx context.Context) (interface{}, bool) {
+	tenantID := ctx.Value(tenantKey)
+	return tenantID, tenantID != nil
+}
+
+// tenantScope filters query and its nested queries by the tenant id.
+type tenantScope struct {
+	tenant interface{}
+	// table of the record that's known to have tenant_id field.
+	table string
+}
+
+func (ts tenantScope) has(table string) bool {
+	return (ts.table != "" && table == ts.table) || isTenantTable(table)
+}
+
+// apply filters nested queries of tenant table, and filters the query itself when filter is true.
+// Unscoped query is not filtered, including its nested queries.
+func (ts tenantScope) apply(query Query, filter bool) Query {
+	if bool(query.UnscopedQuery) {
+		return query
+	}
+
+	if query.TableQuery != nil {
+		inner := ts.apply(*query.TableQuery, ts.has(query.TableQuery.Table))
+		query.TableQuery = &inner
+	}
+
+	if len(query.WithQuery) > 0 {
+		var (
+			withs = make([]WithQuery, len(query.WithQuery))
+		)
+
+		for i, wq := range query.WithQuery {
+			wq.Query = ts.apply(wq.Query, ts.has(wq.Query.Table))
+			if wq.Recursive != nil {
+				recursive := ts.apply(*wq.Recursive, ts.has(wq.Recursive.Table))
+				wq.Recursive = &recursive
+			}
+
+			withs[i] = wq
+		}
+
+		query.WithQuery = withs
+	}
+
+	if len(query.CombineQuery) > 0 {
+		var (
+			combines = make([]CombineQuery, len(query.CombineQuery))
+		)
+
+		for i, cq := range query.CombineQuery {
+			cq.Query = ts.apply(cq.Query, filter || ts.has(cq.Query.Table))
+			combines[i] = cq
+		}
+
+		query.CombineQuery = combines
+	}
+
+	query.WhereQuery = ts.filter(query.WhereQuery)
+	query.GroupQuery.Filter = ts.filter(query.GroupQuery.Filter)
+
+	if !filter {
+		return query
+	}
+
+	var (
+		field = "tenant_id"
+	)
+
+	if len(query.JoinQuery) > 0 {
+		field = query.Table + "." + field
+	}
+
+	return query.Where(Eq(field, ts.tenant))
+}
+
+// filter applies tenant to subqueries used as filter value, such as In and Exists.
+func (ts tenantScope) filter(fq FilterQuery) FilterQuery {
+	switch v := fq.Value.(type) {
+	case Query:
+		fq.Value = ts.apply(v, ts.has(v.Table))
+	case []interface{}:
+		if len(v) == 1 {
+			if query, ok := v[0].(Query); ok {
+				fq.Value = []interface{}{ts.apply(query, ts.has(query.Table))}
+			}
+		}
+	}
+
+	if len(fq.Inner) > 0 {
+		var (
+			inner = make([]FilterQuery, len(fq.Inner))
+		)
+
+		for i := range fq.Inner {
+			inner[i] = ts.filter(fq.Inner[i])
+		}
+
+		fq.Inner = inner
+	}
+
+	return fq
+}
+
+// withTenant filters query by the tenant id when its table is registered as tenant table,
+// including the queries combined using union, intersect or except, and nested queries of tenant table.
+func withTenant(tenant interface{}, query Query) Query {
+	if tenant == nil {
+		return query
+	}
+
+	return tenantScope{tenant: tenant}.apply(query, isTenantTable(query.Table))
+}
+
+// withDocumentTenant filters query by the tenant id when the document has tenant_id field, and nested queries of tenant table.
+func withDocumentTenant(tenant interface{}, flag DocumentFlag, query Query) Query {
+	if tenant == nil {
+		return query
+	}
+
+	var (
+		ts = tenantScope{tenant: tenant}
+	)
+
+	if flag.Is(HasTenantID) {
+		ts.table = query.Table
+	}
+
+	return ts.apply(query, flag.Is(HasTenantID))
+}
+
+// checkTenantQuery returns error when query of record that has tenant_id field can't be filtered by the tenant.
+func checkTenantQuery(tenant interface{}, flag DocumentFlag, query Query) error {
+	if tenant != nil && flag.Is(HasTenantID) && !bool(query.UnscopedQuery) && query.SQLQuery.Statement != "" {
+		return ErrTenantRawQuery
+	}
+
+	return nil
+}
+
+// isTenantType returns true if the type can be used as tenant id.
+func isTenantType(rt reflect.Type) bool {
+	switch rt.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.String:
+		return true
+	}
+
+	// such as uuid.
+	return rt.Implements(rtValuer) || rt.Implements(rtStringer)
+}
+
+// setTenant assigns tenant id to the record when it's not set, and rejects record that belongs to other tenant.
+func setTenant(tenant interface{}, doc *Document, mutation *Mutation) error {
+	if tenant == nil || !doc.Flag(HasTenantID) {
+		return nil
+	}
+
+	if mut, ok := mutation.Mutates["tenant_id"]; ok && !isTenantMutate(mut, tenant) {
+		return ErrCrossTenant
+	}
+
+	if value, _ := doc.Value("tenant_id"); !isZero(value) {
+		if !sameTenant(value, tenant) {
+			return ErrCrossTenant
+		}
+
+		return nil
+	}
+
+	if !doc.SetValue("tenant_id", tenant) {
+		panic(fmt.Sprint("rel: cannot assign tenant id ", tenant, " as tenant_id into ", doc.Table()))
+	}
+
+	value, _ := doc.Value("tenant_id")
+	mutation.Add(Set("tenant_id", value))
+
+	return nil
+}
+
+// isTenantMutate returns true if the mutate doesn't move record to other tenant.
+func isTenantMutate(mut Mutate, tenant interface{}) bool {
+	return mut.Type == ChangeSetOp && (isZero(mut.Value) || sameTenant(mut.Value, tenant))
+}
+
+// sameTenant compares tenant id using its formatted value, so tenant id in context doesn't need to have the exact type of the field.
+func sameTenant(value interface{}, tenant interface{}) bool {
+	return fmt.Sprint(value) == fmt.Sprint(tenant)
+}
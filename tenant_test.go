@@ -0,0 +1,565 @@
+package rel
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type Invoice struct {
+	ID       int
+	TenantID int
+	Total    int
+}
+
+func init() {
+	RegisterTenantTable("invoices", "receipts")
+}
+
+func TestTenantFromContext(t *testing.T) {
+	tenant, ok := TenantFromContext(context.TODO())
+	assert.Nil(t, tenant)
+	assert.False(t, ok)
+
+	tenant, ok = TenantFromContext(WithTenant(context.TODO(), 1))
+	assert.Equal(t, 1, tenant)
+	assert.True(t, ok)
+}
+
+func TestWithTenant(t *testing.T) {
+	tests := []struct {
+		name   string
+		tenant interface{}
+		query  Query
+		result Query
+	}{
+		{
+			name:   "no tenant",
+			tenant: nil,
+			query:  From("invoices"),
+			result: From("invoices"),
+		},
+		{
+			name:   "filter",
+			tenant: 1,
+			query:  From("invoices").Where(Gt("total", 10)),
+			result: From("invoices").Where(Gt("total", 10), Eq("tenant_id", 1)),
+		},
+		{
+			name:   "join",
+			tenant: 1,
+			query:  From("invoices").Join("users"),
+			result: From("invoices").Join("users").Where(Eq("invoices.tenant_id", 1)),
+		},
+		{
+			name:   "unscoped",
+			tenant: 1,
+			query:  From("invoices").Unscoped(),
+			result: From("invoices").Unscoped(),
+		},
+		{
+			name:   "table without tenant",
+			tenant: 1,
+			query:  From("users"),
+			result: From("users"),
+		},
+		{
+			name:   "unknown table",
+			tenant: 1,
+			query:  From("payments"),
+			result: From("payments"),
+		},
+		{
+			name:   "in subquery",
+			tenant: 1,
+			query:  From("users").Where(In("id", From("invoices").Select("user_id"))),
+			result: From("users").Where(In("id", From("invoices").Select("user_id").Where(Eq("tenant_id", 1)))),
+		},
+		{
+			name:   "exists subquery",
+			tenant: 1,
+			query:  From("users").Where(Eq("active", true).And(Exists(From("invoices")))),
+			result: From("users").Where(Eq("active", true).And(Exists(From("invoices").Where(Eq("tenant_id", 1))))),
+		},
+		{
+			name:   "having subquery",
+			tenant: 1,
+			query:  From("users").Group("role").Having(NotExists(From("receipts"))),
+			result: From("users").Group("role").Having(NotExists(From("receipts").Where(Eq("tenant_id", 1)))),
+		},
+		{
+			name:   "unscoped subquery",
+			tenant: 1,
+			query:  From("users").Where(Exists(From("invoices").Unscoped())),
+			result: From("users").Where(Exists(From("invoices").Unscoped())),
+		},
+		{
+			name:   "from subquery",
+			tenant: 1,
+			query:  FromQuery("recent", From("invoices").Where(Gt("id", 10))),
+			result: FromQuery("recent", From("invoices").Where(Gt("id", 10), Eq("tenant_id", 1))),
+		},
+		{
+			name:   "common table expression",
+			tenant: 1,
+			query:  With("paid", From("invoices")).From("paid"),
+			result: With("paid", From("invoices").Where(Eq("tenant_id", 1))).From("paid"),
+		},
+		{
+			name:   "recursive common table expression",
+			tenant: 1,
+			query:  From("tree").WithRecursive("tree", From("receipts"), From("receipts").Join("tree")),
+			result: From("tree").WithRecursive("tree", From("receipts").Where(Eq("tenant_id", 1)), From("receipts").Join("tree").Where(Eq("receipts.tenant_id", 1))),
+		},
+		{
+			name:   "combine",
+			tenant: 1,
+			query:  From("invoices").Union(From("receipts")),
+			result: From("invoices").Union(From("receipts").Where(Eq("tenant_id", 1))).Where(Eq("tenant_id", 1)),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.result, withTenant(test.tenant, test.query))
+		})
+	}
+}
+
+type InvoiceItem struct {
+	ID       int
+	TenantID float64
+}
+
+func TestDocument_tenantIDType(t *testing.T) {
+	assert.True(t, NewDocument(&Invoice{}).Flag(HasTenantID))
+	assert.False(t, NewDocument(&InvoiceItem{}).Flag(HasTenantID))
+}
+
+func TestSetTenant(t *testing.T) {
+	tests := []struct {
+		name     string
+		invoice  Invoice
+		mutation Mutation
+		result   Invoice
+		mutates  map[string]Mutate
+		err      error
+	}{
+		{
+			name:     "assign",
+			invoice:  Invoice{Total: 10},
+			mutation: Mutation{Mutates: map[string]Mutate{"tenant_id": Set("tenant_id", 0)}},
+			result:   Invoice{TenantID: 1, Total: 10},
+			mutates:  map[string]Mutate{"tenant_id": Set("tenant_id", 1)},
+		},
+		{
+			name:     "same tenant",
+			invoice:  Invoice{TenantID: 1},
+			mutation: Mutation{Mutates: map[string]Mutate{"tenant_id": Set("tenant_id", 1)}},
+			result:   Invoice{TenantID: 1},
+			mutates:  map[string]Mutate{"tenant_id": Set("tenant_id", 1)},
+		},
+		{
+			name:     "other tenant",
+			invoice:  Invoice{TenantID: 2},
+			mutation: Mutation{Mutates: map[string]Mutate{"total": Set("total", 10)}},
+			result:   Invoice{TenantID: 2},
+			mutates:  map[string]Mutate{"total": Set("total", 10)},
+			err:      ErrCrossTenant,
+		},
+		{
+			name:     "move to other tenant",
+			invoice:  Invoice{TenantID: 1},
+			mutation: Mutation{Mutates: map[string]Mutate{"tenant_id": Set("tenant_id", 2)}},
+			result:   Invoice{TenantID: 1},
+			mutates:  map[string]Mutate{"tenant_id": Set("tenant_id", 2)},
+			err:      ErrCrossTenant,
+		},
+		{
+			name:     "increment tenant",
+			invoice:  Invoice{TenantID: 1},
+			mutation: Mutation{Mutates: map[string]Mutate{"tenant_id": Inc("tenant_id")}},
+			result:   Invoice{TenantID: 1},
+			mutates:  map[string]Mutate{"tenant_id": Inc("tenant_id")},
+			err:      ErrCrossTenant,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				invoice  = test.invoice
+				mutation = test.mutation
+			)
+
+			assert.Equal(t, test.err, setTenant(1, NewDocument(&invoice), &mutation))
+			assert.Equal(t, test.result, invoice)
+			assert.Equal(t, test.mutates, mutation.Mutates)
+		})
+	}
+}
+
+func TestSetTenant_invalidType(t *testing.T) {
+	assert.Panics(t, func() {
+		setTenant("tenant", NewDocument(&Invoice{}), &Mutation{})
+	})
+}
+
+func TestRepository_Find_tenant(t *testing.T) {
+	var (
+		invoice Invoice
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ctx     = WithTenant(context.TODO(), 1)
+		cur     = createCursor(1)
+	)
+
+	adapter.On("Query", From("invoices").Where(Eq("id", 10), Eq("tenant_id", 1)).Limit(1)).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Find(ctx, &invoice, Eq("id", 10)))
+	assert.Equal(t, 10, invoice.ID)
+	assert.False(t, cur.Next())
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Find_tenantSQL(t *testing.T) {
+	var (
+		invoice Invoice
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ctx     = WithTenant(context.TODO(), 1)
+		query   = Build("", SQL("SELECT * FROM invoices WHERE id=?;", 10))
+		cur     = createCursor(1)
+	)
+
+	assert.Equal(t, ErrTenantRawQuery, repo.Find(ctx, &invoice, query))
+	assert.Equal(t, ErrTenantRawQuery, repo.FindAll(ctx, &[]Invoice{}, query))
+
+	// explicitly unscoped.
+	adapter.On("Query", Build("invoices", query).Unscoped().Limit(1)).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Find(ctx, &invoice, query.Unscoped()))
+	assert.Equal(t, 10, invoice.ID)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_FindAll_tenantNotApplicable(t *testing.T) {
+	var (
+		users   []User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ctx     = WithTenant(context.TODO(), 1)
+		cur     = createCursor(1)
+	)
+
+	adapter.On("Query", From("users")).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.FindAll(ctx, &users))
+	assert.Len(t, users, 1)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_FindAll_tenantSubquery(t *testing.T) {
+	var (
+		users   []User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ctx     = WithTenant(context.TODO(), 1)
+		cur     = createCursor(1)
+	)
+
+	adapter.On("Query", From("users").Where(In("id", From("invoices").Select("user_id").Where(Eq("tenant_id", 1))))).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.FindAll(ctx, &users, In("id", From("invoices").Select("user_id"))))
+	assert.Len(t, users, 1)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Insert_tenant(t *testing.T) {
+	var (
+		invoice = Invoice{Total: 10}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ctx     = WithTenant(context.TODO(), 1)
+		mutates = map[string]Mutate{
+			"tenant_id": Set("tenant_id", 1),
+			"total":     Set("total", 10),
+		}
+	)
+
+	adapter.On("Insert", From("invoices"), mutates, OnConflict{}).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Insert(ctx, &invoice))
+	assert.Equal(t, Invoice{ID: 1, TenantID: 1, Total: 10}, invoice)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_crossTenant(t *testing.T) {
+	var (
+		invoice = Invoice{TenantID: 2, Total: 10}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ctx     = WithTenant(context.TODO(), 1)
+	)
+
+	assert.Equal(t, ErrCrossTenant, repo.Insert(ctx, &invoice))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_InsertAll_tenant(t *testing.T) {
+	var (
+		invoices = []Invoice{{Total: 10}, {Total: 20}}
+		adapter  = &testAdapter{}
+		repo     = New(adapter)
+		ctx      = WithTenant(context.TODO(), 1)
+		mutates  = []map[string]Mutate{
+			{"tenant_id": Set("tenant_id", 1), "total": Set("total", 10)},
+			{"tenant_id": Set("tenant_id", 1), "total": Set("total", 20)},
+		}
+	)
+
+	adapter.On("InsertAll", From("invoices"), mock.Anything, mutates, OnConflict{}).Return([]interface{}{1, 2}, nil).Once()
+
+	assert.Nil(t, repo.InsertAll(ctx, &invoices))
+	assert.Equal(t, []Invoice{{ID: 1, TenantID: 1, Total: 10}, {ID: 2, TenantID: 1, Total: 20}}, invoices)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_InsertAll_crossTenant(t *testing.T) {
+	var (
+		invoices = []Invoice{{Total: 10}, {TenantID: 2, Total: 20}}
+		adapter  = &testAdapter{}
+		repo     = New(adapter)
+		ctx      = WithTenant(context.TODO(), 1)
+	)
+
+	assert.Equal(t, ErrCrossTenant, repo.InsertAll(ctx, &invoices))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_tenant(t *testing.T) {
+	var (
+		invoice = Invoice{ID: 1, TenantID: 1, Total: 10}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ctx     = WithTenant(context.TODO(), 1)
+		mutates = map[string]Mutate{"total": Set("total", 20)}
+	)
+
+	adapter.On("Update", From("invoices").Where(Eq("id", 1), Eq("tenant_id", 1)), mutates).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Update(ctx, &invoice, Set("total", 20)))
+	assert.Equal(t, 20, invoice.Total)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_crossTenant(t *testing.T) {
+	var (
+		invoice = Invoice{ID: 1, TenantID: 1, Total: 10}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ctx     = WithTenant(context.TODO(), 1)
+	)
+
+	assert.Equal(t, ErrCrossTenant, repo.Update(ctx, &invoice, Set("tenant_id", 2)))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Delete_tenant(t *testing.T) {
+	var (
+		invoice = Invoice{ID: 1, TenantID: 1}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ctx     = WithTenant(context.TODO(), 1)
+	)
+
+	adapter.On("Delete", From("invoices").Where(Eq("id", 1), Eq("tenant_id", 1))).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Delete(ctx, &invoice))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Delete_crossTenant(t *testing.T) {
+	var (
+		invoice = Invoice{ID: 1, TenantID: 2}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ctx     = WithTenant(context.TODO(), 1)
+	)
+
+	assert.Equal(t, ErrCrossTenant, repo.Delete(ctx, &invoice))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Count_tenant(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ctx     = WithTenant(context.TODO(), 1)
+	)
+
+	adapter.On("Aggregate", From("invoices").Where(Gt("total", 10), Eq("tenant_id", 1)), "count", "*").Return(2, nil).Once()
+
+	count, err := repo.Count(ctx, "invoices", Gt("total", 10))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Count_tenantNotApplicable(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ctx     = WithTenant(context.TODO(), 1)
+	)
+
+	adapter.On("Aggregate", From("users"), "count", "*").Return(2, nil).Once()
+
+	count, err := repo.Count(ctx, "users")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Count_tenantUnscoped(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ctx     = WithTenant(context.TODO(), 1)
+	)
+
+	adapter.On("Aggregate", From("invoices").Unscoped(), "count", "*").Return(2, nil).Once()
+
+	count, err := repo.Count(ctx, "invoices", Unscoped(true))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Aggregate_tenant(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ctx     = WithTenant(context.TODO(), 1)
+	)
+
+	adapter.On("Aggregate", From("invoices").Where(Eq("tenant_id", 1)), "sum", "total").Return(30, nil).Once()
+
+	sum, err := repo.Aggregate(ctx, From("invoices"), "sum", "total")
+	assert.Nil(t, err)
+	assert.Equal(t, 30, sum)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_UpdateAll_tenant(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ctx     = WithTenant(context.TODO(), 1)
+		mutates = map[string]Mutate{"total": Set("total", 0)}
+	)
+
+	adapter.On("Update", From("invoices").Where(Eq("tenant_id", 1)), mutates).Return(2, nil).Once()
+
+	assert.Nil(t, repo.UpdateAll(ctx, From("invoices"), Set("total", 0)))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_UpdateAll_crossTenant(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ctx     = WithTenant(context.TODO(), 1)
+	)
+
+	assert.Equal(t, ErrCrossTenant, repo.UpdateAll(ctx, From("invoices"), Set("tenant_id", 2)))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_DeleteAll_tenant(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ctx     = WithTenant(context.TODO(), 1)
+	)
+
+	adapter.On("Delete", From("invoices").Where(Eq("tenant_id", 1))).Return(2, nil).Once()
+
+	assert.Nil(t, repo.DeleteAll(ctx, From("invoices")))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_DeleteAll_withoutTenant(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ctx     = WithoutTenant(WithTenant(context.TODO(), 1))
+	)
+
+	adapter.On("Delete", From("invoices")).Return(2, nil).Once()
+
+	assert.Nil(t, repo.DeleteAll(ctx, From("invoices")))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Scan_tenantSQL(t *testing.T) {
+	var (
+		total   int
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		ctx     = WithTenant(context.TODO(), 1)
+		query   = Build("", SQL("SELECT SUM(total) FROM invoices;"))
+		cur     = createCursor(1)
+	)
+
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Scan(ctx, &total, query))
+	assert.Equal(t, 10, total)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestIterator_tenant(t *testing.T) {
+	var (
+		invoice Invoice
+		adapter = &testAdapter{}
+		cur     = createCursor(1)
+		it      = newIterator(WithTenant(context.TODO(), 1), adapter, Query{}, nil)
+	)
+
+	adapter.On("Query", From("invoices").Where(Eq("tenant_id", 1)).SortAsc("id").Limit(1000)).Return(cur, nil).Once()
+
+	assert.Nil(t, it.Next(&invoice))
+	assert.Equal(t, io.EOF, it.Next(&invoice))
+	assert.Nil(t, it.Close())
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
@@ -0,0 +1,76 @@
+package rel
+
+import (
+	"time"
+)
+
+// TransactionOption interface.
+// Available options are: Retry.
+type TransactionOption interface {
+	applyTransaction(options *transactionOptions)
+}
+
+type transactionOptions struct {
+	retry Retry
+}
+
+func applyTransactionOptions(options []TransactionOption) transactionOptions {
+	var (
+		opts transactionOptions
+	)
+
+	for i := range options {
+		options[i].applyTransaction(&opts)
+	}
+
+	return opts
+}
+
+// Retry transaction when it's aborted by serialization failure or deadlock.
+// The whole function is executed again in a new transaction, so it must be safe to be called multiple times.
+// Retry only applies to the outermost transaction, nested transaction returns the error to its parent.
+type Retry struct {
+	// MaxAttempts is the maximum number of attempts including the first one.
+	MaxAttempts int
+	// Backoff returns the delay before the given attempt, the first retry is the second attempt.
+	// Transaction is retried immediately when it's nil.
+	Backoff func(attempt int) time.Duration
+}
+
+func (r Retry) applyTransaction(options *transactionOptions) {
+	options.retry = r
+}
+
+func (r Retry) delay(attempt int) time.Duration {
+	if r.Backoff == nil {
+		return 0
+	}
+
+	return r.Backoff(attempt)
+}
+
+// ConstantBackoff waits for the same duration before every attempt.
+func ConstantBackoff(duration time.Duration) func(attempt int) time.Duration {
+	return func(int) time.Duration {
+		return duration
+	}
+}
+
+// ExponentialBackoff doubles the delay for every attempt starting from base, and it's limited to max.
+func ExponentialBackoff(base time.Duration, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		var (
+			delay = base
+		)
+
+		for i := 2; i < attempt && delay < max; i++ {
+			delay *= 2
+		}
+
+		if delay > max {
+			return max
+		}
+
+		return delay
+	}
+}
@@ -0,0 +1,31 @@
+package rel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry(t *testing.T) {
+	var (
+		retry = Retry{MaxAttempts: 3, Backoff: ConstantBackoff(time.Second)}
+		opts  = applyTransactionOptions([]TransactionOption{retry})
+	)
+
+	assert.Equal(t, 3, opts.retry.MaxAttempts)
+	assert.Equal(t, time.Second, opts.retry.delay(2))
+	assert.Equal(t, time.Duration(0), Retry{MaxAttempts: 3}.delay(2))
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	var (
+		backoff = ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+	)
+
+	assert.Equal(t, 10*time.Millisecond, backoff(2))
+	assert.Equal(t, 20*time.Millisecond, backoff(3))
+	assert.Equal(t, 40*time.Millisecond, backoff(4))
+	assert.Equal(t, 50*time.Millisecond, backoff(5))
+	assert.Equal(t, 50*time.Millisecond, backoff(100))
+}
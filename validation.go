@@ -0,0 +1,262 @@
+package rel
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validator validates a record and returns errors of the invalid fields.
+type Validator func(doc *Document) []FieldError
+
+// ValidateRequired validates that fields are not blank.
+// Nil, empty string, string with only whitespaces, empty slice and empty map are considered blank.
+func ValidateRequired(fields ...string) Validator {
+	return func(doc *Document) []FieldError {
+		var (
+			errs []FieldError
+		)
+
+		for _, field := range fields {
+			if value, _ := doc.Value(field); isBlank(value) {
+				errs = append(errs, FieldError{Field: field, Message: "can't be blank"})
+			}
+		}
+
+		return errs
+	}
+}
+
+// ValidateLength validates length of string, slice or map field.
+// Zero max means the length has no upper limit.
+func ValidateLength(field string, min int, max int) Validator {
+	return ValidateFunc(field, func(value interface{}) string {
+		var (
+			length int
+			rv     = reflect.ValueOf(value)
+		)
+
+		switch rv.Kind() {
+		case reflect.String:
+			length = utf8.RuneCountInString(rv.String())
+		case reflect.Slice, reflect.Map, reflect.Array:
+			length = rv.Len()
+		default:
+			return ""
+		}
+
+		if length < min {
+			return fmt.Sprint("length must be at least ", min)
+		}
+
+		if max > 0 && length > max {
+			return fmt.Sprint("length must be at most ", max)
+		}
+
+		return ""
+	})
+}
+
+// ValidateFormat validates string field using the given regular expression.
+func ValidateFormat(field string, pattern *regexp.Regexp) Validator {
+	return ValidateFunc(field, func(value interface{}) string {
+		if rv := reflect.ValueOf(value); rv.Kind() != reflect.String || !pattern.MatchString(rv.String()) {
+			return "has invalid format"
+		}
+
+		return ""
+	})
+}
+
+// ValidateInclusion validates that field value is one of the given values.
+// Values must have the same type as the field.
+func ValidateInclusion(field string, values ...interface{}) Validator {
+	return ValidateFunc(field, func(value interface{}) string {
+		for i := range values {
+			if reflect.DeepEqual(value, values[i]) {
+				return ""
+			}
+		}
+
+		return "is invalid"
+	})
+}
+
+// ValidateRange validates that numeric field value is between min and max (inclusive).
+func ValidateRange(field string, min float64, max float64) Validator {
+	return ValidateFunc(field, func(value interface{}) string {
+		var (
+			number float64
+			rv     = reflect.ValueOf(value)
+		)
+
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			number = float64(rv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			number = float64(rv.Uint())
+		case reflect.Float32, reflect.Float64:
+			number = rv.Float()
+		default:
+			return "is not a number"
+		}
+
+		if number < min || number > max {
+			return fmt.Sprint("must be between ", min, " and ", max)
+		}
+
+		return ""
+	})
+}
+
+// ValidateAssoc validates records of the association using the given validators.
+// Field of the errors is prefixed by the association, such as address.city for has one or belongs to,
+// and transactions[0].item for has many.
+func ValidateAssoc(field string, validators ...Validator) Validator {
+	return func(doc *Document) []FieldError {
+		var (
+			assoc = doc.Association(field)
+		)
+
+		if assoc.IsZero() {
+			return nil
+		}
+
+		if assoc.Type() != HasMany {
+			assocDoc, _ := assoc.Document()
+			return runValidators(assocDoc, validators, field+".")
+		}
+
+		var (
+			errs   []FieldError
+			col, _ = assoc.Collection()
+		)
+
+		for i := 0; i < col.Len(); i++ {
+			errs = append(errs, runValidators(col.Get(i), validators, fmt.Sprint(field, "[", i, "]."))...)
+		}
+
+		return errs
+	}
+}
+
+// ValidateFunc validates field using custom function.
+// The function returns error message when the value is invalid, or empty string otherwise.
+// Blank value is not validated, use ValidateRequired to reject it.
+func ValidateFunc(field string, fn func(value interface{}) string) Validator {
+	return func(doc *Document) []FieldError {
+		value, _ := doc.Value(field)
+		if isBlank(value) {
+			return nil
+		}
+
+		if msg := fn(value); msg != "" {
+			return []FieldError{{Field: field, Message: msg}}
+		}
+
+		return nil
+	}
+}
+
+// validate runs validators and returns ValidationError if the record is invalid.
+func validate(doc *Document, validators []Validator) error {
+	if errs := runValidators(doc, validators, ""); len(errs) > 0 {
+		return ValidationError{Errors: errs}
+	}
+
+	return nil
+}
+
+// runValidators returns errors of all validators with field prefixed by the given prefix.
+func runValidators(doc *Document, validators []Validator, prefix string) []FieldError {
+	var (
+		errs []FieldError
+	)
+
+	for _, validator := range validators {
+		for _, fe := range validator(doc) {
+			fe.Field = prefix + fe.Field
+			errs = append(errs, fe)
+		}
+	}
+
+	return errs
+}
+
+func isBlank(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	if zeroer, ok := value.(isZeroer); ok {
+		return zeroer.IsZero()
+	}
+
+	var (
+		rv = reflect.ValueOf(value)
+	)
+
+	switch rv.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(rv.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	}
+
+	return false
+}
+
+// constraint maps constraint error returned by database to a field error.
+type constraint struct {
+	field   string
+	key     string
+	typ     ConstraintType
+	message string
+}
+
+func (c constraint) match(err ConstraintError) bool {
+	if c.typ != err.Type {
+		return false
+	}
+
+	if c.key != "" {
+		return c.key == err.Key
+	}
+
+	return containsToken(err.Key, c.field)
+}
+
+// containsToken returns true when s contains token delimited by non alphanumeric characters or the edge of s.
+func containsToken(s string, token string) bool {
+	if token == "" {
+		return false
+	}
+
+	for offset := 0; offset < len(s); {
+		i := strings.Index(s[offset:], token)
+		if i < 0 {
+			return false
+		}
+
+		var (
+			start = offset + i
+			end   = start + len(token)
+		)
+
+		if (start == 0 || !isAlphanumeric(s[start-1])) && (end == len(s) || !isAlphanumeric(s[end])) {
+			return true
+		}
+
+		offset = start + 1
+	}
+
+	return false
+}
+
+func isAlphanumeric(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,255 @@
+package rel
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Signup struct {
+	ID       int
+	Name     string
+	Email    *string
+	Age      int
+	Score    float64
+	Role     string
+	Tags     []string
+	BirthAt  time.Time
+	Password string
+}
+
+func TestValidator(t *testing.T) {
+	var (
+		email = "rel@example.com"
+		typo  = "rel.example.com"
+		blank = "  "
+	)
+
+	tests := []struct {
+		name      string
+		record    Signup
+		validator Validator
+		result    []FieldError
+	}{
+		{
+			name:      "required",
+			record:    Signup{Name: "rel", Email: &email, Tags: []string{"go"}, BirthAt: time.Now()},
+			validator: ValidateRequired("name", "email", "tags", "birth_at"),
+		},
+		{
+			name:      "required blank",
+			record:    Signup{Name: " ", Email: &blank, Tags: []string{}},
+			validator: ValidateRequired("name", "email", "tags", "birth_at", "age"),
+			result: []FieldError{
+				{Field: "name", Message: "can't be blank"},
+				{Field: "email", Message: "can't be blank"},
+				{Field: "tags", Message: "can't be blank"},
+				{Field: "birth_at", Message: "can't be blank"},
+			},
+		},
+		{
+			name:      "required nil",
+			record:    Signup{},
+			validator: ValidateRequired("email", "not_exist"),
+			result: []FieldError{
+				{Field: "email", Message: "can't be blank"},
+				{Field: "not_exist", Message: "can't be blank"},
+			},
+		},
+		{
+			name:      "length",
+			record:    Signup{Name: "rél", Tags: []string{"go"}},
+			validator: ValidateLength("name", 3, 3),
+		},
+		{
+			name:      "length too short",
+			record:    Signup{Name: "re"},
+			validator: ValidateLength("name", 3, 0),
+			result:    []FieldError{{Field: "name", Message: "length must be at least 3"}},
+		},
+		{
+			name:      "length too long",
+			record:    Signup{Tags: []string{"go", "sql", "orm"}},
+			validator: ValidateLength("tags", 0, 2),
+			result:    []FieldError{{Field: "tags", Message: "length must be at most 2"}},
+		},
+		{
+			name:      "length blank",
+			record:    Signup{},
+			validator: ValidateLength("name", 3, 0),
+		},
+		{
+			name:      "length not applicable",
+			record:    Signup{Age: 10},
+			validator: ValidateLength("age", 3, 0),
+		},
+		{
+			name:      "format",
+			record:    Signup{Email: &email},
+			validator: ValidateFormat("email", regexp.MustCompile(`^\S+@\S+$`)),
+		},
+		{
+			name:      "format invalid",
+			record:    Signup{Email: &typo},
+			validator: ValidateFormat("email", regexp.MustCompile(`^\S+@\S+$`)),
+			result:    []FieldError{{Field: "email", Message: "has invalid format"}},
+		},
+		{
+			name:      "format not string",
+			record:    Signup{Age: 10},
+			validator: ValidateFormat("age", regexp.MustCompile(`\d+`)),
+			result:    []FieldError{{Field: "age", Message: "has invalid format"}},
+		},
+		{
+			name:      "inclusion",
+			record:    Signup{Role: "admin"},
+			validator: ValidateInclusion("role", "admin", "member"),
+		},
+		{
+			name:      "inclusion invalid",
+			record:    Signup{Role: "root"},
+			validator: ValidateInclusion("role", "admin", "member"),
+			result:    []FieldError{{Field: "role", Message: "is invalid"}},
+		},
+		{
+			name:      "range",
+			record:    Signup{Age: 0, Score: 9.5},
+			validator: ValidateRange("score", 0, 10),
+		},
+		{
+			name:      "range zero",
+			record:    Signup{Age: 0},
+			validator: ValidateRange("age", 17, 100),
+			result:    []FieldError{{Field: "age", Message: "must be between 17 and 100"}},
+		},
+		{
+			name:      "range out of bound",
+			record:    Signup{Score: 10.5},
+			validator: ValidateRange("score", 0, 10),
+			result:    []FieldError{{Field: "score", Message: "must be between 0 and 10"}},
+		},
+		{
+			name:      "range not a number",
+			record:    Signup{Name: "rel"},
+			validator: ValidateRange("name", 0, 10),
+			result:    []FieldError{{Field: "name", Message: "is not a number"}},
+		},
+		{
+			name:   "func",
+			record: Signup{Password: "secret"},
+			validator: ValidateFunc("password", func(value interface{}) string {
+				if value == "secret" {
+					return "is too common"
+				}
+
+				return ""
+			}),
+			result: []FieldError{{Field: "password", Message: "is too common"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.result, test.validator(NewDocument(&test.record)))
+		})
+	}
+}
+
+func TestValidateAssoc(t *testing.T) {
+	var (
+		validator = ValidateAssoc("address", ValidateRequired("street"))
+		validMany = ValidateAssoc("transactions", ValidateRequired("item"))
+	)
+
+	t.Run("has one", func(t *testing.T) {
+		user := User{Address: Address{Notes: "notes"}}
+		assert.Equal(t, []FieldError{{Field: "address.street", Message: "can't be blank"}}, validator(NewDocument(&user)))
+	})
+
+	t.Run("belongs to", func(t *testing.T) {
+		var (
+			address   = Address{User: &User{Age: 10}}
+			validator = ValidateAssoc("user", ValidateRequired("name"))
+		)
+
+		assert.Equal(t, []FieldError{{Field: "user.name", Message: "can't be blank"}}, validator(NewDocument(&address)))
+	})
+
+	t.Run("has many", func(t *testing.T) {
+		user := User{Transactions: []Transaction{{Item: "soap"}, {}}}
+		assert.Equal(t, []FieldError{{Field: "transactions[1].item", Message: "can't be blank"}}, validMany(NewDocument(&user)))
+	})
+
+	t.Run("not loaded", func(t *testing.T) {
+		var (
+			user    User
+			address Address
+		)
+
+		assert.Nil(t, validator(NewDocument(&user)))
+		assert.Nil(t, validMany(NewDocument(&user)))
+		assert.Nil(t, ValidateAssoc("user", ValidateRequired("name"))(NewDocument(&address)))
+		assert.Nil(t, address.User)
+	})
+}
+
+func TestConstraint_match(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint constraint
+		err        ConstraintError
+		match      bool
+	}{
+		{
+			name:       "contains field",
+			constraint: constraint{field: "email", typ: UniqueConstraint},
+			err:        ConstraintError{Key: "users_email_key", Type: UniqueConstraint},
+			match:      true,
+		},
+		{
+			name:       "different field",
+			constraint: constraint{field: "email", typ: UniqueConstraint},
+			err:        ConstraintError{Key: "users_name_key", Type: UniqueConstraint},
+		},
+		{
+			name:       "field as key",
+			constraint: constraint{field: "email", typ: UniqueConstraint},
+			err:        ConstraintError{Key: "email", Type: UniqueConstraint},
+			match:      true,
+		},
+		{
+			name:       "field is part of other field",
+			constraint: constraint{field: "name", typ: UniqueConstraint},
+			err:        ConstraintError{Key: "users_username_key", Type: UniqueConstraint},
+		},
+		{
+			name:       "field is part of other field after the field",
+			constraint: constraint{field: "name", typ: UniqueConstraint},
+			err:        ConstraintError{Key: "users_name2_key", Type: UniqueConstraint},
+		},
+		{
+			name:       "different type",
+			constraint: constraint{field: "email", typ: UniqueConstraint},
+			err:        ConstraintError{Key: "users_email_key", Type: CheckConstraint},
+		},
+		{
+			name:       "key",
+			constraint: constraint{field: "email", key: "unique_email", typ: UniqueConstraint},
+			err:        ConstraintError{Key: "unique_email", Type: UniqueConstraint},
+			match:      true,
+		},
+		{
+			name:       "different key",
+			constraint: constraint{field: "email", key: "unique_email", typ: UniqueConstraint},
+			err:        ConstraintError{Key: "users_email_key", Type: UniqueConstraint},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.match, test.constraint.match(test.err))
+		})
+	}
+}
@@ -72,4 +72,16 @@ var (
 
 	// Fragment add custom filter.
 	Fragment = rel.FilterFragment
+
+	// Exists check whether the subquery returns any rows.
+	Exists = rel.Exists
+
+	// NotExists check whether the subquery returns no rows.
+	NotExists = rel.NotExists
+
+	// TupleGt compares that tuple of fields is greater than tuple of values.
+	TupleGt = rel.TupleGt
+
+	// TupleLt compares that tuple of fields is less than tuple of values.
+	TupleLt = rel.TupleLt
 )
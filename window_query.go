@@ -0,0 +1,44 @@
+package rel
+
+// WindowQuery defines window used by window function.
+type WindowQuery struct {
+	Partition []string
+	SortQuery []SortQuery
+	Frame     string
+}
+
+// PartitionBy divides rows into partitions by the given fields.
+func (wq WindowQuery) PartitionBy(fields ...string) WindowQuery {
+	wq.Partition = append(wq.Partition, fields...)
+	return wq
+}
+
+// Sort rows within partition.
+func (wq WindowQuery) Sort(sorts ...SortQuery) WindowQuery {
+	wq.SortQuery = append(wq.SortQuery, sorts...)
+	return wq
+}
+
+// SortAsc sorts rows within partition by field in ascending order.
+func (wq WindowQuery) SortAsc(field string) WindowQuery {
+	return wq.Sort(NewSortAsc(field))
+}
+
+// SortDesc sorts rows within partition by field in descending order.
+func (wq WindowQuery) SortDesc(field string) WindowQuery {
+	return wq.Sort(NewSortDesc(field))
+}
+
+// Framef sets frame clause of the window using raw query, eg: ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW.
+func (wq WindowQuery) Framef(expr string) WindowQuery {
+	wq.Frame = expr
+	return wq
+}
+
+// Window defines a window partitioned by the given fields.
+// Window without partition spans all rows of the query.
+func Window(partition ...string) WindowQuery {
+	return WindowQuery{
+		Partition: partition,
+	}
+}
@@ -0,0 +1,30 @@
+package rel_test
+
+import (
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/sort"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindow(t *testing.T) {
+	assert.Equal(t, rel.WindowQuery{}, rel.Window())
+	assert.Equal(t, rel.WindowQuery{
+		Partition: []string{"game_id", "level"},
+	}, rel.Window("game_id").PartitionBy("level"))
+}
+
+func TestWindow_Sort(t *testing.T) {
+	assert.Equal(t, rel.WindowQuery{
+		Partition: []string{"game_id"},
+		SortQuery: []rel.SortQuery{sort.Desc("score"), sort.Asc("created_at"), sort.Asc("id")},
+	}, rel.Window("game_id").SortDesc("score").SortAsc("created_at").Sort(sort.Asc("id")))
+}
+
+func TestWindow_Framef(t *testing.T) {
+	assert.Equal(t, rel.WindowQuery{
+		SortQuery: []rel.SortQuery{sort.Asc("created_at")},
+		Frame:     "ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW",
+	}, rel.Window().SortAsc("created_at").Framef("ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW"))
+}
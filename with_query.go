@@ -0,0 +1,31 @@
+package rel
+
+// WithQuery defines common table expression in query.
+type WithQuery struct {
+	Name      string
+	Query     Query
+	Recursive *Query
+}
+
+// Build query.
+func (wq WithQuery) Build(query *Query) {
+	query.WithQuery = append(query.WithQuery, wq)
+}
+
+// NewWith defines a common table expression with given name that can be referenced as a table.
+func NewWith(name string, query Query) WithQuery {
+	return WithQuery{
+		Name:  name,
+		Query: query,
+	}
+}
+
+// NewWithRecursive defines a recursive common table expression.
+// The recursive query is combined with the initial query using union all, and may refer to the expression by its name.
+func NewWithRecursive(name string, query Query, recursive Query) WithQuery {
+	return WithQuery{
+		Name:      name,
+		Query:     query,
+		Recursive: &recursive,
+	}
+}
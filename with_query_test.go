@@ -0,0 +1,33 @@
+package rel_test
+
+import (
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWith(t *testing.T) {
+	var (
+		query = rel.From("users").Where(where.Eq("active", true))
+	)
+
+	assert.Equal(t, rel.WithQuery{
+		Name:  "active_users",
+		Query: query,
+	}, rel.NewWith("active_users", query))
+}
+
+func TestWithRecursive(t *testing.T) {
+	var (
+		query     = rel.From("categories").Where(where.Nil("parent_id"))
+		recursive = rel.Select("categories.*").From("categories").JoinOn("tree", "tree.id", "categories.parent_id")
+	)
+
+	assert.Equal(t, rel.WithQuery{
+		Name:      "tree",
+		Query:     query,
+		Recursive: &recursive,
+	}, rel.NewWithRecursive("tree", query, recursive))
+}